@@ -0,0 +1,134 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/executor"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// Backend is the subset of the Ethereum client used to broadcast a signed bundle.
+//
+// The go-ethereum *ethclient.Client implements this interface.
+type Backend interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// broadcaster holds the settings of Broadcast
+type broadcaster struct {
+	receiptTimeout time.Duration
+	pollInterval   time.Duration
+}
+
+// BroadcastOption allows setting custom parameters on Broadcast
+type BroadcastOption func(*broadcaster) error
+
+// WithReceiptTimeout sets how long to wait for each transaction to be mined, defaults to 2 minutes
+func WithReceiptTimeout(d time.Duration) BroadcastOption {
+	return func(b *broadcaster) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid receipt timeout: %v", d)
+		}
+		b.receiptTimeout = d
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval between receipt polls, defaults to 5 seconds
+func WithPollInterval(d time.Duration) BroadcastOption {
+	return func(b *broadcaster) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid poll interval: %v", d)
+		}
+		b.pollInterval = d
+		return nil
+	}
+}
+
+// Broadcast verifies a signed bundle and sends its transactions in step order, waiting for
+// each one to be mined before sending the next. The returned result holds the receipts and
+// the signatures of sign request steps, ready to be passed back to the API.
+func Broadcast(ctx context.Context, backend Backend, b *Bundle, opts ...BroadcastOption) (*executor.Result, error) {
+	settings := broadcaster{
+		receiptTimeout: 2 * time.Minute,
+		pollInterval:   5 * time.Second,
+	}
+	for _, o := range opts {
+		if err := o(&settings); err != nil {
+			return nil, err
+		}
+	}
+	result := &executor.Result{}
+	if !b.IsSigned() {
+		return result, ErrNotSigned
+	}
+	if err := b.Verify(); err != nil {
+		return result, err
+	}
+
+	for _, step := range b.Steps {
+		stepResult := executor.StepResult{Number: step.Number, Type: step.Type}
+		if step.Type != service.ChainActivityStepTypePreparedTx {
+			stepResult.Signature = step.Signature
+			result.Steps = append(result.Steps, stepResult)
+			continue
+		}
+
+		preparedTx, err := step.Request.Data.AsPreparedTx()
+		if err != nil {
+			return result, &executor.StepError{Number: step.Number, Type: step.Type, Err: err}
+		}
+		stepResult.Label = preparedTx.Label
+		signedTx, err := step.SignedTransaction()
+		if err != nil {
+			return result, &executor.StepError{Number: step.Number, Type: step.Type, Err: err}
+		}
+		if err := backend.SendTransaction(ctx, signedTx); err != nil && !strings.Contains(err.Error(), "already known") {
+			return result, &executor.StepError{Number: step.Number, Type: step.Type, Err: fmt.Errorf("failed to send transaction: %w", err)}
+		}
+		receipt, err := settings.waitMined(ctx, backend, signedTx.Hash())
+		if err != nil {
+			return result, &executor.StepError{Number: step.Number, Type: step.Type, Err: err}
+		}
+		hash := receipt.TxHash
+		stepResult.TxHash = &hash
+		stepResult.Receipt = receipt
+		result.Steps = append(result.Steps, stepResult)
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return result, &executor.StepError{Number: step.Number, Type: step.Type, Err: fmt.Errorf("%w: %s", executor.ErrTxFailed, hash.Hex())}
+		}
+	}
+	return result, nil
+}
+
+// waitMined polls for the receipt of a transaction until the receipt timeout expires
+func (b *broadcaster) waitMined(ctx context.Context, backend Backend, txHash common.Hash) (*types.Receipt, error) {
+	deadline := time.Now().Add(b.receiptTimeout)
+	for {
+		receipt, err := backend.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		if time.Now().After(deadline) {
+			if !errors.Is(err, ethereum.NotFound) {
+				return nil, fmt.Errorf("%w: %s: %v", executor.ErrReceiptTimeout, txHash.Hex(), err)
+			}
+			return nil, fmt.Errorf("%w: %s", executor.ErrReceiptTimeout, txHash.Hex())
+		}
+		timer := time.NewTimer(b.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
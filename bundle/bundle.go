@@ -0,0 +1,293 @@
+// Package bundle moves chain activities to an offline machine for signing and back.
+//
+// The online side exports a service.ChainActivity with Export, the offline side signs it with
+// Sign, and the online side broadcasts the signed bundle with Broadcast. Checksums over the
+// unsigned and the signed content catch accidental corruption in transit. They are not
+// authenticated, anyone able to edit a bundle can recompute them, so every transaction is
+// checked against the request it was built from and the steps should be reviewed before signing.
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/executor"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+const (
+	// Format identifies signing bundles
+	Format = "zarban-signing-bundle"
+
+	// Version is the bundle layout version written by this package
+	Version = 1
+
+	// checksumPrefix names the checksum algorithm
+	checksumPrefix = "sha256:"
+)
+
+var (
+	// ErrChecksumMismatch is returned when a bundle was corrupted after its checksum was computed
+	ErrChecksumMismatch = errors.New("bundle checksum mismatch")
+
+	// ErrTxMismatch is returned when the transaction of a step does not match its request
+	ErrTxMismatch = errors.New("transaction does not match its request")
+
+	// ErrNotSigned is returned when broadcasting a bundle that has not been signed
+	ErrNotSigned = errors.New("bundle is not signed")
+)
+
+// Bundle is a self-describing, checksummed description of the steps of a chain activity
+type Bundle struct {
+	Format         string         `json:"format"`
+	Version        int            `json:"version"`
+	ChainID        *big.Int       `json:"chainId"`
+	From           common.Address `json:"from"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	Steps          []Step         `json:"steps"`
+	Checksum       string         `json:"checksum"`
+	SignedAt       *time.Time     `json:"signedAt,omitempty"`
+	SignedChecksum string         `json:"signedChecksum,omitempty"`
+}
+
+// Step is a single step of a bundle. PreparedTx steps carry the unsigned transaction with its
+// assigned nonce and fees, sign request steps only their request.
+type Step struct {
+	Number    int                           `json:"number"`
+	Type      service.ChainActivityStepType `json:"type"`
+	Request   service.ChainActivityStep     `json:"request"`
+	Nonce     *uint64                       `json:"nonce,omitempty"`
+	Tx        hexutil.Bytes                 `json:"tx,omitempty"`
+	SignedTx  hexutil.Bytes                 `json:"signedTx,omitempty"`
+	Signature hexutil.Bytes                 `json:"signature,omitempty"`
+}
+
+// Transaction decodes the unsigned transaction of a PreparedTx step
+func (s *Step) Transaction() (*types.Transaction, error) {
+	return decodeTx(s.Number, s.Tx)
+}
+
+// SignedTransaction decodes the signed transaction of a PreparedTx step
+func (s *Step) SignedTransaction() (*types.Transaction, error) {
+	return decodeTx(s.Number, s.SignedTx)
+}
+
+// IsSigned returns true once the bundle went through Sign
+func (b *Bundle) IsSigned() bool {
+	return b.SignedChecksum != ""
+}
+
+// Marshal returns the bundle as indented JSON
+func (b *Bundle) Marshal() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// Parse decodes a bundle and verifies it
+func Parse(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Verify checks the format and the checksums of the bundle, and that every transaction matches
+// the recipient, value, calldata and gas estimate of its request. For signed bundles it also
+// checks that every signed transaction is the exported one and that every signature is from From.
+func (b *Bundle) Verify() error {
+	if b.Format != Format {
+		return fmt.Errorf("unknown bundle format: %q", b.Format)
+	}
+	if b.Version != Version {
+		return fmt.Errorf("unsupported bundle version: %d", b.Version)
+	}
+	if b.ChainID == nil || b.ChainID.Sign() <= 0 {
+		return fmt.Errorf("invalid chain id: %v", b.ChainID)
+	}
+	checksum, err := b.checksum(false)
+	if err != nil {
+		return err
+	}
+	if checksum != b.Checksum {
+		return fmt.Errorf("%w: expected %s, computed %s", ErrChecksumMismatch, b.Checksum, checksum)
+	}
+	for i := range b.Steps {
+		if err := b.checkTx(&b.Steps[i]); err != nil {
+			return err
+		}
+	}
+	if !b.IsSigned() {
+		for _, step := range b.Steps {
+			if step.SignedTx != nil || step.Signature != nil {
+				return fmt.Errorf("%w: step %d is signed but the bundle has no signed checksum", ErrChecksumMismatch, step.Number)
+			}
+		}
+		return nil
+	}
+
+	signedChecksum, err := b.checksum(true)
+	if err != nil {
+		return err
+	}
+	if signedChecksum != b.SignedChecksum {
+		return fmt.Errorf("%w: expected signed %s, computed %s", ErrChecksumMismatch, b.SignedChecksum, signedChecksum)
+	}
+	for i := range b.Steps {
+		if err := b.verifyStep(&b.Steps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTx rebuilds the transaction of a PreparedTx step from its request with the nonce and fees
+// of the exported transaction, and compares the two. The gas limit may exceed the estimate by the
+// buffer of the exporting builder, but not fall below it.
+func (b *Bundle) checkTx(step *Step) error {
+	if step.Type != service.ChainActivityStepTypePreparedTx {
+		return nil
+	}
+	tx, err := step.Transaction()
+	if err != nil {
+		return err
+	}
+	preparedTx, err := step.Request.Data.AsPreparedTx()
+	if err != nil {
+		return fmt.Errorf("step %d: failed to decode prepared tx: %w", step.Number, err)
+	}
+	if step.Nonce == nil {
+		return fmt.Errorf("step %d: %w: no nonce", step.Number, ErrTxMismatch)
+	}
+	fees := executor.Fees{GasPrice: tx.GasPrice()}
+	if tx.Type() == types.DynamicFeeTxType {
+		fees = executor.Fees{GasFeeCap: tx.GasFeeCap(), GasTipCap: tx.GasTipCap()}
+	}
+	builder, err := executor.NewTxBuilder(nil)
+	if err != nil {
+		return err
+	}
+	expected, err := builder.BuildWithFees(preparedTx, b.ChainID, *step.Nonce, fees)
+	if err != nil {
+		return fmt.Errorf("step %d: failed to build transaction from request: %w", step.Number, err)
+	}
+
+	var field string
+	switch {
+	case tx.Type() != expected.Type():
+		field = "type"
+	case tx.Type() != types.LegacyTxType && tx.ChainId().Cmp(b.ChainID) != 0:
+		field = "chain id"
+	case tx.Nonce() != expected.Nonce():
+		field = "nonce"
+	case tx.To() == nil || *tx.To() != *expected.To():
+		field = "recipient"
+	case tx.Value().Cmp(expected.Value()) != 0:
+		field = "value"
+	case !bytes.Equal(tx.Data(), expected.Data()):
+		field = "data"
+	case tx.Gas() < expected.Gas():
+		field = "gas"
+	case len(tx.AccessList()) != 0:
+		field = "access list"
+	}
+	if field != "" {
+		return fmt.Errorf("step %d: %w: %s differs", step.Number, ErrTxMismatch, field)
+	}
+	return nil
+}
+
+// verifyStep checks the signed output of a step against its request
+func (b *Bundle) verifyStep(step *Step) error {
+	switch step.Type {
+	case service.ChainActivityStepTypePreparedTx:
+		tx, err := step.Transaction()
+		if err != nil {
+			return err
+		}
+		signedTx, err := step.SignedTransaction()
+		if err != nil {
+			return err
+		}
+		txSigner := types.LatestSignerForChainID(b.ChainID)
+		if txSigner.Hash(tx) != txSigner.Hash(signedTx) {
+			return fmt.Errorf("step %d: signed transaction differs from the exported one", step.Number)
+		}
+		sender, err := types.Sender(txSigner, signedTx)
+		if err != nil {
+			return fmt.Errorf("step %d: failed to recover sender: %w", step.Number, err)
+		}
+		if sender != b.From {
+			return fmt.Errorf("step %d: %w: expected %s, recovered %s", step.Number, signer.ErrSignatureMismatch, b.From.Hex(), sender.Hex())
+		}
+
+	case service.ChainActivityStepTypeEIP712SignRequest:
+		request, err := step.Request.Data.AsEIP712SignRequest()
+		if err != nil {
+			return fmt.Errorf("step %d: failed to decode eip712 sign request: %w", step.Number, err)
+		}
+		if err := signer.VerifyTypedData(b.From, request.TypedData, step.Signature); err != nil {
+			return fmt.Errorf("step %d: %w", step.Number, err)
+		}
+
+	case service.ChainActivityStepTypePersonalSignRequest:
+		request, err := step.Request.Data.AsPersonalSignRequest()
+		if err != nil {
+			return fmt.Errorf("step %d: failed to decode personal sign request: %w", step.Number, err)
+		}
+		if err := signer.VerifyPersonal(b.From, signer.PersonalMessage(request.Message), step.Signature); err != nil {
+			return fmt.Errorf("step %d: %w", step.Number, err)
+		}
+
+	default:
+		return fmt.Errorf("step %d: unsupported step type %q", step.Number, step.Type)
+	}
+	return nil
+}
+
+// checksum hashes the JSON encoding of the bundle. The unsigned checksum leaves out everything
+// produced by Sign, the signed checksum covers everything but itself.
+func (b *Bundle) checksum(signed bool) (string, error) {
+	c := *b
+	c.SignedChecksum = ""
+	if !signed {
+		c.Checksum = ""
+		c.SignedAt = nil
+		c.Steps = make([]Step, len(b.Steps))
+		for i, step := range b.Steps {
+			step.SignedTx = nil
+			step.Signature = nil
+			c.Steps[i] = step
+		}
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return checksumPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// decodeTx decodes a binary encoded transaction of a step
+func decodeTx(number int, raw hexutil.Bytes) (*types.Transaction, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("step %d has no transaction", number)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("step %d: failed to decode transaction: %w", number, err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,337 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/executor"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+var (
+	testChainID = big.NewInt(1)
+	testTo      = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	attacker    = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+// testBackend is an in-memory chain that mines every sent transaction successfully
+type testBackend struct {
+	mu   sync.Mutex
+	sent []*types.Transaction
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(10)}, nil
+}
+
+func (b *testBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(20), nil
+}
+
+func (b *testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(2), nil
+}
+
+func (b *testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{Reward: [][]*big.Int{{big.NewInt(3)}}}, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 7, nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, tx := range b.sent {
+		if tx.Hash() == txHash {
+			return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func newTestSigner(t *testing.T) *signer.PrivateKeySigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := signer.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// exportActivity exports a prepared transaction and a personal sign request for from
+func exportActivity(t *testing.T, backend *testBackend, from common.Address) *Bundle {
+	t.Helper()
+	txStep := service.ChainActivityStep{Type: service.ChainActivityStepTypePreparedTx}
+	err := txStep.Data.FromPreparedTx(service.PreparedTx{
+		GasUseEstimate:   50000,
+		MethodParameters: service.MethodParameters{To: testTo.Hex(), Value: "5", Calldata: "0x0102"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signStep := service.ChainActivityStep{Type: service.ChainActivityStepTypePersonalSignRequest}
+	if err := signStep.Data.FromPersonalSignRequest(service.PersonalSignRequest{Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	activity := service.ChainActivity{NumberOfSteps: 2, StepNumber: 1, Steps: []service.ChainActivityStep{txStep, signStep}}
+
+	builder, err := executor.NewTxBuilder(backend, executor.WithGasBuffer(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Export(context.Background(), activity, from, testChainID, builder, executor.NewNonceManager(backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// replaceTx rebuilds the transaction of the first step with edit applied
+func replaceTx(t *testing.T, b *Bundle, edit func(tx *types.DynamicFeeTx)) {
+	t.Helper()
+	tx, err := b.Steps[0].Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &types.DynamicFeeTx{
+		ChainID: tx.ChainId(), Nonce: tx.Nonce(), GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap(),
+		Gas: tx.Gas(), To: tx.To(), Value: tx.Value(), Data: tx.Data(),
+	}
+	edit(inner)
+	raw, err := types.NewTx(inner).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Steps[0].Tx = raw
+}
+
+// rechecksum recomputes the unsigned checksum, as anyone editing a bundle can
+func rechecksum(t *testing.T, b *Bundle) {
+	t.Helper()
+	checksum, err := b.checksum(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Checksum = checksum
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(t *testing.T, b *Bundle)
+		wantErr error
+	}{
+		{name: "untouched"},
+		{
+			name: "corrupted",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.Value = big.NewInt(6) })
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name: "other recipient",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.To = &attacker })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other value",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.Value = big.NewInt(1e18) })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other data",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.Data = []byte{9, 9} })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other nonce",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.Nonce++ })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other chain",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.ChainID = big.NewInt(5) })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "gas below the estimate",
+			tamper: func(t *testing.T, b *Bundle) {
+				replaceTx(t, b, func(tx *types.DynamicFeeTx) { tx.Gas = 21000 })
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other request",
+			tamper: func(t *testing.T, b *Bundle) {
+				err := b.Steps[0].Request.Data.FromPreparedTx(service.PreparedTx{
+					GasUseEstimate:   50000,
+					MethodParameters: service.MethodParameters{To: attacker.Hex(), Value: "5", Calldata: "0x0102"},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				rechecksum(t, b)
+			},
+			wantErr: ErrTxMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSigner(t)
+			b := exportActivity(t, &testBackend{}, s.Address())
+			if tt.tamper != nil {
+				tt.tamper(t, b)
+			}
+			err := Sign(context.Background(), b, s)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !b.IsSigned() {
+				t.Fatal("expected a signed bundle")
+			}
+			data, err := b.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Parse(data); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSignRejectsOtherAccount(t *testing.T) {
+	b := exportActivity(t, &testBackend{}, newTestSigner(t).Address())
+	if err := Sign(context.Background(), b, newTestSigner(t)); err == nil {
+		t.Fatal("expected an error for a signer of another account")
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(t *testing.T, b *Bundle, s signer.Signer)
+		wantErr  error
+		wantSent int
+	}{
+		{name: "signed", wantSent: 1},
+		{
+			name:    "unsigned",
+			tamper:  func(t *testing.T, b *Bundle, s signer.Signer) {},
+			wantErr: ErrNotSigned,
+		},
+		{
+			name: "signed transaction replaced",
+			tamper: func(t *testing.T, b *Bundle, s signer.Signer) {
+				if err := Sign(context.Background(), b, s); err != nil {
+					t.Fatal(err)
+				}
+				tx, err := b.Steps[0].Transaction()
+				if err != nil {
+					t.Fatal(err)
+				}
+				other := types.NewTx(&types.DynamicFeeTx{
+					ChainID: testChainID, Nonce: tx.Nonce(), GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap(),
+					Gas: tx.Gas(), To: &attacker, Value: tx.Value(),
+				})
+				signedTx, err := s.SignTx(context.Background(), other, testChainID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw, err := signedTx.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				b.Steps[0].SignedTx = raw
+				checksum, err := b.checksum(true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b.SignedChecksum = checksum
+			},
+			wantErr: errors.New("signed transaction differs"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &testBackend{}
+			s := newTestSigner(t)
+			b := exportActivity(t, backend, s.Address())
+			if tt.tamper != nil {
+				tt.tamper(t, b, s)
+			} else if err := Sign(context.Background(), b, s); err != nil {
+				t.Fatal(err)
+			}
+			result, err := Broadcast(context.Background(), backend, b, WithPollInterval(time.Millisecond))
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && !containsError(err, tt.wantErr)) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(backend.sent) != 0 {
+					t.Fatalf("expected nothing sent, got %d transactions", len(backend.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(backend.sent) != tt.wantSent {
+				t.Fatalf("expected %d sent transactions, got %d", tt.wantSent, len(backend.sent))
+			}
+			if len(result.Steps) != 2 || result.Steps[1].Signature == nil {
+				t.Fatalf("unexpected result: %+v", result.Steps)
+			}
+		})
+	}
+}
+
+// containsError reports whether the message of err contains the message of want
+func containsError(err, want error) bool {
+	return err != nil && want != nil && strings.Contains(err.Error(), want.Error())
+}
@@ -0,0 +1,98 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zarbanio/zarban-go/executor"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+// Export turns the remaining steps of a chain activity into an unsigned bundle for from.
+// PreparedTx steps are priced with builder and get consecutive nonces from nonces; the nonces
+// stay reserved, so mark them with nonces.Broadcast once the bundle is broadcast, or release
+// them with nonces.Release if it never is.
+func Export(ctx context.Context, activity service.ChainActivity, from common.Address, chainID *big.Int, builder *executor.TxBuilder, nonces *executor.NonceManager) (*Bundle, error) {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid chain id: %v", chainID)
+	}
+	first := activity.StepNumber
+	if first < 1 {
+		first = 1
+	}
+
+	b := &Bundle{
+		Format:    Format,
+		Version:   Version,
+		ChainID:   new(big.Int).Set(chainID),
+		From:      from,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	var reserved []uint64
+	release := func() {
+		for _, nonce := range reserved {
+			nonces.Release(from, nonce)
+		}
+	}
+	for number := first; number <= len(activity.Steps); number++ {
+		request := activity.Steps[number-1]
+		step := Step{Number: number, Type: request.Type, Request: request}
+
+		switch request.Type {
+		case service.ChainActivityStepTypePreparedTx:
+			preparedTx, err := request.Data.AsPreparedTx()
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: failed to decode prepared tx: %w", number, err)
+			}
+			nonce, err := nonces.Next(ctx, from)
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: %w", number, err)
+			}
+			reserved = append(reserved, nonce)
+			tx, err := builder.Build(ctx, preparedTx, chainID, nonce)
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: failed to build transaction: %w", number, err)
+			}
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: failed to encode transaction: %w", number, err)
+			}
+			step.Nonce = &nonce
+			step.Tx = raw
+
+		case service.ChainActivityStepTypeEIP712SignRequest:
+			request, err := request.Data.AsEIP712SignRequest()
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: failed to decode eip712 sign request: %w", number, err)
+			}
+			if err := signer.VerifyTypedDataHash(request.TypedData, request.Hash); err != nil {
+				release()
+				return nil, fmt.Errorf("step %d: %w", number, err)
+			}
+
+		case service.ChainActivityStepTypePersonalSignRequest:
+
+		default:
+			release()
+			return nil, fmt.Errorf("step %d: %w: %q", number, executor.ErrUnsupportedStep, request.Type)
+		}
+		b.Steps = append(b.Steps, step)
+	}
+
+	checksum, err := b.checksum(false)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	b.Checksum = checksum
+	return b, nil
+}
@@ -0,0 +1,83 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+// Sign verifies an unsigned bundle and signs every step with s, which must sign for the
+// account the bundle was exported for. Transactions are checked against their requests and
+// EIP-712 requests against their hash first.
+func Sign(ctx context.Context, b *Bundle, s signer.Signer) error {
+	if b.IsSigned() {
+		return errors.New("bundle is already signed")
+	}
+	if err := b.Verify(); err != nil {
+		return err
+	}
+	if s.Address() != b.From {
+		return fmt.Errorf("bundle is for %s, signer is %s", b.From.Hex(), s.Address().Hex())
+	}
+
+	for i := range b.Steps {
+		step := &b.Steps[i]
+		switch step.Type {
+		case service.ChainActivityStepTypePreparedTx:
+			tx, err := step.Transaction()
+			if err != nil {
+				return err
+			}
+			signedTx, err := s.SignTx(ctx, tx, b.ChainID)
+			if err != nil {
+				return fmt.Errorf("step %d: failed to sign transaction: %w", step.Number, err)
+			}
+			raw, err := signedTx.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("step %d: failed to encode signed transaction: %w", step.Number, err)
+			}
+			step.SignedTx = raw
+
+		case service.ChainActivityStepTypeEIP712SignRequest:
+			request, err := step.Request.Data.AsEIP712SignRequest()
+			if err != nil {
+				return fmt.Errorf("step %d: failed to decode eip712 sign request: %w", step.Number, err)
+			}
+			if err := signer.VerifyTypedDataHash(request.TypedData, request.Hash); err != nil {
+				return fmt.Errorf("step %d: %w", step.Number, err)
+			}
+			signature, err := s.SignTypedData(ctx, request.TypedData)
+			if err != nil {
+				return fmt.Errorf("step %d: failed to sign typed data: %w", step.Number, err)
+			}
+			step.Signature = signature
+
+		case service.ChainActivityStepTypePersonalSignRequest:
+			request, err := step.Request.Data.AsPersonalSignRequest()
+			if err != nil {
+				return fmt.Errorf("step %d: failed to decode personal sign request: %w", step.Number, err)
+			}
+			signature, err := s.SignPersonal(ctx, signer.PersonalMessage(request.Message))
+			if err != nil {
+				return fmt.Errorf("step %d: failed to sign personal message: %w", step.Number, err)
+			}
+			step.Signature = signature
+
+		default:
+			return fmt.Errorf("step %d: unsupported step type %q", step.Number, step.Type)
+		}
+	}
+
+	signedAt := time.Now().UTC().Truncate(time.Second)
+	b.SignedAt = &signedAt
+	checksum, err := b.checksum(true)
+	if err != nil {
+		return err
+	}
+	b.SignedChecksum = checksum
+	return nil
+}
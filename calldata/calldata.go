@@ -0,0 +1,176 @@
+// Package calldata turns the opaque calldata of prepared transactions into method names and
+// named, typed arguments using an embedded set of Zarban protocol ABIs
+package calldata
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// ErrUnknownMethod is returned when no known ABI has a method matching the calldata selector
+var ErrUnknownMethod = errors.New("unknown method")
+
+//go:embed abis/*.json
+var abiFiles embed.FS
+
+// embeddedContracts lists the embedded ABIs in lookup order. When several ABIs share a
+// selector, such as approve(address,uint256), the first one wins.
+var embeddedContracts = []string{
+	"erc20",
+	"ds_proxy",
+	"proxy_actions",
+	"cdp_manager",
+	"lending_pool",
+	"permit2",
+	"nonfungible_position_manager",
+	"uniswap_v3_staker",
+	"staking_rewards",
+}
+
+var (
+	embeddedOnce sync.Once
+	embedded     []ContractABI
+)
+
+// ContractABI is a named contract ABI
+type ContractABI struct {
+	Name string
+	ABI  abi.ABI
+}
+
+// Embedded returns the embedded protocol ABIs: the cdp manager, DSProxy and the proxy actions,
+// the lending pool and its ETH gateway, Permit2, the Uniswap V3 NonfungiblePositionManager and
+// staker, staking rewards and ERC-20 tokens
+func Embedded() []ContractABI {
+	embeddedOnce.Do(func() {
+		for _, name := range embeddedContracts {
+			data, err := abiFiles.ReadFile(path.Join("abis", name+".json"))
+			if err != nil {
+				panic(fmt.Sprintf("calldata: missing embedded abi %s: %v", name, err))
+			}
+			contractABI, err := abi.JSON(bytes.NewReader(data))
+			if err != nil {
+				panic(fmt.Sprintf("calldata: invalid embedded abi %s: %v", name, err))
+			}
+			embedded = append(embedded, ContractABI{Name: name, ABI: contractABI})
+		}
+	})
+	return embedded
+}
+
+// ABIs returns the embedded protocol ABIs without their names, e.g. to decode custom errors
+func ABIs() []abi.ABI {
+	contracts := Embedded()
+	abis := make([]abi.ABI, len(contracts))
+	for i, contract := range contracts {
+		abis[i] = contract.ABI
+	}
+	return abis
+}
+
+// Decoder decodes calldata with a list of contract ABIs
+type Decoder struct {
+	contracts []ContractABI
+}
+
+// NewDecoder creates a Decoder looking methods up in contracts, in order, or in the embedded
+// protocol ABIs if none are given
+func NewDecoder(contracts ...ContractABI) *Decoder {
+	if len(contracts) == 0 {
+		contracts = Embedded()
+	}
+	return &Decoder{contracts: contracts}
+}
+
+// DecodeMethodParameters decodes the calldata of a prepared transaction
+func (d *Decoder) DecodeMethodParameters(params service.MethodParameters) (*Method, error) {
+	return d.DecodeHex(params.Calldata)
+}
+
+// DecodeHex decodes 0x-prefixed hex calldata
+func (d *Decoder) DecodeHex(calldata string) (*Method, error) {
+	data, err := hexutil.Decode(calldata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode calldata: %w", err)
+	}
+	return d.Decode(data)
+}
+
+// Decode looks the selector of data up and unpacks its arguments. Arguments holding calldata
+// themselves, like the data of DSProxy execute or multicall, are decoded as nested calls.
+func (d *Decoder) Decode(data []byte) (*Method, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: calldata too short", ErrUnknownMethod)
+	}
+	for _, contract := range d.contracts {
+		method, err := contract.ABI.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		return d.newMethod(contract.Name, method, values), nil
+	}
+	return nil, fmt.Errorf("%w: selector %s", ErrUnknownMethod, hexutil.Encode(data[:4]))
+}
+
+// newMethod builds the decoded view of a method call
+func (d *Decoder) newMethod(contract string, method *abi.Method, values []interface{}) *Method {
+	m := &Method{
+		Contract:  contract,
+		Name:      method.RawName,
+		Signature: method.Sig,
+		Payable:   method.IsPayable(),
+		Args:      make([]Arg, len(method.Inputs)),
+	}
+	for i, input := range method.Inputs {
+		arg := Arg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: values[i],
+			Text:  formatValue(input.Type, values[i]),
+		}
+		arg.Calls = d.nestedCalls(input.Type, values[i])
+		m.Args[i] = arg
+	}
+	return m
+}
+
+// nestedCalls decodes bytes and bytes[] arguments that hold known calls
+func (d *Decoder) nestedCalls(t abi.Type, value interface{}) []*Method {
+	switch {
+	case t.T == abi.BytesTy:
+		data, ok := value.([]byte)
+		if !ok {
+			return nil
+		}
+		if call, err := d.Decode(data); err == nil {
+			return []*Method{call}
+		}
+	case t.T == abi.SliceTy && t.Elem.T == abi.BytesTy:
+		elements, ok := value.([][]byte)
+		if !ok || len(elements) == 0 {
+			return nil
+		}
+		calls := make([]*Method, 0, len(elements))
+		for _, data := range elements {
+			call, err := d.Decode(data)
+			if err != nil {
+				return nil
+			}
+			calls = append(calls, call)
+		}
+		return calls
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package calldata
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// openLockETHAndDraw is a DSProxy execute call opening an ETH-A vault and drawing 1000 DAI on
+// MakerDAO mainnet, whose proxy actions the Zarban ones are forked from
+var openLockETHAndDraw = "0x1cff79cd" +
+	"00000000000000000000000082ecd135dce65fbc6dbdd0e4237e0af93ffd5038" + // target
+	"0000000000000000000000000000000000000000000000000000000000000040" + // data offset
+	"00000000000000000000000000000000000000000000000000000000000000c4" + // data length
+	"e685cc04" +
+	"0000000000000000000000005ef30b9986345249bc32d8928b7ee64de9435e39" + // manager
+	"00000000000000000000000019c0976f590d67707e62397c87829d896dc0f1f1" + // jug
+	"0000000000000000000000002f0b23f53734252bda2277357e97e1517d6b042a" + // ethJoin
+	"0000000000000000000000009759a6ac90977b93b58547b4a71c78317f391a28" + // zarJoin
+	"4554482d41000000000000000000000000000000000000000000000000000000" + // ilk
+	"00000000000000000000000000000000000000000000003635c9adc5dea00000" + // wadD
+	strings.Repeat("00", 28)
+
+func TestDecodeProxyExecute(t *testing.T) {
+	method, err := NewDecoder().DecodeHex(openLockETHAndDraw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method.Contract != "ds_proxy" || method.Signature != "execute(address,bytes)" {
+		t.Fatalf("expected DSProxy execute, got %s [%s]", method.Signature, method.Contract)
+	}
+	target, _ := method.Arg("target")
+	if target.Value != common.HexToAddress("0x82ecD135Dce65Fbc6DbdD0e4237E0AF93FFD5038") {
+		t.Fatalf("unexpected target %s", target.Text)
+	}
+
+	data, _ := method.Arg("data")
+	if len(data.Calls) != 1 {
+		t.Fatalf("expected the data to be decoded as one call, got %d", len(data.Calls))
+	}
+	call := data.Calls[0]
+	if call.Contract != "proxy_actions" || call.Name != "openLockETHAndDraw" {
+		t.Fatalf("expected openLockETHAndDraw of the proxy actions, got %s [%s]", call.Name, call.Contract)
+	}
+	if wad, _ := call.Arg("wadD"); wad.Value.(*big.Int).Cmp(new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))) != 0 {
+		t.Fatalf("unexpected wadD %s", wad.Text)
+	}
+	if !method.AcceptsValue() {
+		t.Fatal("expected the call to accept the ether locked in the vault")
+	}
+
+	want := `execute(address,bytes) [ds_proxy]
+  target (address): 0x82ecD135Dce65Fbc6DbdD0e4237E0AF93FFD5038
+  data (bytes): ` + "0x" + openLockETHAndDraw[2+8+64*3:2+8+64*3+196*2] + `
+    openLockETHAndDraw(address,address,address,address,bytes32,uint256) [proxy_actions]
+      manager (address): 0x5ef30b9986345249bc32d8928B7ee64DE9435E39
+      jug (address): 0x19c0976f590D67707E62397C87829d896Dc0f1F1
+      ethJoin (address): 0x2F0b23f53734252Bda2277357e97e1517d6B042A
+      zarJoin (address): 0x9759A6Ac90977b93B58547b4A71c78317f391A28
+      ilk (bytes32): 0x4554482d41000000000000000000000000000000000000000000000000000000 ("ETH-A")
+      wadD (uint256): 1000000000000000000000`
+	if got := method.String(); got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+// embeddedABI returns the embedded ABI called name
+func embeddedABI(t *testing.T, name string) ContractABI {
+	t.Helper()
+	for _, contract := range Embedded() {
+		if contract.Name == name {
+			return contract
+		}
+	}
+	t.Fatalf("no embedded abi %s", name)
+	return ContractABI{}
+}
+
+func TestDecodeMulticall(t *testing.T) {
+	positionManager := embeddedABI(t, "nonfungible_position_manager")
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	unwrap, err := positionManager.ABI.Pack("unwrapWETH9", big.NewInt(5), recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund, err := positionManager.ABI.Pack("refundETH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		calls     [][]byte
+		wantCalls []string
+	}{
+		{name: "known calls", calls: [][]byte{unwrap, refund}, wantCalls: []string{"unwrapWETH9", "refundETH"}},
+		// a call that cannot be decoded leaves the others undecoded, so nothing is hidden
+		{name: "unknown call", calls: [][]byte{unwrap, hexutil.MustDecode("0xdeadbeef")}},
+		{name: "no calls", calls: [][]byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := positionManager.ABI.Pack("multicall", tt.calls)
+			if err != nil {
+				t.Fatal(err)
+			}
+			method, err := NewDecoder().Decode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			arg, _ := method.Arg("data")
+			if len(arg.Calls) != len(tt.wantCalls) {
+				t.Fatalf("expected %d nested calls, got %d", len(tt.wantCalls), len(arg.Calls))
+			}
+			for i, name := range tt.wantCalls {
+				if arg.Calls[i].Name != name {
+					t.Fatalf("expected call %d to be %s, got %s", i, name, arg.Calls[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeUnknown(t *testing.T) {
+	tests := []struct {
+		name     string
+		calldata string
+	}{
+		{name: "unknown selector", calldata: "0xdeadbeef0000000000000000000000000000000000000000000000000000000000000001"},
+		{name: "short calldata", calldata: "0x1cff79"},
+		{name: "empty calldata", calldata: "0x"},
+		// execute(address,bytes) whose arguments cannot be unpacked
+		{name: "truncated arguments", calldata: openLockETHAndDraw[:2+8+64]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDecoder().DecodeHex(tt.calldata); !errors.Is(err, ErrUnknownMethod) {
+				t.Fatalf("expected %v, got %v", ErrUnknownMethod, err)
+			}
+		})
+	}
+	if _, err := NewDecoder().DecodeHex("0xzz"); err == nil || errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("expected a hex decoding error, got %v", err)
+	}
+}
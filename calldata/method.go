@@ -0,0 +1,135 @@
+package calldata
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Method is a decoded method call
+type Method struct {
+	Contract  string `json:"contract"`
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Payable   bool   `json:"payable"`
+	Args      []Arg  `json:"args"`
+}
+
+// Arg is a decoded argument of a method call. Value holds the go-ethereum representation of
+// the argument and Text a human readable one.
+type Arg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"-"`
+	Text  string      `json:"value"`
+	Calls []*Method   `json:"calls,omitempty"`
+}
+
+// Arg returns the argument called name
+func (m *Method) Arg(name string) (Arg, bool) {
+	for _, arg := range m.Args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return Arg{}, false
+}
+
+// AcceptsValue reports whether the call can be sent with ether. Payable calls wrapping other
+// calls, like DSProxy execute or multicall, only accept ether if one of the wrapped calls does.
+func (m *Method) AcceptsValue() bool {
+	if !m.Payable {
+		return false
+	}
+	nested := false
+	for _, arg := range m.Args {
+		for _, call := range arg.Calls {
+			nested = true
+			if call.AcceptsValue() {
+				return true
+			}
+		}
+	}
+	return !nested
+}
+
+// String returns the call with one argument per line, nested calls indented below the
+// argument holding them
+func (m *Method) String() string {
+	var b strings.Builder
+	m.write(&b, "")
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// write writes the call at the given indentation
+func (m *Method) write(b *strings.Builder, indent string) {
+	fmt.Fprintf(b, "%s%s [%s]\n", indent, m.Signature, m.Contract)
+	for _, arg := range m.Args {
+		name := arg.Name
+		if name == "" {
+			name = "_"
+		}
+		fmt.Fprintf(b, "%s  %s (%s): %s\n", indent, name, arg.Type, arg.Text)
+		for _, call := range arg.Calls {
+			call.write(b, indent+"    ")
+		}
+	}
+}
+
+// formatValue renders an unpacked argument value of type t
+func formatValue(t abi.Type, value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Encode(v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch t.T {
+	case abi.FixedBytesTy:
+		raw := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(raw), rv)
+		if text := printable(raw); text != "" {
+			return fmt.Sprintf("%s (%q)", hexutil.Encode(raw), text)
+		}
+		return hexutil.Encode(raw)
+	case abi.SliceTy, abi.ArrayTy:
+		elements := make([]string, rv.Len())
+		for i := range elements {
+			elements[i] = formatValue(*t.Elem, rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case abi.TupleTy:
+		fields := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			fields[i] = fmt.Sprintf("%s: %s", t.TupleRawNames[i], formatValue(*elem, rv.Field(i).Interface()))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+// printable returns the text of a right-padded fixed bytes value, like the ilk "ETH-A", or
+// an empty string if it does not hold printable ASCII
+func printable(raw []byte) string {
+	text := strings.TrimRight(string(raw), "\x00")
+	if text == "" {
+		return ""
+	}
+	for _, c := range []byte(text) {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return text
+}
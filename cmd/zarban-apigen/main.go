@@ -0,0 +1,459 @@
+// Command zarban-apigen generates the typed API facade and the operation table of a client
+// package from its OpenAPI spec and the client generated by oapi-codegen, and optionally the
+// accessors of its Timestamp model.
+//
+// Usage:
+//
+//	zarban-apigen -spec api_specs/service.openapi.yaml -client service/client.go -package service -out service/api.go -timestamp service/timestamp.go
+//
+// The spec provides the operations, their methods and paths, and the client the Go signatures
+// and response models, so the facade always matches both.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods are the HTTP methods of an OpenAPI path item, in the order operations are listed
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// operation is an operation of the spec
+type operation struct {
+	ID      string
+	Method  string
+	Path    string
+	Summary string
+
+	// Name, Params and Args are taken from the ClientInterface method of the operation
+	Name   string
+	Params string
+	Args   string
+
+	// Model is the type of the first 2xx JSON response, or empty if there is none
+	Model string
+
+	// Success reports whether the operation has a 2xx response
+	Success bool
+}
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI spec of the API")
+	clientPath := flag.String("client", "", "client generated by oapi-codegen from the spec")
+	pkg := flag.String("package", "", "package name of the generated code")
+	out := flag.String("out", "", "file to write the generated code to")
+	timestampOut := flag.String("timestamp", "", "file to write the Timestamp accessors to, if set")
+	flag.Parse()
+
+	if *specPath == "" || *clientPath == "" || *pkg == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	operations, err := loadOperations(*specPath)
+	if err != nil {
+		log.Fatalf("Failed to load spec: %v", err)
+	}
+	if err := resolveSignatures(*clientPath, operations); err != nil {
+		log.Fatalf("Failed to load client: %v", err)
+	}
+	code, err := generate(*pkg, filepath.ToSlash(*specPath), operations)
+	if err != nil {
+		log.Fatalf("Failed to generate code: %v", err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+
+	if *timestampOut == "" {
+		return
+	}
+	code, err = generateTimestamp(*pkg, filepath.ToSlash(*specPath))
+	if err != nil {
+		log.Fatalf("Failed to generate code: %v", err)
+	}
+	if err := os.WriteFile(*timestampOut, code, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *timestampOut, err)
+	}
+}
+
+// loadOperations reads the operations of an OpenAPI spec in the order of its paths
+func loadOperations(path string) ([]*operation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec struct {
+		Paths yaml.Node `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec.Paths.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("spec has no paths")
+	}
+
+	var operations []*operation
+	for i := 0; i+1 < len(spec.Paths.Content); i += 2 {
+		route := spec.Paths.Content[i].Value
+		var item map[string]struct {
+			OperationID string               `yaml:"operationId"`
+			Summary     string               `yaml:"summary"`
+			Responses   map[string]yaml.Node `yaml:"responses"`
+		}
+		if err := decodeOperations(spec.Paths.Content[i+1], &item); err != nil {
+			return nil, fmt.Errorf("path %s: %w", route, err)
+		}
+		for _, method := range methods {
+			op, ok := item[method]
+			if !ok {
+				continue
+			}
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s has no operationId", strings.ToUpper(method), route)
+			}
+			success := false
+			for code := range op.Responses {
+				if strings.HasPrefix(code, "2") {
+					success = true
+				}
+			}
+			operations = append(operations, &operation{
+				ID:      op.OperationID,
+				Method:  strings.ToUpper(method),
+				Path:    route,
+				Summary: strings.TrimSpace(op.Summary),
+				Success: success,
+			})
+		}
+	}
+	return operations, nil
+}
+
+// decodeOperations decodes the operations of a path item, skipping its other fields
+func decodeOperations(node *yaml.Node, v interface{}) error {
+	filtered := yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		for _, method := range methods {
+			if node.Content[i].Value == method {
+				filtered.Content = append(filtered.Content, node.Content[i], node.Content[i+1])
+			}
+		}
+	}
+	return filtered.Decode(v)
+}
+
+// resolveSignatures fills in the Go signature and response model of each operation from the
+// ClientInterface and response types of the generated client
+func resolveSignatures(path string, operations []*operation) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	methods := make(map[string]*ast.FuncType)
+	responses := make(map[string]*ast.StructType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gen.Specs {
+			spec := s.(*ast.TypeSpec)
+			switch t := spec.Type.(type) {
+			case *ast.InterfaceType:
+				if spec.Name.Name != "ClientInterface" {
+					continue
+				}
+				for _, m := range t.Methods.List {
+					if ft, ok := m.Type.(*ast.FuncType); ok && len(m.Names) == 1 {
+						methods[m.Names[0].Name] = ft
+					}
+				}
+			case *ast.StructType:
+				responses[spec.Name.Name] = t
+			}
+		}
+	}
+
+	for _, op := range operations {
+		name := strings.ToUpper(op.ID[:1]) + op.ID[1:]
+		ft, ok := methods[name]
+		if !ok {
+			return fmt.Errorf("ClientInterface has no method %s for operation %s", name, op.ID)
+		}
+		op.Name = name
+
+		var params, args []string
+		for _, field := range ft.Params.List {
+			typ := render(fset, field.Type)
+			for _, ident := range field.Names {
+				switch {
+				case ident.Name == "ctx":
+					continue
+				case strings.HasPrefix(typ, "..."):
+					continue
+				}
+				params = append(params, ident.Name+" "+typ)
+				args = append(args, ident.Name)
+			}
+		}
+		op.Params = strings.Join(params, ", ")
+		op.Args = strings.Join(args, ", ")
+
+		if response, ok := responses[name+"Response"]; ok {
+			op.Model = successModel(fset, response)
+		}
+	}
+	return nil
+}
+
+// successModel returns the type of the first JSON2xx field of a response type
+func successModel(fset *token.FileSet, response *ast.StructType) string {
+	type field struct {
+		name string
+		typ  ast.Expr
+	}
+	var fields []field
+	for _, f := range response.Fields.List {
+		for _, ident := range f.Names {
+			if strings.HasPrefix(ident.Name, "JSON2") {
+				fields = append(fields, field{ident.Name, f.Type})
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	typ := fields[0].typ
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	return render(fset, typ)
+}
+
+// render prints a type expression
+func render(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		log.Fatalf("Failed to print type: %v", err)
+	}
+	return buf.String()
+}
+
+// generate renders and formats the generated file
+func generate(pkg, spec string, operations []*operation) ([]byte, error) {
+	return execute(fileTemplate, struct {
+		Package    string
+		Spec       string
+		Operations []*operation
+	}{pkg, spec, operations})
+}
+
+// generateTimestamp renders and formats the Timestamp accessors
+func generateTimestamp(pkg, spec string) ([]byte, error) {
+	return execute(timestampTemplate, struct {
+		Package string
+		Spec    string
+	}{pkg, spec})
+}
+
+// execute renders a template and formats the result
+func execute(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, buf.Bytes())
+	}
+	return code, nil
+}
+
+var fileTemplate = template.Must(template.New("api").Funcs(template.FuncMap{
+	"comma": func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return s + ", "
+	},
+}).Parse(`// Code generated by zarban-apigen from {{.Spec}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Operation is an operation of the API, as declared in the OpenAPI spec
+type Operation struct {
+	ID     string
+	Method string
+	Path   string
+}
+
+// operations lists the operations of the API in the order of the spec
+var operations = []Operation{
+{{- range .Operations}}
+	{ID: "{{.ID}}", Method: "{{.Method}}", Path: "{{.Path}}"},
+{{- end}}
+}
+
+// Operations returns the operations of the API
+func Operations() []Operation {
+	return append([]Operation(nil), operations...)
+}
+
+// OperationID returns the operationId of a request to the API, or an empty string if the
+// request does not match any operation. Paths are matched against the end of the request
+// path, so servers with a path prefix are supported, and literal segments take precedence over
+// path parameters.
+func OperationID(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	id, best := "", -1
+	for _, op := range operations {
+		if op.Method != req.Method {
+			continue
+		}
+		if literals, ok := matchPath(op.Path, segments); ok && literals > best {
+			id, best = op.ID, literals
+		}
+	}
+	return id
+}
+
+// matchPath matches a path template against the trailing request path segments, and returns
+// the number of literal segments matched
+func matchPath(template string, segments []string) (int, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) > len(segments) {
+		return 0, false
+	}
+	segments = segments[len(segments)-len(parts):]
+	literals := 0
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// API calls the operations of the API and returns their decoded responses. Failed requests
+// and error responses are returned as *APIError.
+type API struct {
+	client ClientInterface
+}
+
+// NewAPI creates an API calling the operations with client
+func NewAPI(client ClientInterface) *API {
+	return &API{client: client}
+}
+
+// NewAPIWithServer creates an API with a new Client for server
+func NewAPIWithServer(server string, opts ...ClientOption) (*API, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPI(client), nil
+}
+
+// Client returns the client used by the API
+func (a *API) Client() ClientInterface {
+	return a.client
+}
+{{range .Operations}}{{if .Success}}
+// {{.Name}} calls {{.Method}} {{.Path}}{{if .Summary}}: {{.Summary}}{{end}}
+{{- if .Model}}
+func (a *API) {{.Name}}(ctx context.Context, {{comma .Params}}reqEditors ...RequestEditorFn) ({{.Model}}, error) {
+	var response {{.Model}}
+	httpResponse, err := a.client.{{.Name}}(ctx, {{comma .Args}}reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+{{- else}}
+func (a *API) {{.Name}}(ctx context.Context, {{comma .Params}}reqEditors ...RequestEditorFn) error {
+	httpResponse, err := a.client.{{.Name}}(ctx, {{comma .Args}}reqEditors...)
+	return checkResponse(ctx, httpResponse, err)
+}
+{{- end}}
+{{end}}{{end}}`))
+
+var timestampTemplate = template.Must(template.New("timestamp").Parse(`// Code generated by zarban-apigen from {{.Spec}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zarbanio/zarban-go/jalaali"
+)
+
+// timestampLayouts are the layouts accepted for the Gregorian date of a Timestamp
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Time returns the instant of the timestamp in UTC. The Gregorian date is used, or the
+// Jalaali date if the Gregorian one is missing. Dates without a zone are read as UTC.
+func (t Timestamp) Time() (time.Time, error) {
+	if t.Gregorian == "" {
+		if t.Jalaali == "" {
+			return time.Time{}, errors.New("empty timestamp")
+		}
+		parsed, err := jalaali.Parse(t.Jalaali, time.UTC)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return parsed.UTC(), nil
+	}
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, t.Gregorian); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp: %q", t.Gregorian)
+}
+
+// NewTimestamp returns the timestamp of an instant, with its Gregorian and Jalaali dates in UTC
+func NewTimestamp(instant time.Time) Timestamp {
+	instant = instant.UTC()
+	return Timestamp{
+		Gregorian: instant.Format(time.RFC3339),
+		Jalaali:   jalaali.Format(instant, jalaali.RFC3339),
+	}
+}
+`))
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGenerateUpToDate checks that regenerating the client packages produces no diff
+func TestGenerateUpToDate(t *testing.T) {
+	for _, pkg := range []string{"wallet", "service"} {
+		t.Run(pkg, func(t *testing.T) {
+			spec := "api_specs/" + pkg + ".openapi.yaml"
+			operations, err := loadOperations(filepath.Join("..", "..", spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := resolveSignatures(filepath.Join("..", "..", pkg, "client.go"), operations); err != nil {
+				t.Fatal(err)
+			}
+			api, err := generate(pkg, spec, operations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			timestamp, err := generateTimestamp(pkg, spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, code := range map[string][]byte{"api.go": api, "timestamp.go": timestamp} {
+				existing, err := os.ReadFile(filepath.Join("..", "..", pkg, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(existing, code) {
+					t.Errorf("%s/%s is out of date, run make codegen", pkg, name)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadOperations(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []operation
+		wantErr bool
+	}{
+		{
+			name: "operations in spec order",
+			spec: `
+paths:
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+    delete:
+      operationId: deleteItem
+      responses:
+        "204": {}
+    get:
+      operationId: getItem
+      summary: " Get an item "
+      responses:
+        "200": {}
+        "404": {}
+  /health:
+    get:
+      operationId: health
+      responses:
+        default: {}
+`,
+			want: []operation{
+				{ID: "getItem", Method: "GET", Path: "/items/{id}", Summary: "Get an item", Success: true},
+				{ID: "deleteItem", Method: "DELETE", Path: "/items/{id}", Success: true},
+				{ID: "health", Method: "GET", Path: "/health"},
+			},
+		},
+		{
+			name: "missing operationId",
+			spec: `
+paths:
+  /items:
+    get:
+      responses:
+        "200": {}
+`,
+			wantErr: true,
+		},
+		{name: "no paths", spec: "openapi: 3.0.0\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			if err := os.WriteFile(path, []byte(tt.spec), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			operations, err := loadOperations(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			var got []operation
+			for _, op := range operations {
+				got = append(got, *op)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Command zarban-sign signs a chain activity bundle on an offline machine.
+//
+// Usage:
+//
+//	zarban-sign -in activity.json -out activity.signed.json -keystore UTC--... -password-file pass.txt
+//	zarban-sign -in activity.json -out activity.signed.json -mnemonic-file words.txt -path "m/44'/60'/0'/0/0"
+//
+// The steps of the bundle are printed for review and signed only once confirmed, unless -yes is
+// given.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/bundle"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+func main() {
+	in := flag.String("in", "", "unsigned bundle to sign")
+	out := flag.String("out", "", "file to write the signed bundle to")
+	keystorePath := flag.String("keystore", "", "keystore file of the signing account")
+	mnemonicFile := flag.String("mnemonic-file", "", "file holding the BIP-39 mnemonic of the signing account")
+	path := flag.String("path", "m/44'/60'/0'/0/0", "derivation path used with -mnemonic-file")
+	passwordFile := flag.String("password-file", "", "file holding the keystore password or the mnemonic passphrase")
+	yes := flag.Bool("yes", false, "sign without asking for confirmation")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("Failed to read bundle: %v", err)
+	}
+	b, err := bundle.Parse(data)
+	if err != nil {
+		log.Fatalf("Failed to verify bundle: %v", err)
+	}
+
+	s, err := loadSigner(*keystorePath, *mnemonicFile, *path, *passwordFile)
+	if err != nil {
+		log.Fatalf("Failed to load signer: %v", err)
+	}
+
+	describe(b)
+	if !*yes && !confirm("Sign this bundle? [y/N] ") {
+		log.Fatal("Signing aborted")
+	}
+	if err := bundle.Sign(context.Background(), b, s); err != nil {
+		log.Fatalf("Failed to sign bundle: %v", err)
+	}
+	signed, err := b.Marshal()
+	if err != nil {
+		log.Fatalf("Failed to encode bundle: %v", err)
+	}
+	if err := os.WriteFile(*out, signed, 0o600); err != nil {
+		log.Fatalf("Failed to write bundle: %v", err)
+	}
+	fmt.Printf("Signed bundle written to %s\n", *out)
+}
+
+// loadSigner opens the keystore or mnemonic given on the command line
+func loadSigner(keystorePath, mnemonicFile, path, passwordFile string) (signer.Signer, error) {
+	var password string
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	switch {
+	case keystorePath != "" && mnemonicFile != "":
+		return nil, fmt.Errorf("use either -keystore or -mnemonic-file")
+	case keystorePath != "":
+		return signer.NewKeystoreFileSigner(keystorePath, password)
+	case mnemonicFile != "":
+		data, err := os.ReadFile(mnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic file: %w", err)
+		}
+		m, err := signer.NewMnemonic(strings.TrimSpace(string(data)), password)
+		if err != nil {
+			return nil, err
+		}
+		return m.Derive(path)
+	}
+	return nil, fmt.Errorf("one of -keystore or -mnemonic-file is required")
+}
+
+// describe prints the steps of a bundle for review
+func describe(b *bundle.Bundle) {
+	fmt.Printf("Bundle for %s on chain %s, created %s\n", b.From.Hex(), b.ChainID, b.CreatedAt)
+	decoder := calldata.NewDecoder()
+	for _, step := range b.Steps {
+		switch step.Type {
+		case service.ChainActivityStepTypePreparedTx:
+			tx, err := step.Transaction()
+			if err != nil {
+				log.Fatalf("Failed to decode step %d: %v", step.Number, err)
+			}
+			to := "contract creation"
+			if tx.To() != nil {
+				to = tx.To().Hex()
+			}
+			fmt.Printf("  %d. transaction nonce %d to %s value %s gas %d max fee %s wei\n",
+				step.Number, tx.Nonce(), to, tx.Value(), tx.Gas(), new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas())))
+			if len(tx.Data()) == 0 {
+				continue
+			}
+			method, err := decoder.Decode(tx.Data())
+			if err != nil {
+				fmt.Printf("     data %s (%v)\n", hexutil.Encode(tx.Data()), err)
+				continue
+			}
+			for _, line := range strings.Split(method.String(), "\n") {
+				fmt.Printf("     %s\n", line)
+			}
+		case service.ChainActivityStepTypeEIP712SignRequest:
+			request, err := step.Request.Data.AsEIP712SignRequest()
+			if err != nil {
+				log.Fatalf("Failed to decode step %d: %v", step.Number, err)
+			}
+			fmt.Printf("  %d. typed data %s for %s (%s)\n",
+				step.Number, request.TypedData.PrimaryType, request.TypedData.Domain.Name, request.TypedData.Domain.VerifyingContract)
+		case service.ChainActivityStepTypePersonalSignRequest:
+			request, err := step.Request.Data.AsPersonalSignRequest()
+			if err != nil {
+				log.Fatalf("Failed to decode step %d: %v", step.Number, err)
+			}
+			fmt.Printf("  %d. personal message %q\n", step.Number, request.Message)
+		}
+	}
+}
+
+// confirm asks a yes or no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Print(question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// Package dsmath implements the fixed-point arithmetic of the stablecoin system contracts:
+// wads with 18 decimals, rays with 27 decimals and rads with 45 decimals. Products and
+// quotients are rounded half up like DSMath, so results match the values computed on chain.
+// Negative values, such as the signed deltas of frob, are rounded half away from zero.
+package dsmath
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/money"
+)
+
+// Number of decimals of each fixed-point unit
+const (
+	WadDecimals = 18
+	RayDecimals = 27
+	RadDecimals = 45
+)
+
+var (
+	wad = pow10(WadDecimals)
+	ray = pow10(RayDecimals)
+	rad = pow10(RadDecimals)
+)
+
+// Wad returns 1 as a wad, 10^18
+func Wad() *big.Int {
+	return new(big.Int).Set(wad)
+}
+
+// Ray returns 1 as a ray, 10^27
+func Ray() *big.Int {
+	return new(big.Int).Set(ray)
+}
+
+// Rad returns 1 as a rad, 10^45
+func Rad() *big.Int {
+	return new(big.Int).Set(rad)
+}
+
+// Wmul multiplies two wads, rounding half away from zero
+func Wmul(x, y *big.Int) *big.Int {
+	return mul(x, y, wad)
+}
+
+// Wdiv divides two wads, rounding half away from zero. It panics if y is zero, like
+// big.Int.Quo.
+func Wdiv(x, y *big.Int) *big.Int {
+	return div(x, y, wad)
+}
+
+// Rmul multiplies two rays, or a wad by a ray returning a wad, rounding half away from zero
+func Rmul(x, y *big.Int) *big.Int {
+	return mul(x, y, ray)
+}
+
+// Rdiv divides two rays, or a wad by a ray returning a wad, rounding half away from zero. It
+// panics if y is zero, like big.Int.Quo.
+func Rdiv(x, y *big.Int) *big.Int {
+	return div(x, y, ray)
+}
+
+// Rpow raises the ray x to the integer power n by squaring, rounding each step like the rpow of
+// DSMath and Jug, e.g. to compound a per second rate over n seconds
+func Rpow(x *big.Int, n uint64) *big.Int {
+	z := Ray()
+	if n%2 != 0 {
+		z.Set(x)
+	}
+	base := new(big.Int).Set(x)
+	for n /= 2; n != 0; n /= 2 {
+		base = Rmul(base, base)
+		if n%2 != 0 {
+			z = Rmul(z, base)
+		}
+	}
+	return z
+}
+
+// WadToRay converts a wad to a ray
+func WadToRay(x *big.Int) *big.Int {
+	return new(big.Int).Mul(x, pow10(RayDecimals-WadDecimals))
+}
+
+// RayToWad converts a ray to a wad, rounding half away from zero
+func RayToWad(x *big.Int) *big.Int {
+	return round(x, pow10(RayDecimals-WadDecimals))
+}
+
+// WadToRad converts a wad to a rad
+func WadToRad(x *big.Int) *big.Int {
+	return new(big.Int).Mul(x, pow10(RadDecimals-WadDecimals))
+}
+
+// RadToWad converts a rad to a wad, rounding half away from zero
+func RadToWad(x *big.Int) *big.Int {
+	return round(x, pow10(RadDecimals-WadDecimals))
+}
+
+// MulWadRay multiplies a wad by a ray returning an exact rad, like the debt of a vault
+// computed by the Vat from its normalized debt and the rate of its ilk
+func MulWadRay(x, y *big.Int) *big.Int {
+	return new(big.Int).Mul(x, y)
+}
+
+// ToWad converts a decimal amount to a wad, failing if it has more than 18 decimals
+func ToWad(a money.Amount) (*big.Int, error) {
+	return a.Native(WadDecimals)
+}
+
+// ToRay converts a decimal amount to a ray, failing if it has more than 27 decimals
+func ToRay(a money.Amount) (*big.Int, error) {
+	return a.Native(RayDecimals)
+}
+
+// ToRad converts a decimal amount to a rad, failing if it has more than 45 decimals
+func ToRad(a money.Amount) (*big.Int, error) {
+	return a.Native(RadDecimals)
+}
+
+// FromWad converts a wad to a decimal amount
+func FromWad(x *big.Int) money.Amount {
+	return money.New(x, WadDecimals)
+}
+
+// FromRay converts a ray to a decimal amount
+func FromRay(x *big.Int) money.Amount {
+	return money.New(x, RayDecimals)
+}
+
+// FromRad converts a rad to a decimal amount
+func FromRad(x *big.Int) money.Amount {
+	return money.New(x, RadDecimals)
+}
+
+// ParseInt parses a fixed-point integer such as a wad or ray returned by the API
+func ParseInt(s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid fixed-point number: %q", s)
+	}
+	return x, nil
+}
+
+// mul returns x*y/one rounded half away from zero
+func mul(x, y, one *big.Int) *big.Int {
+	return round(new(big.Int).Mul(x, y), one)
+}
+
+// div returns x*one/y rounded half away from zero
+func div(x, y, one *big.Int) *big.Int {
+	return round(new(big.Int).Mul(x, one), y)
+}
+
+// round returns x/y rounded half away from zero. Rounding the absolute value keeps negative
+// results symmetric with positive ones, which big.Int.Quo alone would truncate toward zero.
+func round(x, y *big.Int) *big.Int {
+	d := new(big.Int).Abs(y)
+	z := new(big.Int).Abs(x)
+	z.Add(z, new(big.Int).Rsh(d, 1))
+	z.Quo(z, d)
+	if x.Sign()*y.Sign() < 0 {
+		z.Neg(z)
+	}
+	return z
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
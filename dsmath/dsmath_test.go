@@ -0,0 +1,190 @@
+package dsmath
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zarbanio/zarban-go/money"
+)
+
+// num parses a decimal integer
+func num(t *testing.T, s string) *big.Int {
+	t.Helper()
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid number %q", s)
+	}
+	return x
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(x, y *big.Int) *big.Int
+		x, y string
+		want string
+	}{
+		{name: "wmul", fn: Wmul, x: "1500000000000000000", y: "2000000000000000000", want: "3000000000000000000"},
+		{name: "wmul half", fn: Wmul, x: "500000000000000000", y: "500000000000000000", want: "250000000000000000"},
+		{name: "wmul rounds half up", fn: Wmul, x: "1", y: "500000000000000000", want: "1"},
+		{name: "wmul rounds down", fn: Wmul, x: "1", y: "499999999999999999", want: "0"},
+		{name: "wmul negative", fn: Wmul, x: "-1", y: "500000000000000000", want: "-1"},
+		{name: "wmul negative rounds down", fn: Wmul, x: "-1", y: "499999999999999999", want: "0"},
+		{name: "wmul two negatives", fn: Wmul, x: "-1500000000000000000", y: "-2000000000000000000", want: "3000000000000000000"},
+		{name: "wdiv", fn: Wdiv, x: "1000000000000000000", y: "3000000000000000000", want: "333333333333333333"},
+		{name: "wdiv rounds half up", fn: Wdiv, x: "2000000000000000000", y: "3000000000000000000", want: "666666666666666667"},
+		{name: "wdiv negative", fn: Wdiv, x: "-2000000000000000000", y: "3000000000000000000", want: "-666666666666666667"},
+		{name: "wdiv negative divisor", fn: Wdiv, x: "2000000000000000000", y: "-3000000000000000000", want: "-666666666666666667"},
+		{name: "rmul", fn: Rmul, x: "5", y: "1000000000000000000000000000", want: "5"},
+		{name: "rmul negative", fn: Rmul, x: "-5", y: "1000000000000000000000000000", want: "-5"},
+		{name: "rmul wad by rate", fn: Rmul, x: "100000000000000000000", y: "1050000000000000000000000000", want: "105000000000000000000"},
+		{name: "rmul negative wad by rate", fn: Rmul, x: "-3", y: "1500000000000000000000000000", want: "-5"},
+		{name: "rdiv", fn: Rdiv, x: "1000000000000000000000000000", y: "3000000000000000000000000000", want: "333333333333333333333333333"},
+		{name: "rdiv wad by rate", fn: Rdiv, x: "105000000000000000000", y: "1050000000000000000000000000", want: "100000000000000000000"},
+		{name: "rdiv negative", fn: Rdiv, x: "-2000000000000000000000000000", y: "3000000000000000000000000000", want: "-666666666666666666666666667"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := num(t, tt.x), num(t, tt.y)
+			got := tt.fn(x, y)
+			if got.Cmp(num(t, tt.want)) != 0 {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+			if x.Cmp(num(t, tt.x)) != 0 || y.Cmp(num(t, tt.y)) != 0 {
+				t.Fatal("operands were modified")
+			}
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(x *big.Int) *big.Int
+		x    string
+		want string
+	}{
+		{name: "wad to ray", fn: WadToRay, x: "1", want: "1000000000"},
+		{name: "wad to rad", fn: WadToRad, x: "-1", want: "-1000000000000000000000000000"},
+		{name: "ray to wad", fn: RayToWad, x: "1500000000", want: "2"},
+		{name: "ray to wad rounds down", fn: RayToWad, x: "1499999999", want: "1"},
+		{name: "negative ray to wad", fn: RayToWad, x: "-600000000", want: "-1"},
+		{name: "negative ray to wad rounds to zero", fn: RayToWad, x: "-400000000", want: "0"},
+		{name: "negative ray to wad rounds half away", fn: RayToWad, x: "-1500000000", want: "-2"},
+		{name: "rad to wad", fn: RadToWad, x: "2500000000000000000000000000", want: "3"},
+		{name: "negative rad to wad", fn: RadToWad, x: "-2500000000000000000000000000", want: "-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(num(t, tt.x)); got.Cmp(num(t, tt.want)) != 0 {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRpow(t *testing.T) {
+	tests := []struct {
+		name string
+		x    string
+		n    uint64
+		want string
+	}{
+		{name: "zero power", x: "1000000000000000000000000000", n: 0, want: "1000000000000000000000000000"},
+		{name: "zero to zero", x: "0", n: 0, want: "1000000000000000000000000000"},
+		{name: "zero", x: "0", n: 3, want: "0"},
+		{name: "one", x: "1000000000000000000000000000", n: 1, want: "1000000000000000000000000000"},
+		{name: "powers of two", x: "2000000000000000000000000000", n: 10, want: "1024000000000000000000000000000"},
+		{name: "half", x: "500000000000000000000000000", n: 3, want: "125000000000000000000000000"},
+		{name: "negative odd power", x: "-2000000000000000000000000000", n: 3, want: "-8000000000000000000000000000"},
+		{name: "negative even power", x: "-2000000000000000000000000000", n: 4, want: "16000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rpow(num(t, tt.x), tt.n); got.Cmp(num(t, tt.want)) != 0 {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestRpowJug checks the rate compounded by Jug.drip in the MakerDAO jug tests, where a duty of
+// 5% a day over one day gives a rate of 1.05 truncated to a wad
+func TestRpowJug(t *testing.T) {
+	tests := []struct {
+		name    string
+		duty    string
+		seconds uint64
+		want    string
+	}{
+		{name: "5% a day", duty: "1000000564701133626865910626", seconds: 86400, want: "1050000000000000000"},
+		{name: "5% a day twice", duty: "1000000564701133626865910626", seconds: 2 * 86400, want: "1102500000000000000"},
+		{name: "no fee", duty: "1000000000000000000000000000", seconds: 365 * 86400, want: "1000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := Rpow(num(t, tt.duty), tt.seconds)
+			got := new(big.Int).Quo(rate, big.NewInt(1e9))
+			if got.Cmp(num(t, tt.want)) != 0 {
+				t.Fatalf("expected %s, got %s (rate %s)", tt.want, got, rate)
+			}
+		})
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	Wdiv(Wad(), new(big.Int))
+}
+
+func TestFixedPointAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		to      func(money.Amount) (*big.Int, error)
+		from    func(*big.Int) money.Amount
+		want    string
+		wantErr bool
+	}{
+		{name: "wad", amount: "1.5", to: ToWad, from: FromWad, want: "1500000000000000000"},
+		{name: "ray", amount: "-0.25", to: ToRay, from: FromRay, want: "-250000000000000000000000000"},
+		{name: "rad", amount: "2", to: ToRad, from: FromRad, want: "2000000000000000000000000000000000000000000000"},
+		{name: "too precise for a wad", amount: "0.0000000000000000001", to: ToWad, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := money.Parse(tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.to(a)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Cmp(num(t, tt.want)) != 0 {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+			if back := tt.from(got); back.Cmp(a) != 0 {
+				t.Fatalf("expected %s back, got %s", a, back)
+			}
+		})
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	if x, err := ParseInt(" 1000000000000000000000000000 "); err != nil || x.Cmp(Ray()) != 0 {
+		t.Fatalf("expected a ray, got %v, %v", x, err)
+	}
+	for _, s := range []string{"", "1.5", "1e18", "0x10"} {
+		if _, err := ParseInt(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
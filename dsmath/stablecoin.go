@@ -0,0 +1,87 @@
+package dsmath
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/money"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// SecondsPerYear is the number of seconds a per second rate is compounded over for a year
+const SecondsPerYear = 365 * 24 * 60 * 60
+
+// MaxAcceptablePrice returns the maxAcceptablePrice field of a take request, a ray, for a
+// price in ZAR per unit of collateral
+func MaxAcceptablePrice(price money.Amount) (string, error) {
+	x, err := ToRay(price)
+	if err != nil {
+		return "", fmt.Errorf("invalid max acceptable price: %w", err)
+	}
+	return x.String(), nil
+}
+
+// CollateralAmountUpperLimit returns the collateralAmountUpperLimit field of a take request, a
+// wad, for an amount of collateral
+func CollateralAmountUpperLimit(amount money.Amount) (string, error) {
+	x, err := ToWad(amount)
+	if err != nil {
+		return "", fmt.Errorf("invalid collateral amount upper limit: %w", err)
+	}
+	return x.String(), nil
+}
+
+// TakeRequest builds a request to buy at most amount collateral from an auction of ilk, paying
+// at most maxPrice ZAR per unit of collateral
+func TakeRequest(user, ilk string, auctionID int, amount, maxPrice money.Amount) (service.StablecoinSystemTakeTxRequest, error) {
+	limit, err := CollateralAmountUpperLimit(amount)
+	if err != nil {
+		return service.StablecoinSystemTakeTxRequest{}, err
+	}
+	price, err := MaxAcceptablePrice(maxPrice)
+	if err != nil {
+		return service.StablecoinSystemTakeTxRequest{}, err
+	}
+	return service.StablecoinSystemTakeTxRequest{
+		AuctionId:                  auctionID,
+		CollateralAmountUpperLimit: limit,
+		Ilk:                        ilk,
+		MaxAcceptablePrice:         price,
+		User:                       user,
+	}, nil
+}
+
+// Owe returns the ZAR owed, as a rad, for a slice of collateral as a wad bought at a price
+// as a ray, like the Clipper computes it when an auction is taken
+func Owe(slice, price *big.Int) *big.Int {
+	return MulWadRay(slice, price)
+}
+
+// ParseDuty parses Ilk.Duty as a per second rate ray. The duty is accepted either as a decimal
+// like "1.000000000627937192491029810" or as a raw ray integer like "1000000000627937192491029810".
+func ParseDuty(duty string) (*big.Int, error) {
+	text := strings.TrimSpace(duty)
+	if !strings.Contains(text, ".") && len(strings.TrimLeft(text, "+-")) > RayDecimals {
+		return ParseInt(text)
+	}
+	rate, err := money.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duty: %w", err)
+	}
+	x, err := ToRay(rate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duty: %w", err)
+	}
+	return x, nil
+}
+
+// Accrue compounds a per second rate ray over the given number of seconds
+func Accrue(duty *big.Int, seconds uint64) *big.Int {
+	return Rpow(duty, seconds)
+}
+
+// AnnualRate compounds a per second rate ray over a year, e.g. 1.02 for a 2% stability fee
+func AnnualRate(duty *big.Int) money.Amount {
+	return FromRay(Accrue(duty, SecondsPerYear))
+}
@@ -14,6 +14,7 @@ Key components and functionality:
 
 2. Helper Functions:
 - get_ilks_symbol: Retrieves available collateral types (ilks) from the API
+- get_collateral_token: Looks up the collateral token of an ilk and its decimals
 - to_native: Converts human-readable amounts to blockchain-native amounts
 - get_vault_tx_steps: Obtains transaction steps for vault creation
 
@@ -42,7 +43,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"os"
 	"strconv"
@@ -54,6 +54,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zarbanio/zarban-go/money"
+	"github.com/zarbanio/zarban-go/policy"
 	"github.com/zarbanio/zarban-go/service"
 )
 
@@ -88,58 +90,65 @@ func getIlksSymbol(client service.Client) ([]service.Symbol, error) {
 	return symbols, nil
 }
 
-func toNative(client service.Client, symbol service.Symbol, amount float64) (string, error) {
+func toNative(client service.Client, token service.Token, amount string) (string, error) {
 	// Get symbols
 	symbols, err := getIlksSymbol(client)
 	if err != nil {
 		return "", fmt.Errorf("failed to get symbols: %w", err)
 	}
 
-	// Define precision map
-	precision := map[service.Symbol]int{
-		"ZAR": 18,
-	}
+	// Validate symbol
+	known := token.Symbol == "ZAR"
 	for _, s := range symbols {
-		precision[s] = 18
+		if s == token.Symbol {
+			known = true
+		}
 	}
-
-	// Validate symbol
-	p, ok := precision[symbol]
-	if !ok {
-		return "", fmt.Errorf("unknown symbol: %s", symbol)
+	if !known {
+		return "", fmt.Errorf("unknown symbol: %s", token.Symbol)
 	}
 
-	// Convert amount to native units using big.Int
-	scaledAmount := new(big.Int)
-	floatAmount := new(big.Float).SetFloat64(amount)
-	scale := new(big.Float).SetFloat64(math.Pow10(p))
-
-	// Multiply amount by scale factor
-	floatAmount.Mul(floatAmount, scale)
+	// Parse the decimal amount exactly and convert it with the decimals of the token
+	value, err := money.Parse(amount)
+	if err != nil {
+		return "", err
+	}
+	return value.NativeString(int(token.Decimals))
+}
 
-	// Convert to integer, checking for accuracy
-	if _, accuracy := floatAmount.Int(scaledAmount); accuracy != big.Exact {
-		return "", fmt.Errorf("lost precision during conversion")
+func getCollateralToken(client service.Client, ethClient *ethclient.Client, ilkName string) (service.Token, error) {
+	httpResponse, err := client.GetIlkByName(context.Background(), ilkName)
+	if err != nil {
+		return service.Token{}, fmt.Errorf("failed to get ilk %s: %w", ilkName, err)
+	}
+	var ilk service.Ilk
+	if err := service.HandleAPIResponse(context.Background(), httpResponse, &ilk); err != nil {
+		return service.Token{}, fmt.Errorf("failed to get ilk %s: %w", ilkName, err)
 	}
 
-	return scaledAmount.String(), nil
+	// The collateral token is the gem of the ilk, its decimals come from the token contract
+	decimals, err := policy.GemDecimals(ethClient)(context.Background(), ilk)
+	if err != nil {
+		return service.Token{}, err
+	}
+	return service.Token{Address: ilk.Gem, Symbol: ilk.Symbol, Decimals: int64(decimals)}, nil
 }
 
 func getVaultTxSteps(
 	client service.Client,
 	ilkName string,
-	symbol service.Symbol,
+	collateral service.Token,
 	walletAddress string,
-	collateralAmount float64,
-	loanAmount float64,
+	collateralAmount string,
+	loanAmount string,
 ) (service.ChainActivity, error) {
-	nativeCollateralAmount, err := toNative(client, symbol, collateralAmount)
+	nativeCollateralAmount, err := toNative(client, collateral, collateralAmount)
 	if err != nil {
 		log.Fatalf("Error converting collateral amount: %v", err)
 		return service.ChainActivity{}, err
 	}
 
-	nativeLoanAmount, err := toNative(client, "ZAR", loanAmount)
+	nativeLoanAmount, err := toNative(client, service.Token{Symbol: "ZAR", Decimals: 18}, loanAmount)
 	if err != nil {
 		log.Fatalf("Error converting loan amount: %v", err)
 		return service.ChainActivity{}, err
@@ -353,15 +362,21 @@ func CreateVaultExample() {
 	}
 
 	// Define vault creation parameters
-	const ILK_NAME = "ETHA"        // Replace with your desired ilk
-	const SYMBOL = "ETH"           // Replace with the symbol associated with your ilk
-	const COLLATERAL_AMOUNT = .001 // Replace with your desired amount
-	const LOAN_AMOUNT = 100        // Replace with your desired amount
+	const ILK_NAME = "ETHA"           // Replace with your desired ilk
+	const COLLATERAL_AMOUNT = "0.001" // Replace with your desired amount
+	const LOAN_AMOUNT = "100"         // Replace with your desired amount
+
+	// Look the collateral token of the ilk up, with its decimals read on chain
+	collateral, err := getCollateralToken(*client, ethClient, ILK_NAME)
+	if err != nil {
+		log.Fatalf("Failed to get collateral token: %v", err)
+		return
+	}
 
 	vaultSteps, err := getVaultTxSteps(
 		*client,
 		ILK_NAME,
-		SYMBOL,
+		collateral,
 		WALLET_ADDRESS,
 		COLLATERAL_AMOUNT,
 		LOAN_AMOUNT)
@@ -379,7 +394,7 @@ func CreateVaultExample() {
 			vaultSteps, err := getVaultTxSteps(
 				*client,
 				ILK_NAME,
-				SYMBOL,
+				collateral,
 				WALLET_ADDRESS,
 				COLLATERAL_AMOUNT,
 				LOAN_AMOUNT)
@@ -12,7 +12,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"os"
 	"time"
@@ -23,36 +22,28 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zarbanio/zarban-go/money"
 	"github.com/zarbanio/zarban-go/service"
 )
 
-func toNative(amount float64) (*string, error) {
-	// Convert amount to native units using big.Int
-	scaledAmount := new(big.Int)
-	floatAmount := new(big.Float).SetFloat64(amount)
-	scale := new(big.Float).SetFloat64(math.Pow10(18))
-
-	// Multiply amount by scale factor
-	floatAmount.Mul(floatAmount, scale)
-
-	// Convert to integer, checking for accuracy
-	if _, accuracy := floatAmount.Int(scaledAmount); accuracy != big.Exact {
-		return nil, fmt.Errorf("lost precision during conversion")
+func toNative(amount string) (*string, error) {
+	// Parse the decimal amount exactly and convert it to ZAR native units
+	value, err := money.Parse(amount)
+	if err != nil {
+		return nil, err
 	}
-
-	str := scaledAmount.String()
-	return &str, nil
+	return value.ToNative(service.Token{Symbol: "ZAR", Decimals: 18})
 }
 
 func getVaultTxSteps(
 	client service.Client,
 	walletAddress string,
 	vaultId int,
-	amount float64,
+	amount string,
 ) (service.ChainActivity, error) {
 	var nativeAmount *string
 	var err error
-	if amount > 0 {
+	if amount != "" {
 		nativeAmount, err = toNative(amount)
 		if err != nil {
 			log.Fatalf("Error converting collateral amount: %v", err)
@@ -203,8 +194,8 @@ func VaultDebtRepayExample() {
 		return
 	}
 
-	const VAULT_ID int = 0   // Update with the actual vault ID
-	const AMOUNT float64 = 0 // Update with the amount to repay
+	const VAULT_ID int = 0 // Update with the actual vault ID
+	const AMOUNT = ""      // Update with the amount to repay, e.g. "100.5", or leave empty to repay all
 
 	// Create and configure the client
 	client, err := service.NewClient("https://testapi.zarban.io")
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// TxBuilder turns service.PreparedTx steps into unsigned transactions
+type TxBuilder struct {
+	backend   FeeBackend
+	strategy  FeeStrategy
+	gasBuffer int64
+}
+
+// BuilderOption allows setting custom parameters on a TxBuilder
+type BuilderOption func(*TxBuilder) error
+
+// NewTxBuilder creates a TxBuilder, using DefaultFeeStrategy unless another strategy is given
+func NewTxBuilder(backend FeeBackend, opts ...BuilderOption) (*TxBuilder, error) {
+	b := TxBuilder{
+		backend:  backend,
+		strategy: DefaultFeeStrategy(),
+	}
+	for _, o := range opts {
+		if err := o(&b); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// WithFeeStrategy sets the strategy used to price transactions
+func WithFeeStrategy(strategy FeeStrategy) BuilderOption {
+	return func(b *TxBuilder) error {
+		b.strategy = strategy
+		return nil
+	}
+}
+
+// WithGasBuffer adds percent to the gas use estimate of the API when setting the gas limit
+func WithGasBuffer(percent int64) BuilderOption {
+	return func(b *TxBuilder) error {
+		if percent < 0 {
+			return fmt.Errorf("invalid gas buffer: %d", percent)
+		}
+		b.gasBuffer = percent
+		return nil
+	}
+}
+
+// Build prices a prepared transaction with the fee strategy and returns it unsigned
+func (b *TxBuilder) Build(ctx context.Context, preparedTx service.PreparedTx, chainID *big.Int, nonce uint64) (*types.Transaction, error) {
+	fees, err := b.strategy.Fees(ctx, b.backend)
+	if err != nil {
+		return nil, err
+	}
+	return b.BuildWithFees(preparedTx, chainID, nonce, fees)
+}
+
+// BuildWithFees returns the prepared transaction as an unsigned transaction with the given fees.
+// A dynamic fee transaction is built when fees has a fee cap, a legacy transaction otherwise.
+func (b *TxBuilder) BuildWithFees(preparedTx service.PreparedTx, chainID *big.Int, nonce uint64, fees Fees) (*types.Transaction, error) {
+	to, value, data, err := decodeMethodParameters(preparedTx.MethodParameters)
+	if err != nil {
+		return nil, err
+	}
+	if preparedTx.GasUseEstimate <= 0 {
+		return nil, fmt.Errorf("invalid gas estimate: %d", preparedTx.GasUseEstimate)
+	}
+	gas := uint64(preparedTx.GasUseEstimate)
+	gas += gas * uint64(b.gasBuffer) / 100
+
+	if fees.IsDynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       gas,
+			To:        &to,
+			Value:     value,
+			Data:      data,
+		}), nil
+	}
+	if fees.GasPrice == nil {
+		return nil, fmt.Errorf("fees have neither a gas price nor a fee cap")
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.GasPrice,
+		Gas:      gas,
+		To:       &to,
+		Value:    value,
+		Data:     data,
+	}), nil
+}
+
+// decodeMethodParameters parses the recipient, value and calldata of a prepared transaction
+func decodeMethodParameters(params service.MethodParameters) (common.Address, *big.Int, []byte, error) {
+	if !common.IsHexAddress(params.To) {
+		return common.Address{}, nil, nil, fmt.Errorf("invalid recipient address: %q", params.To)
+	}
+	value := new(big.Int)
+	if params.Value != "" {
+		if _, ok := value.SetString(params.Value, 10); !ok {
+			return common.Address{}, nil, nil, fmt.Errorf("invalid value: %q", params.Value)
+		}
+	}
+	if value.Sign() < 0 {
+		return common.Address{}, nil, nil, fmt.Errorf("negative value: %q", params.Value)
+	}
+	var data []byte
+	if params.Calldata != "" {
+		decoded, err := hexutil.Decode(params.Calldata)
+		if err != nil {
+			return common.Address{}, nil, nil, fmt.Errorf("failed to decode calldata: %w", err)
+		}
+		data = decoded
+	}
+	return common.HexToAddress(params.To), value, data, nil
+}
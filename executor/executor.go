@@ -0,0 +1,506 @@
+// Package executor drives multi-step service.ChainActivity flows to completion
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/journal"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+// Backend is the subset of the Ethereum client used to send transactions.
+//
+// The go-ethereum *ethclient.Client implements this interface.
+type Backend interface {
+	FeeBackend
+	NonceBackend
+	CallBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// TxValidator checks a prepared transaction before it is signed, e.g. a *guard.Guard
+type TxValidator interface {
+	Check(ctx context.Context, preparedTx service.PreparedTx) error
+}
+
+// TxReserver is a TxValidator taking a budget, such as a daily spending limit, for the
+// transactions it checks, e.g. a *policy.TxValidator. The executor calls Reserve instead of
+// Check, and calls the returned release function if the transaction is not broadcast because
+// simulating, building, signing or sending it failed. Release may be nil.
+type TxReserver interface {
+	TxValidator
+	Reserve(ctx context.Context, preparedTx service.PreparedTx) (release func(), err error)
+}
+
+// StepProvider returns the current state of a chain activity, typically by calling
+// one of the service *Transaction or Create* endpoints. It is called before every
+// step with the result accumulated so far, so signatures produced by earlier steps
+// can be passed back to the API.
+type StepProvider func(ctx context.Context, result *Result) (service.ChainActivity, error)
+
+// Executor runs every step of a chain activity using a pluggable signer
+type Executor struct {
+	backend  Backend
+	signer   signer.Signer
+	verifier *signer.Verifier
+	builder  *TxBuilder
+	nonces   *NonceManager
+	replace  *ReplacementPolicy
+	journal  journal.Store
+
+	// chainMu guards chainID, which is looked up on first use unless set with WithChainID
+	chainMu sync.Mutex
+	chainID *big.Int
+
+	validators []TxValidator
+
+	simulate  bool
+	errorABIs []abi.ABI
+
+	receiptTimeout  time.Duration
+	progressTimeout time.Duration
+	pollInterval    time.Duration
+}
+
+// Option allows setting custom parameters during construction
+type Option func(*Executor) error
+
+// New creates a new Executor, with reasonable defaults
+func New(backend Backend, s signer.Signer, opts ...Option) (*Executor, error) {
+	if backend == nil {
+		return nil, errors.New("backend is required")
+	}
+	if s == nil {
+		return nil, errors.New("signer is required")
+	}
+	e := Executor{
+		backend:         backend,
+		signer:          s,
+		simulate:        true,
+		receiptTimeout:  2 * time.Minute,
+		progressTimeout: time.Minute,
+		pollInterval:    5 * time.Second,
+	}
+	for _, o := range opts {
+		if err := o(&e); err != nil {
+			return nil, err
+		}
+	}
+	if e.builder == nil {
+		builder, err := NewTxBuilder(backend)
+		if err != nil {
+			return nil, err
+		}
+		e.builder = builder
+	}
+	if e.nonces == nil {
+		e.nonces = NewNonceManager(backend)
+	}
+	e.errorABIs = append(e.errorABIs, calldata.ABIs()...)
+	return &e, nil
+}
+
+// WithChainID sets the chain ID used for signing, instead of querying it from the backend
+func WithChainID(chainID *big.Int) Option {
+	return func(e *Executor) error {
+		if chainID == nil || chainID.Sign() <= 0 {
+			return fmt.Errorf("invalid chain id: %v", chainID)
+		}
+		e.chainID = new(big.Int).Set(chainID)
+		return nil
+	}
+}
+
+// WithTxBuilder sets the builder used to turn PreparedTx steps into transactions
+func WithTxBuilder(builder *TxBuilder) Option {
+	return func(e *Executor) error {
+		e.builder = builder
+		return nil
+	}
+}
+
+// WithNonceManager sets the nonce manager, share one between executors sending from the same account
+func WithNonceManager(nonces *NonceManager) Option {
+	return func(e *Executor) error {
+		e.nonces = nonces
+		return nil
+	}
+}
+
+// WithReplacementPolicy speeds up or cancels transactions that stay pending for too long
+func WithReplacementPolicy(policy ReplacementPolicy) Option {
+	return func(e *Executor) error {
+		if policy.StuckAfter <= 0 {
+			return fmt.Errorf("invalid stuck after duration: %v", policy.StuckAfter)
+		}
+		if policy.BumpPercent == 0 {
+			policy.BumpPercent = 10
+		}
+		if policy.BumpPercent < 10 {
+			return fmt.Errorf("bump percent must be at least 10, got %d", policy.BumpPercent)
+		}
+		e.replace = &policy
+		return nil
+	}
+}
+
+// WithTxValidator checks every PreparedTx with v before it is signed. Validators run in the
+// order they are added and the first error aborts the step. Validators implementing TxReserver
+// keep their budget only for broadcast transactions.
+func WithTxValidator(v TxValidator) Option {
+	return func(e *Executor) error {
+		e.validators = append(e.validators, v)
+		return nil
+	}
+}
+
+// WithJournal records the progress of activities run with Resume in store
+func WithJournal(store journal.Store) Option {
+	return func(e *Executor) error {
+		e.journal = store
+		return nil
+	}
+}
+
+// WithSimulation enables or disables simulating PreparedTx steps with eth_call before they
+// are sent, enabled by default
+func WithSimulation(enabled bool) Option {
+	return func(e *Executor) error {
+		e.simulate = enabled
+		return nil
+	}
+}
+
+// WithErrorABIs decodes custom errors of failed simulations using the given contract ABIs,
+// which are looked up before the embedded protocol ABIs of the calldata package
+func WithErrorABIs(abis ...abi.ABI) Option {
+	return func(e *Executor) error {
+		e.errorABIs = append(e.errorABIs, abis...)
+		return nil
+	}
+}
+
+// WithVerifier checks EIP-712 sign requests against the protocol contracts before signing them.
+// Without a verifier only the request hash is checked against its typed data.
+func WithVerifier(v *signer.Verifier) Option {
+	return func(e *Executor) error {
+		e.verifier = v
+		return nil
+	}
+}
+
+// WithReceiptTimeout sets how long to wait for a transaction to be mined
+func WithReceiptTimeout(d time.Duration) Option {
+	return func(e *Executor) error {
+		e.receiptTimeout = d
+		return nil
+	}
+}
+
+// WithProgressTimeout sets how long to wait for the API to report the next step
+// after a step has been executed
+func WithProgressTimeout(d time.Duration) Option {
+	return func(e *Executor) error {
+		e.progressTimeout = d
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval used when polling for receipts and step progress
+func WithPollInterval(d time.Duration) Option {
+	return func(e *Executor) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid poll interval: %v", d)
+		}
+		e.pollInterval = d
+		return nil
+	}
+}
+
+// Run executes the chain activity returned by provider until its last step is done
+func (e *Executor) Run(ctx context.Context, provider StepProvider) (*Result, error) {
+	return e.run(ctx, "", provider)
+}
+
+// run executes a chain activity, journaling its steps under activityID if set
+func (e *Executor) run(ctx context.Context, activityID string, provider StepProvider) (*Result, error) {
+	result := &Result{}
+	if err := e.resolveChainID(ctx); err != nil {
+		return result, err
+	}
+
+	last := 0
+	if activityID != "" {
+		recovered, err := e.recover(ctx, activityID)
+		if err != nil {
+			return recovered, err
+		}
+		result = recovered
+		if step := result.Last(); step != nil {
+			last = step.Number
+		}
+	}
+	for {
+		activity, err := e.next(ctx, provider, result, last)
+		if err != nil {
+			return result, err
+		}
+		if len(activity.Steps) == 0 {
+			return result, nil
+		}
+
+		number := activity.StepNumber
+		step := activity.Steps[number-1]
+		j := e.newStepJournal(activityID, number, step)
+		stepResult, err := e.execute(ctx, number, step, j)
+		if doneErr := j.done(ctx, stepResult, err); err == nil {
+			err = doneErr
+		}
+		if err != nil {
+			return result, &StepError{Number: number, Type: step.Type, Err: err}
+		}
+		result.Steps = append(result.Steps, *stepResult)
+		last = number
+
+		if number >= activity.NumberOfSteps {
+			return result, nil
+		}
+	}
+}
+
+// resolveChainID looks the chain id up from the backend unless it is known already. Every run
+// calls it before reading e.chainID, so concurrent runs see the same chain id.
+func (e *Executor) resolveChainID(ctx context.Context) error {
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+	if e.chainID != nil {
+		return nil
+	}
+	chainID, err := e.backend.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	e.chainID = chainID
+	return nil
+}
+
+// next fetches the chain activity until it reports a step after last
+func (e *Executor) next(ctx context.Context, provider StepProvider, result *Result, last int) (service.ChainActivity, error) {
+	deadline := time.Now().Add(e.progressTimeout)
+	for {
+		activity, err := provider(ctx, result)
+		if err != nil {
+			return service.ChainActivity{}, fmt.Errorf("failed to get chain activity: %w", err)
+		}
+		if len(activity.Steps) == 0 {
+			return activity, nil
+		}
+		if activity.StepNumber < 1 || activity.StepNumber > len(activity.Steps) {
+			return service.ChainActivity{}, fmt.Errorf("invalid step number %d of %d steps", activity.StepNumber, len(activity.Steps))
+		}
+		if activity.StepNumber > last {
+			return activity, nil
+		}
+		if last >= activity.NumberOfSteps {
+			// every step was executed, e.g. by the run a resumed activity was journaled by
+			return service.ChainActivity{}, nil
+		}
+		if time.Now().After(deadline) {
+			return service.ChainActivity{}, &StepError{Number: activity.StepNumber, Type: activity.Steps[activity.StepNumber-1].Type, Err: ErrNoProgress}
+		}
+		if err := sleep(ctx, e.pollInterval); err != nil {
+			return service.ChainActivity{}, err
+		}
+	}
+}
+
+// execute dispatches a single step on its type
+func (e *Executor) execute(ctx context.Context, number int, step service.ChainActivityStep, j *stepJournal) (*StepResult, error) {
+	stepResult := &StepResult{Number: number, Type: step.Type}
+	if err := j.save(ctx, func(*journal.Entry) {}); err != nil {
+		return nil, err
+	}
+
+	switch step.Type {
+	case service.ChainActivityStepTypePreparedTx:
+		preparedTx, err := step.Data.AsPreparedTx()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode prepared tx: %w", err)
+		}
+		stepResult.Label = preparedTx.Label
+		receipt, replaced, err := e.sendPreparedTx(ctx, preparedTx, j)
+		if err != nil {
+			return nil, err
+		}
+		stepResult.TxHash = &receipt.TxHash
+		stepResult.Receipt = receipt
+		stepResult.Replaced = replaced
+
+	case service.ChainActivityStepTypeEIP712SignRequest:
+		request, err := step.Data.AsEIP712SignRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode eip712 sign request: %w", err)
+		}
+		if e.verifier != nil {
+			err = e.verifier.VerifyEIP712SignRequest(ctx, request)
+		} else {
+			err = signer.VerifyTypedDataHash(request.TypedData, request.Hash)
+		}
+		if err != nil {
+			return nil, err
+		}
+		signature, err := e.signer.SignTypedData(ctx, request.TypedData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign typed data: %w", err)
+		}
+		stepResult.Signature = signature
+
+	case service.ChainActivityStepTypePersonalSignRequest:
+		request, err := step.Data.AsPersonalSignRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode personal sign request: %w", err)
+		}
+		signature, err := e.signer.SignPersonal(ctx, signer.PersonalMessage(request.Message))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign personal message: %w", err)
+		}
+		stepResult.Signature = signature
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedStep, step.Type)
+	}
+
+	return stepResult, nil
+}
+
+// sendPreparedTx signs and sends a prepared transaction and waits for it, or one of its
+// replacements, to be mined. The hashes of replaced transactions are returned as well.
+func (e *Executor) sendPreparedTx(ctx context.Context, preparedTx service.PreparedTx, j *stepJournal) (*types.Receipt, []common.Hash, error) {
+	release, err := e.validate(ctx, preparedTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	account := e.signer.Address()
+	if e.simulate {
+		if err := Simulate(ctx, e.backend, account, preparedTx, e.errorABIs...); err != nil {
+			release()
+			return nil, nil, err
+		}
+	}
+	nonce, err := e.nonces.Next(ctx, account)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	tx, err := e.builder.Build(ctx, preparedTx, e.chainID, nonce)
+	if err != nil {
+		e.nonces.Release(account, nonce)
+		release()
+		return nil, nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	signedTx, err := e.signAndSend(ctx, tx, j)
+	if err != nil {
+		if isNonceTooLow(err) {
+			// another process used the nonce, catch up with the chain state
+			_ = e.nonces.Resync(ctx, account)
+		} else {
+			e.nonces.Release(account, nonce)
+		}
+		release()
+		return nil, nil, err
+	}
+	e.nonces.Broadcast(account, nonce)
+
+	receipt, replaced, err := e.waitMined(ctx, preparedTx, []pendingTx{{tx: signedTx}}, j)
+	if err != nil {
+		if errors.Is(err, ErrReceiptTimeout) {
+			// the transaction may have been dropped, hand its nonce out again if so
+			_ = e.nonces.Resync(ctx, account)
+		}
+		return nil, replaced, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, replaced, fmt.Errorf("%w: %s", ErrTxFailed, receipt.TxHash.Hex())
+	}
+	return receipt, replaced, nil
+}
+
+// validate runs the validators on a prepared transaction. It returns a function releasing the
+// budgets taken by TxReservers, to be called if the transaction is not broadcast.
+func (e *Executor) validate(ctx context.Context, preparedTx service.PreparedTx) (func(), error) {
+	var releases []func()
+	release := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, v := range e.validators {
+		reserver, ok := v.(TxReserver)
+		if !ok {
+			if err := v.Check(ctx, preparedTx); err != nil {
+				release()
+				return nil, err
+			}
+			continue
+		}
+		r, err := reserver.Reserve(ctx, preparedTx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if r != nil {
+			releases = append(releases, r)
+		}
+	}
+	return release, nil
+}
+
+// signAndSend signs a transaction, journals it and broadcasts it
+func (e *Executor) signAndSend(ctx context.Context, tx *types.Transaction, j *stepJournal) (*types.Transaction, error) {
+	signedTx, err := e.signer.SignTx(ctx, tx, e.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := j.sent(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	if err := e.backend.SendTransaction(ctx, signedTx); err != nil && !isAlreadyKnown(err) {
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// isNonceTooLow reports whether the node rejected a transaction because its nonce was already used
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
+
+// isAlreadyKnown reports whether the node already has the transaction in its pool
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known")
+}
+
+// sleep waits for d or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,345 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/signer"
+)
+
+// testBackend is an in-memory chain that mines every sent transaction successfully
+type testBackend struct {
+	mu      sync.Mutex
+	pending map[common.Address]uint64
+	sent    []*types.Transaction
+	callErr error
+	sendErr error
+}
+
+func newTestBackend() *testBackend {
+	return &testBackend{pending: make(map[common.Address]uint64)}
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(10)}, nil
+}
+
+func (b *testBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(20), nil
+}
+
+func (b *testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(2), nil
+}
+
+func (b *testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(10), big.NewInt(12)}, Reward: [][]*big.Int{{big.NewInt(3)}}}, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending[account], nil
+}
+
+func (b *testBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, b.callErr
+}
+
+func (b *testBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, tx := range b.sent {
+		if tx.Hash() == txHash {
+			return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}}, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+// rejectValidator rejects every prepared transaction
+type rejectValidator struct{}
+
+func (rejectValidator) Check(ctx context.Context, preparedTx service.PreparedTx) error {
+	return errors.New("rejected")
+}
+
+// budgetReserver is a TxReserver with a budget of transactions
+type budgetReserver struct {
+	mu       sync.Mutex
+	budget   int
+	reserved int
+}
+
+func (r *budgetReserver) Check(ctx context.Context, preparedTx service.PreparedTx) error {
+	_, err := r.Reserve(ctx, preparedTx)
+	return err
+}
+
+func (r *budgetReserver) Reserve(ctx context.Context, preparedTx service.PreparedTx) (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reserved >= r.budget {
+		return nil, errors.New("budget exceeded")
+	}
+	r.reserved++
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.reserved--
+	}, nil
+}
+
+func newTestSigner(t *testing.T) *signer.PrivateKeySigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := signer.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func preparedTxStep(t *testing.T, to string) service.ChainActivityStep {
+	t.Helper()
+	step := service.ChainActivityStep{Type: service.ChainActivityStepTypePreparedTx}
+	err := step.Data.FromPreparedTx(service.PreparedTx{
+		GasUseEstimate:   21000,
+		MethodParameters: service.MethodParameters{To: to, Value: "1", Calldata: "0x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return step
+}
+
+func personalSignStep(t *testing.T, message string) service.ChainActivityStep {
+	t.Helper()
+	step := service.ChainActivityStep{Type: service.ChainActivityStepTypePersonalSignRequest}
+	if err := step.Data.FromPersonalSignRequest(service.PersonalSignRequest{Message: message}); err != nil {
+		t.Fatal(err)
+	}
+	return step
+}
+
+// stepsProvider returns the steps in order, advancing after each executed step
+func stepsProvider(steps ...service.ChainActivityStep) StepProvider {
+	return func(ctx context.Context, result *Result) (service.ChainActivity, error) {
+		number := len(result.Steps) + 1
+		if number > len(steps) {
+			number = len(steps)
+		}
+		return service.ChainActivity{NumberOfSteps: len(steps), StepNumber: number, Steps: steps}, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	to := "0x00000000000000000000000000000000000000aa"
+	tests := []struct {
+		name      string
+		steps     func(t *testing.T) []service.ChainActivityStep
+		opts      []Option
+		callErr   error
+		wantSteps int
+		wantSent  int
+		wantErr   error
+	}{
+		{
+			name: "prepared tx and personal sign",
+			steps: func(t *testing.T) []service.ChainActivityStep {
+				return []service.ChainActivityStep{preparedTxStep(t, to), personalSignStep(t, "hello")}
+			},
+			wantSteps: 2,
+			wantSent:  1,
+		},
+		{
+			name: "unsupported step",
+			steps: func(t *testing.T) []service.ChainActivityStep {
+				return []service.ChainActivityStep{{Type: "Unknown"}}
+			},
+			wantErr: ErrUnsupportedStep,
+		},
+		{
+			name: "invalid recipient",
+			steps: func(t *testing.T) []service.ChainActivityStep {
+				return []service.ChainActivityStep{preparedTxStep(t, "0x1234")}
+			},
+			opts:    []Option{WithSimulation(false)},
+			wantErr: errors.New("invalid recipient address"),
+		},
+		{
+			name: "validator rejects",
+			steps: func(t *testing.T) []service.ChainActivityStep {
+				return []service.ChainActivityStep{preparedTxStep(t, to)}
+			},
+			opts:    []Option{WithTxValidator(rejectValidator{})},
+			wantErr: errors.New("rejected"),
+		},
+		{
+			name: "simulation reverts",
+			steps: func(t *testing.T) []service.ChainActivityStep {
+				return []service.ChainActivityStep{preparedTxStep(t, to)}
+			},
+			callErr: errors.New("execution reverted"),
+			wantErr: errors.New("execution reverted"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend()
+			backend.callErr = tt.callErr
+			opts := append([]Option{WithPollInterval(time.Millisecond)}, tt.opts...)
+			e, err := New(backend, newTestSigner(t), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.Run(context.Background(), stepsProvider(tt.steps(t)...))
+			if tt.wantErr != nil {
+				var stepErr *StepError
+				if !errors.As(err, &stepErr) {
+					t.Fatalf("expected a step error, got %v", err)
+				}
+				if !errors.Is(err, tt.wantErr) && !containsError(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Steps) != tt.wantSteps {
+				t.Fatalf("expected %d steps, got %d", tt.wantSteps, len(result.Steps))
+			}
+			if len(backend.sent) != tt.wantSent {
+				t.Fatalf("expected %d sent transactions, got %d", tt.wantSent, len(backend.sent))
+			}
+		})
+	}
+}
+
+func TestRunSignsWithChainID(t *testing.T) {
+	backend := newTestBackend()
+	s := newTestSigner(t)
+	e, err := New(backend, s, WithChainID(big.NewInt(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Run(context.Background(), stepsProvider(preparedTxStep(t, "0x00000000000000000000000000000000000000aa"))); err != nil {
+		t.Fatal(err)
+	}
+	tx := backend.sent[0]
+	if tx.ChainId().Int64() != 5 {
+		t.Fatalf("expected chain id 5, got %v", tx.ChainId())
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != s.Address() {
+		t.Fatalf("expected sender %s, got %s", s.Address(), from)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	backend := newTestBackend()
+	e, err := New(backend, newTestSigner(t), WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := preparedTxStep(t, "0x00000000000000000000000000000000000000aa")
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := e.Run(context.Background(), stepsProvider(step))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	nonces := make(map[uint64]bool)
+	for _, tx := range backend.sent {
+		if tx.ChainId().Int64() != 1 {
+			t.Fatalf("expected chain id 1, got %v", tx.ChainId())
+		}
+		nonces[tx.Nonce()] = true
+	}
+	if len(nonces) != 4 {
+		t.Fatalf("expected 4 distinct nonces, got %d", len(nonces))
+	}
+}
+
+func TestRunReleasesReservations(t *testing.T) {
+	tests := []struct {
+		name         string
+		callErr      error
+		sendErr      error
+		opts         []Option
+		wantReserved int
+		wantErr      bool
+	}{
+		{name: "broadcast", wantReserved: 1},
+		{name: "simulation fails", callErr: errors.New("execution reverted"), wantErr: true},
+		{name: "send fails", sendErr: errors.New("insufficient funds for gas"), wantErr: true},
+		{name: "later validator rejects", opts: []Option{WithTxValidator(rejectValidator{})}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend()
+			backend.callErr = tt.callErr
+			backend.sendErr = tt.sendErr
+			reserver := &budgetReserver{budget: 1}
+			opts := append([]Option{WithPollInterval(time.Millisecond), WithTxValidator(reserver)}, tt.opts...)
+			e, err := New(backend, newTestSigner(t), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = e.Run(context.Background(), stepsProvider(preparedTxStep(t, "0x00000000000000000000000000000000000000aa")))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if reserver.reserved != tt.wantReserved {
+				t.Fatalf("expected %d reserved, got %d", tt.wantReserved, reserver.reserved)
+			}
+		})
+	}
+}
+
+// containsError reports whether the message of err contains the message of want
+func containsError(err, want error) bool {
+	return err != nil && want != nil && strings.Contains(err.Error(), want.Error())
+}
@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrFeeCapExceeded is returned when the current base fee is above the configured fee cap
+var ErrFeeCapExceeded = errors.New("base fee exceeds max fee cap")
+
+// FeeBackend is the subset of the Ethereum client used to price transactions.
+//
+// The go-ethereum *ethclient.Client implements this interface.
+type FeeBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Fees holds the fee fields of a transaction. GasPrice is set for legacy transactions,
+// GasFeeCap and GasTipCap for dynamic fee transactions.
+type Fees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+// IsDynamic returns true if the fees are for an EIP-1559 dynamic fee transaction
+func (f Fees) IsDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+// FeeStrategy decides the fees of a new transaction
+type FeeStrategy interface {
+	Fees(ctx context.Context, backend FeeBackend) (Fees, error)
+}
+
+// FeeHistoryStrategy prices dynamic fee transactions from the priority fees paid in recent
+// blocks, and falls back to legacy gas pricing on chains without London
+type FeeHistoryStrategy struct {
+	// Blocks is the number of recent blocks to sample, defaults to 10
+	Blocks uint64
+
+	// Percentile of the priority fees paid in each block, defaults to 50
+	Percentile float64
+
+	// BaseFeeMultiplier is applied to the next base fee to absorb base fee growth, defaults to 2
+	BaseFeeMultiplier int64
+
+	// MinTipCap and MaxTipCap bound the priority fee, if set
+	MinTipCap *big.Int
+	MaxTipCap *big.Int
+
+	// MaxFeeCap caps the max fee per gas, or the gas price of legacy transactions, if set
+	MaxFeeCap *big.Int
+}
+
+// DefaultFeeStrategy returns the fee strategy used when none is configured
+func DefaultFeeStrategy() *FeeHistoryStrategy {
+	return &FeeHistoryStrategy{}
+}
+
+// Fees implements FeeStrategy
+func (s *FeeHistoryStrategy) Fees(ctx context.Context, backend FeeBackend) (Fees, error) {
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if head.BaseFee == nil {
+		return s.legacyFees(ctx, backend)
+	}
+
+	blocks := s.Blocks
+	if blocks == 0 {
+		blocks = 10
+	}
+	percentile := s.Percentile
+	if percentile == 0 {
+		percentile = 50
+	}
+	multiplier := s.BaseFeeMultiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	history, err := backend.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return Fees{}, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	baseFee := head.BaseFee
+	if n := len(history.BaseFee); n > 0 && history.BaseFee[n-1] != nil {
+		// the last entry is the base fee of the next block
+		baseFee = history.BaseFee[n-1]
+	}
+	tip := medianReward(history.Reward)
+	if tip == nil {
+		tip, err = backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return Fees{}, fmt.Errorf("failed to get gas tip cap: %w", err)
+		}
+	}
+	if s.MinTipCap != nil && tip.Cmp(s.MinTipCap) < 0 {
+		tip = new(big.Int).Set(s.MinTipCap)
+	}
+	if s.MaxTipCap != nil && tip.Cmp(s.MaxTipCap) > 0 {
+		tip = new(big.Int).Set(s.MaxTipCap)
+	}
+
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(multiplier))
+	feeCap.Add(feeCap, tip)
+	if s.MaxFeeCap != nil && feeCap.Cmp(s.MaxFeeCap) > 0 {
+		if baseFee.Cmp(s.MaxFeeCap) >= 0 {
+			return Fees{}, fmt.Errorf("%w: base fee %s, max fee cap %s", ErrFeeCapExceeded, baseFee, s.MaxFeeCap)
+		}
+		feeCap = new(big.Int).Set(s.MaxFeeCap)
+		if room := new(big.Int).Sub(feeCap, baseFee); tip.Cmp(room) > 0 {
+			tip = room
+		}
+	}
+	return Fees{GasFeeCap: feeCap, GasTipCap: tip}, nil
+}
+
+// legacyFees prices a legacy transaction on chains without a base fee
+func (s *FeeHistoryStrategy) legacyFees(ctx context.Context, backend FeeBackend) (Fees, error) {
+	gasPrice, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return Fees{}, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	if s.MaxFeeCap != nil && gasPrice.Cmp(s.MaxFeeCap) > 0 {
+		return Fees{}, fmt.Errorf("%w: gas price %s, max fee cap %s", ErrFeeCapExceeded, gasPrice, s.MaxFeeCap)
+	}
+	return Fees{GasPrice: gasPrice}, nil
+}
+
+// medianReward returns the median of the sampled priority fees, ignoring empty blocks
+func medianReward(rewards [][]*big.Int) *big.Int {
+	var samples []*big.Int
+	for _, block := range rewards {
+		if len(block) > 0 && block[0] != nil && block[0].Sign() > 0 {
+			samples = append(samples, block[0])
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Cmp(samples[j]) < 0
+	})
+	return new(big.Int).Set(samples[len(samples)/2])
+}
@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// feeBackend returns fixed fee market data
+type feeBackend struct {
+	baseFee  *big.Int
+	nextBase *big.Int
+	rewards  [][]*big.Int
+	gasPrice *big.Int
+	tipCap   *big.Int
+}
+
+func (b *feeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: b.baseFee}, nil
+}
+
+func (b *feeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *feeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.tipCap, nil
+}
+
+func (b *feeBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	history := &ethereum.FeeHistory{Reward: b.rewards}
+	if b.nextBase != nil {
+		history.BaseFee = []*big.Int{b.baseFee, b.nextBase}
+	}
+	return history, nil
+}
+
+func rewards(tips ...int64) [][]*big.Int {
+	var r [][]*big.Int
+	for _, tip := range tips {
+		r = append(r, []*big.Int{big.NewInt(tip)})
+	}
+	return r
+}
+
+func TestFeeHistoryStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy FeeHistoryStrategy
+		backend  feeBackend
+		want     Fees
+		wantErr  error
+	}{
+		{
+			name:    "median tip and doubled next base fee",
+			backend: feeBackend{baseFee: big.NewInt(100), nextBase: big.NewInt(110), rewards: rewards(1, 5, 3)},
+			want:    Fees{GasFeeCap: big.NewInt(223), GasTipCap: big.NewInt(3)},
+		},
+		{
+			name:    "empty blocks fall back to the suggested tip",
+			backend: feeBackend{baseFee: big.NewInt(100), rewards: rewards(0, 0), tipCap: big.NewInt(7)},
+			want:    Fees{GasFeeCap: big.NewInt(207), GasTipCap: big.NewInt(7)},
+		},
+		{
+			name:     "tip bounds",
+			strategy: FeeHistoryStrategy{MinTipCap: big.NewInt(4), BaseFeeMultiplier: 1},
+			backend:  feeBackend{baseFee: big.NewInt(100), rewards: rewards(1)},
+			want:     Fees{GasFeeCap: big.NewInt(104), GasTipCap: big.NewInt(4)},
+		},
+		{
+			name:     "fee cap clamps the tip",
+			strategy: FeeHistoryStrategy{MaxFeeCap: big.NewInt(105)},
+			backend:  feeBackend{baseFee: big.NewInt(100), rewards: rewards(10)},
+			want:     Fees{GasFeeCap: big.NewInt(105), GasTipCap: big.NewInt(5)},
+		},
+		{
+			name:     "base fee above the fee cap",
+			strategy: FeeHistoryStrategy{MaxFeeCap: big.NewInt(90)},
+			backend:  feeBackend{baseFee: big.NewInt(100), rewards: rewards(10)},
+			wantErr:  ErrFeeCapExceeded,
+		},
+		{
+			name:    "legacy chain",
+			backend: feeBackend{gasPrice: big.NewInt(50)},
+			want:    Fees{GasPrice: big.NewInt(50)},
+		},
+		{
+			name:     "legacy gas price above the fee cap",
+			strategy: FeeHistoryStrategy{MaxFeeCap: big.NewInt(40)},
+			backend:  feeBackend{gasPrice: big.NewInt(50)},
+			wantErr:  ErrFeeCapExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fees, err := tt.strategy.Fees(context.Background(), &tt.backend)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalFees(fees, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, fees)
+			}
+		})
+	}
+}
+
+func TestBuildWithFees(t *testing.T) {
+	preparedTx := service.PreparedTx{
+		GasUseEstimate:   100000,
+		MethodParameters: service.MethodParameters{To: "0x00000000000000000000000000000000000000aa", Value: "5", Calldata: "0x0102"},
+	}
+	tests := []struct {
+		name     string
+		fees     Fees
+		buffer   int64
+		wantType uint8
+		wantGas  uint64
+		wantErr  bool
+	}{
+		{name: "dynamic", fees: Fees{GasFeeCap: big.NewInt(30), GasTipCap: big.NewInt(2)}, wantType: types.DynamicFeeTxType, wantGas: 100000},
+		{name: "legacy with buffer", fees: Fees{GasPrice: big.NewInt(30)}, buffer: 20, wantType: types.LegacyTxType, wantGas: 120000},
+		{name: "no fees", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewTxBuilder(&feeBackend{}, WithGasBuffer(tt.buffer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx, err := b.BuildWithFees(preparedTx, big.NewInt(1), 3, tt.fees)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.Type() != tt.wantType || tx.Gas() != tt.wantGas || tx.Nonce() != 3 || tx.Value().Int64() != 5 || len(tx.Data()) != 2 {
+				t.Fatalf("unexpected transaction: type %d gas %d nonce %d value %v data %x", tx.Type(), tx.Gas(), tx.Nonce(), tx.Value(), tx.Data())
+			}
+		})
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	tests := []struct {
+		name    string
+		fees    Fees
+		percent int64
+		want    Fees
+	}{
+		{
+			name:    "dynamic rounds up",
+			fees:    Fees{GasFeeCap: big.NewInt(101), GasTipCap: big.NewInt(10)},
+			percent: 10,
+			want:    Fees{GasFeeCap: big.NewInt(112), GasTipCap: big.NewInt(11)},
+		},
+		{
+			name:    "small tip still grows",
+			fees:    Fees{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2)},
+			percent: 10,
+			want:    Fees{GasFeeCap: big.NewInt(110), GasTipCap: big.NewInt(3)},
+		},
+		{
+			name:    "legacy rounds up",
+			fees:    Fees{GasPrice: big.NewInt(101)},
+			percent: 10,
+			want:    Fees{GasPrice: big.NewInt(112)},
+		},
+		{
+			name:    "legacy exact",
+			fees:    Fees{GasPrice: big.NewInt(1000)},
+			percent: 12,
+			want:    Fees{GasPrice: big.NewInt(1120)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bumpFees(tt.fees, tt.percent); !equalFees(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// equalFees compares fees field by field
+func equalFees(a, b Fees) bool {
+	eq := func(x, y *big.Int) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Cmp(y) == 0
+	}
+	return eq(a.GasPrice, b.GasPrice) && eq(a.GasFeeCap, b.GasFeeCap) && eq(a.GasTipCap, b.GasTipCap)
+}
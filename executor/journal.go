@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/journal"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// stepJournal records the progress of the step being executed, a nil stepJournal records nothing
+type stepJournal struct {
+	store      journal.Store
+	activityID string
+	entry      journal.Entry
+}
+
+// newStepJournal starts the journal entry of a step, it returns nil without an activity ID
+func (e *Executor) newStepJournal(activityID string, number int, step service.ChainActivityStep) *stepJournal {
+	if e.journal == nil || activityID == "" {
+		return nil
+	}
+	return &stepJournal{
+		store:      e.journal,
+		activityID: activityID,
+		entry: journal.Entry{
+			Step:    number,
+			Type:    step.Type,
+			Request: step,
+			Status:  journal.StatusPending,
+		},
+	}
+}
+
+// save updates the entry and writes it to the store
+func (j *stepJournal) save(ctx context.Context, update func(entry *journal.Entry)) error {
+	if j == nil {
+		return nil
+	}
+	update(&j.entry)
+	j.entry.UpdatedAt = time.Now().UTC()
+	if err := j.store.Save(ctx, j.activityID, j.entry); err != nil {
+		return fmt.Errorf("failed to journal step %d: %w", j.entry.Step, err)
+	}
+	return nil
+}
+
+// sent records a signed transaction right before it is broadcast
+func (j *stepJournal) sent(ctx context.Context, tx *types.Transaction) error {
+	if j == nil {
+		return nil
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode signed transaction: %w", err)
+	}
+	return j.save(ctx, func(entry *journal.Entry) {
+		hash := tx.Hash()
+		entry.Status = journal.StatusSent
+		entry.SignedTxs = append(entry.SignedTxs, raw)
+		entry.TxHash = &hash
+	})
+}
+
+// done records the outcome of the step. A step with a transaction that may still be mined
+// stays sent, so resuming waits for it instead of sending it again.
+func (j *stepJournal) done(ctx context.Context, stepResult *StepResult, stepErr error) error {
+	return j.save(ctx, func(entry *journal.Entry) {
+		if stepErr == nil {
+			entry.Status = journal.StatusConfirmed
+			entry.TxHash = stepResult.TxHash
+			entry.Receipt = stepResult.Receipt
+			entry.Signature = stepResult.Signature
+			entry.Error = ""
+			return
+		}
+		entry.Error = stepErr.Error()
+		if entry.Status != journal.StatusSent || errors.Is(stepErr, ErrTxFailed) || errors.Is(stepErr, ErrTxCancelled) {
+			entry.Status = journal.StatusFailed
+		}
+	})
+}
+
+// Resume executes the chain activity returned by provider like Run, journaling every step
+// under activityID. Steps confirmed by an earlier run are not executed again, and transactions
+// sent by an interrupted run are waited for instead of being sent again.
+func (e *Executor) Resume(ctx context.Context, activityID string, provider StepProvider) (*Result, error) {
+	if e.journal == nil {
+		return &Result{}, errors.New("resume requires a journal")
+	}
+	if activityID == "" {
+		return &Result{}, errors.New("activity id is required")
+	}
+	return e.run(ctx, activityID, provider)
+}
+
+// recover rebuilds the result of the steps journaled under activityID. It stops at the first
+// step that is neither confirmed nor sent, which is executed again.
+func (e *Executor) recover(ctx context.Context, activityID string) (*Result, error) {
+	result := &Result{}
+	entries, err := e.journal.Load(ctx, activityID)
+	if err != nil {
+		return result, fmt.Errorf("failed to load journal: %w", err)
+	}
+	for _, entry := range entries {
+		switch entry.Status {
+		case journal.StatusConfirmed:
+		case journal.StatusSent:
+			j := &stepJournal{store: e.journal, activityID: activityID, entry: entry}
+			stepResult, err := e.resumeSent(ctx, j)
+			if doneErr := j.done(ctx, stepResult, err); err == nil {
+				err = doneErr
+			}
+			if err != nil {
+				return result, &StepError{Number: entry.Step, Type: entry.Type, Err: err}
+			}
+			entry = j.entry
+		default:
+			return result, nil
+		}
+		result.Steps = append(result.Steps, entryResult(entry))
+	}
+	return result, nil
+}
+
+// resumeSent waits for the transactions of an interrupted step, rebroadcasting the last one
+// in case it never reached the node
+func (e *Executor) resumeSent(ctx context.Context, j *stepJournal) (*StepResult, error) {
+	txs, err := j.entry.Transactions()
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("no signed transaction journaled for step %d", j.entry.Step)
+	}
+	preparedTx, err := j.entry.Request.Data.AsPreparedTx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode prepared tx: %w", err)
+	}
+
+	self := e.signer.Address()
+	sent := make([]pendingTx, len(txs))
+	for i, tx := range txs {
+		sent[i] = pendingTx{tx: tx, cancel: isCancel(tx, self)}
+	}
+	last := txs[len(txs)-1]
+	if err := e.backend.SendTransaction(ctx, last); err != nil && !isAlreadyKnown(err) && !isNonceTooLow(err) && !isUnderpriced(err) {
+		return nil, fmt.Errorf("failed to rebroadcast transaction: %w", err)
+	}
+
+	stepResult := &StepResult{Number: j.entry.Step, Type: j.entry.Type, Label: preparedTx.Label}
+	receipt, replaced, err := e.waitMined(ctx, preparedTx, sent, j)
+	if err != nil {
+		return nil, err
+	}
+	stepResult.Replaced = replaced
+	stepResult.TxHash = &receipt.TxHash
+	stepResult.Receipt = receipt
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("%w: %s", ErrTxFailed, receipt.TxHash.Hex())
+	}
+	return stepResult, nil
+}
+
+// entryResult converts a confirmed journal entry into the result of its step
+func entryResult(entry journal.Entry) StepResult {
+	stepResult := StepResult{
+		Number:    entry.Step,
+		Type:      entry.Type,
+		TxHash:    entry.TxHash,
+		Receipt:   entry.Receipt,
+		Signature: entry.Signature,
+	}
+	if entry.Type == service.ChainActivityStepTypePreparedTx {
+		if preparedTx, err := entry.Request.Data.AsPreparedTx(); err == nil {
+			stepResult.Label = preparedTx.Label
+		}
+	}
+	if txs, err := entry.Transactions(); err == nil && entry.TxHash != nil {
+		for _, tx := range txs {
+			if tx.Hash() != *entry.TxHash {
+				stepResult.Replaced = append(stepResult.Replaced, tx.Hash())
+			}
+		}
+	}
+	return stepResult
+}
+
+// isCancel reports whether tx is a cancellation sent by the replacement policy
+func isCancel(tx *types.Transaction, self common.Address) bool {
+	return tx.To() != nil && *tx.To() == self && tx.Value().Sign() == 0 && len(tx.Data()) == 0
+}
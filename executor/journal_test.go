@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/journal"
+)
+
+// never leaves every sent transaction pending
+func never(sent []*types.Transaction) int {
+	return -1
+}
+
+func TestResume(t *testing.T) {
+	to := "0x00000000000000000000000000000000000000aa"
+	tests := []struct {
+		name string
+		// first runs until the interruption, with the backend of both runs
+		first        func(backend *stuckBackend) []Option
+		resume       func(sent []*types.Transaction) int
+		wantErr      error
+		wantStatus   journal.Status
+		wantAttempts int
+		wantSent     int
+		wantMined    int
+	}{
+		{
+			name: "crash between sign and send",
+			first: func(backend *stuckBackend) []Option {
+				backend.sendErrs = []error{errors.New("connection reset by peer")}
+				return nil
+			},
+			resume:       mineAt(0, 1),
+			wantStatus:   journal.StatusConfirmed,
+			wantAttempts: 2,
+			wantSent:     1,
+			wantMined:    0,
+		},
+		{
+			name: "crash after send before the receipt",
+			first: func(backend *stuckBackend) []Option {
+				return []Option{WithReceiptTimeout(10 * time.Millisecond)}
+			},
+			resume:       mineAt(0, 1),
+			wantStatus:   journal.StatusConfirmed,
+			wantAttempts: 2,
+			wantSent:     1,
+			wantMined:    0,
+		},
+		{
+			name: "crash after a speed-up",
+			first: func(backend *stuckBackend) []Option {
+				return []Option{
+					WithReceiptTimeout(10 * time.Millisecond),
+					WithReplacementPolicy(ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, MaxReplacements: 1}),
+				}
+			},
+			resume:       mineAt(0, 2),
+			wantStatus:   journal.StatusConfirmed,
+			wantAttempts: 3,
+			wantSent:     2,
+			wantMined:    0,
+		},
+		{
+			name: "journaled cancel",
+			first: func(backend *stuckBackend) []Option {
+				return []Option{
+					WithReceiptTimeout(10 * time.Millisecond),
+					WithReplacementPolicy(ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, Cancel: true}),
+				}
+			},
+			resume:       mineAt(1, 2),
+			wantErr:      ErrTxCancelled,
+			wantStatus:   journal.StatusFailed,
+			wantAttempts: 3,
+			wantSent:     2,
+			wantMined:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := journal.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			backend := &stuckBackend{testBackend: newTestBackend(), mine: never}
+			s := newTestSigner(t)
+			provider := stepsProvider(preparedTxStep(t, to))
+
+			opts := append([]Option{WithJournal(store), WithPollInterval(time.Millisecond)}, tt.first(backend)...)
+			interrupted, err := New(backend, s, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := interrupted.Resume(context.Background(), "activity-1", provider); err == nil {
+				t.Fatal("expected the first run to be interrupted")
+			}
+			entries, err := store.Load(context.Background(), "activity-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Status != journal.StatusSent {
+				t.Fatalf("expected the step to be journaled as sent, got %+v", entries)
+			}
+
+			// a new process resumes the activity, without a replacement policy
+			backend.mine = tt.resume
+			resumed, err := New(backend, s, WithJournal(store), WithPollInterval(time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := resumed.Resume(context.Background(), "activity-1", provider)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(backend.attempts) != tt.wantAttempts {
+				t.Fatalf("expected %d sends, got %d", tt.wantAttempts, len(backend.attempts))
+			}
+			if len(backend.sent) != tt.wantSent {
+				t.Fatalf("expected %d transactions in the pool, got %d", tt.wantSent, len(backend.sent))
+			}
+			// the journaled transaction is rebroadcast instead of signing a new one
+			if last := backend.attempts[len(backend.attempts)-1]; last.Hash() != backend.sent[len(backend.sent)-1].Hash() {
+				t.Fatal("expected the last journaled transaction to be rebroadcast")
+			}
+
+			entries, err = store.Load(context.Background(), "activity-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Status != tt.wantStatus {
+				t.Fatalf("expected the step to be journaled as %s, got %+v", tt.wantStatus, entries)
+			}
+			if len(entries[0].SignedTxs) != tt.wantSent {
+				t.Fatalf("expected %d journaled transactions, got %d", tt.wantSent, len(entries[0].SignedTxs))
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			mined := backend.sent[tt.wantMined].Hash()
+			if step := result.Last(); step == nil || step.TxHash == nil || *step.TxHash != mined {
+				t.Fatalf("expected mined transaction %s, got %+v", mined.Hex(), result.Last())
+			}
+			if len(result.Last().Replaced) != tt.wantSent-1 {
+				t.Fatalf("expected %d replaced transactions, got %v", tt.wantSent-1, result.Last().Replaced)
+			}
+
+			// resuming a completed activity sends nothing
+			attempts := len(backend.attempts)
+			again, err := resumed.Resume(context.Background(), "activity-1", provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(backend.attempts) != attempts || len(again.Steps) != 1 || *again.Steps[0].TxHash != mined {
+				t.Fatalf("expected the confirmed step to be recovered from the journal, got %+v", again.Steps)
+			}
+		})
+	}
+}
+
+func TestResumeRequiresJournal(t *testing.T) {
+	e, err := New(newTestBackend(), newTestSigner(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Resume(context.Background(), "activity-1", stepsProvider()); err == nil {
+		t.Fatal("expected an error without a journal")
+	}
+	store, err := journal.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err = New(newTestBackend(), newTestSigner(t), WithJournal(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Resume(context.Background(), "", stepsProvider()); err == nil {
+		t.Fatal("expected an error without an activity id")
+	}
+}
+
+func TestIsCancel(t *testing.T) {
+	self := newTestSigner(t).Address()
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+		want bool
+	}{
+		{name: "self-transfer", tx: transfer(self, 0, nil), want: true},
+		{name: "transfer to another account", tx: transfer(other, 0, nil)},
+		{name: "self-transfer with value", tx: transfer(self, 1, nil)},
+		{name: "self call", tx: transfer(self, 0, []byte{1})},
+		{name: "contract creation", tx: types.NewTx(&types.LegacyTx{Value: new(big.Int)})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCancel(tt.tx, self); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// transfer is an unsigned transaction sending value wei and data to to
+func transfer(to common.Address, value int64, data []byte) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{To: &to, Value: big.NewInt(value), Data: data, Gas: cancelGas})
+}
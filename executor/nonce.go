@@ -0,0 +1,200 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceBackend is the subset of the Ethereum client used to track account nonces.
+//
+// The go-ethereum *ethclient.Client implements this interface.
+type NonceBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out sequential nonces per account. It is safe for concurrent use and
+// is meant to be shared by every executor sending from the same accounts.
+//
+// A nonce is reserved by Next until it is either released with Release, because its transaction
+// was never broadcast, or marked with Broadcast. Resync reconciles the local state with the
+// pending nonce of the chain without touching reservations, and hands out again the nonces of
+// transactions that were dropped from the pool.
+type NonceManager struct {
+	backend NonceBackend
+
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+// accountNonces tracks the nonces of a single account
+type accountNonces struct {
+	next     uint64
+	synced   bool
+	released []uint64
+	reserved map[uint64]bool
+}
+
+// NewNonceManager creates a NonceManager that syncs with the chain on first use of an account
+func NewNonceManager(backend NonceBackend) *NonceManager {
+	return &NonceManager{
+		backend:  backend,
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+// Next reserves the next nonce of account. Nonces released earlier are handed out first,
+// so a transaction that was never broadcast does not leave a gap.
+func (m *NonceManager) Next(ctx context.Context, account common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonces := m.account(account)
+	if !nonces.synced {
+		pending, err := m.backend.PendingNonceAt(ctx, account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		if pending > nonces.next {
+			nonces.next = pending
+		}
+		nonces.synced = true
+	}
+	var nonce uint64
+	if len(nonces.released) > 0 {
+		nonce = nonces.released[0]
+		nonces.released = nonces.released[1:]
+	} else {
+		nonce = nonces.next
+		nonces.next++
+	}
+	nonces.reserved[nonce] = true
+	return nonce, nil
+}
+
+// Release returns a reserved nonce whose transaction was never broadcast
+func (m *NonceManager) Release(account common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonces := m.account(account)
+	delete(nonces.reserved, nonce)
+	if nonce >= nonces.next {
+		return
+	}
+	nonces.release(nonce)
+}
+
+// Broadcast marks a reserved nonce as used by a broadcast transaction. Once broadcast, the nonce
+// is only handed out again if Resync finds that its transaction was dropped.
+func (m *NonceManager) Broadcast(account common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.account(account).reserved, nonce)
+}
+
+// Resync reads the pending nonce of account from the chain and reconciles the local state with
+// it. Call it after a nonce too low error, or once a transaction may have been dropped.
+//
+// The counter only moves forward and nonces reserved by other executors stay reserved. Released
+// nonces that were used on chain in the meantime are discarded. Nonces between the pending nonce
+// and the counter that are neither reserved nor released belong to broadcast transactions the
+// node no longer knows about, so they are released to fill the gap. A transaction that reached
+// a different node but not yet the queried one is counted as dropped as well; its nonce is then
+// reused and only one of the two transactions gets mined.
+func (m *NonceManager) Resync(ctx context.Context, account common.Address) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := m.backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	nonces := m.account(account)
+	nonces.synced = true
+
+	released := nonces.released[:0]
+	for _, nonce := range nonces.released {
+		if nonce >= pending {
+			released = append(released, nonce)
+		}
+	}
+	nonces.released = released
+	for nonce := range nonces.reserved {
+		if nonce < pending {
+			// the nonce was used by another process, its transaction will be rejected
+			delete(nonces.reserved, nonce)
+		}
+	}
+
+	if pending > nonces.next {
+		nonces.next = pending
+		return nil
+	}
+	for nonce := pending; nonce < nonces.next; nonce++ {
+		if !nonces.reserved[nonce] && !nonces.isReleased(nonce) {
+			nonces.release(nonce)
+		}
+	}
+	return nil
+}
+
+// Gaps returns the nonces between the pending nonce of the chain and the counter that are
+// neither reserved nor released, which are the nonces of dropped transactions. Resync fills them.
+func (m *NonceManager) Gaps(ctx context.Context, account common.Address) ([]uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := m.backend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	nonces := m.account(account)
+	var gaps []uint64
+	for nonce := pending; nonce < nonces.next; nonce++ {
+		if !nonces.reserved[nonce] && !nonces.isReleased(nonce) {
+			gaps = append(gaps, nonce)
+		}
+	}
+	return gaps, nil
+}
+
+// account returns the state of account, m.mu must be held
+func (m *NonceManager) account(account common.Address) *accountNonces {
+	nonces, ok := m.accounts[account]
+	if !ok {
+		nonces = &accountNonces{reserved: make(map[uint64]bool)}
+		m.accounts[account] = nonces
+	}
+	return nonces
+}
+
+// isReleased reports whether nonce is waiting to be handed out again
+func (n *accountNonces) isReleased(nonce uint64) bool {
+	for _, released := range n.released {
+		if released == nonce {
+			return true
+		}
+	}
+	return false
+}
+
+// release queues nonce to be handed out again, shrinking the counter instead of keeping
+// released nonces at the top
+func (n *accountNonces) release(nonce uint64) {
+	if n.isReleased(nonce) {
+		return
+	}
+	n.released = append(n.released, nonce)
+	sort.Slice(n.released, func(i, j int) bool {
+		return n.released[i] < n.released[j]
+	})
+	for k := len(n.released); k > 0 && n.released[k-1] == n.next-1; k-- {
+		n.next--
+		n.released = n.released[:k-1]
+	}
+}
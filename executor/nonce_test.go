@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var nonceAccount = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+// nonceStep is an action on a NonceManager, followed by the chain's pending nonce for the next steps
+type nonceStep struct {
+	next      int // number of nonces to reserve with Next
+	release   []uint64
+	broadcast []uint64
+	pending   *uint64 // new pending nonce of the chain, before resyncing
+	resync    bool
+}
+
+func pendingNonce(n uint64) *uint64 {
+	return &n
+}
+
+func TestNonceManager(t *testing.T) {
+	tests := []struct {
+		name       string
+		pending    uint64
+		steps      []nonceStep
+		wantNonces []uint64
+		wantGaps   []uint64
+	}{
+		{
+			name:       "sequential from the pending nonce",
+			pending:    4,
+			steps:      []nonceStep{{next: 3}},
+			wantNonces: []uint64{4, 5, 6},
+		},
+		{
+			name:    "released nonces are reused first",
+			pending: 0,
+			steps: []nonceStep{
+				{next: 3},
+				{release: []uint64{1}},
+				{next: 2},
+			},
+			wantNonces: []uint64{0, 1, 2, 1, 3},
+		},
+		{
+			name:    "releasing the top shrinks the counter",
+			pending: 0,
+			steps: []nonceStep{
+				{next: 3},
+				{release: []uint64{2, 1}},
+				{next: 1},
+			},
+			wantNonces: []uint64{0, 1, 2, 1},
+		},
+		{
+			name:    "resync keeps reservations of other executors",
+			pending: 10,
+			steps: []nonceStep{
+				{next: 3},
+				{broadcast: []uint64{10}, pending: pendingNonce(11), resync: true},
+				{next: 1},
+			},
+			wantNonces: []uint64{10, 11, 12, 13},
+		},
+		{
+			name:    "resync moves the counter forward only",
+			pending: 10,
+			steps: []nonceStep{
+				{next: 2},
+				{broadcast: []uint64{10, 11}, pending: pendingNonce(15), resync: true},
+				{next: 1},
+			},
+			wantNonces: []uint64{10, 11, 15},
+		},
+		{
+			name:    "resync discards released nonces used on chain",
+			pending: 0,
+			steps: []nonceStep{
+				{next: 3},
+				{release: []uint64{0}},
+				{broadcast: []uint64{1, 2}, pending: pendingNonce(3), resync: true},
+				{next: 1},
+			},
+			wantNonces: []uint64{0, 1, 2, 3},
+		},
+		{
+			name:    "resync refills dropped transactions",
+			pending: 0,
+			steps: []nonceStep{
+				{next: 4},
+				{broadcast: []uint64{0, 1, 2}, pending: pendingNonce(1), resync: true},
+				{next: 3},
+			},
+			wantNonces: []uint64{0, 1, 2, 3, 1, 2, 4},
+		},
+		{
+			name:    "gaps exclude reserved and released nonces",
+			pending: 0,
+			steps: []nonceStep{
+				{next: 5},
+				{broadcast: []uint64{0, 1, 2}, release: []uint64{3}, pending: pendingNonce(1)},
+			},
+			wantNonces: []uint64{0, 1, 2, 3, 4},
+			wantGaps:   []uint64{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend()
+			backend.pending[nonceAccount] = tt.pending
+			m := NewNonceManager(backend)
+			ctx := context.Background()
+
+			var nonces []uint64
+			for _, step := range tt.steps {
+				for i := 0; i < step.next; i++ {
+					nonce, err := m.Next(ctx, nonceAccount)
+					if err != nil {
+						t.Fatal(err)
+					}
+					nonces = append(nonces, nonce)
+				}
+				for _, nonce := range step.broadcast {
+					m.Broadcast(nonceAccount, nonce)
+				}
+				for _, nonce := range step.release {
+					m.Release(nonceAccount, nonce)
+				}
+				if step.pending != nil {
+					backend.pending[nonceAccount] = *step.pending
+				}
+				if step.resync {
+					if err := m.Resync(ctx, nonceAccount); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if !reflect.DeepEqual(nonces, tt.wantNonces) {
+				t.Fatalf("expected nonces %v, got %v", tt.wantNonces, nonces)
+			}
+			gaps, err := m.Gaps(ctx, nonceAccount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gaps, tt.wantGaps) {
+				t.Fatalf("expected gaps %v, got %v", tt.wantGaps, gaps)
+			}
+		})
+	}
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	backend := newTestBackend()
+	m := NewNonceManager(backend)
+	ctx := context.Background()
+
+	const workers, perWorker = 8, 50
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				nonce, err := m.Next(ctx, nonceAccount)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if i%10 == 0 {
+					// another executor hit nonce too low while this one holds a reservation
+					if err := m.Resync(ctx, nonceAccount); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+				mu.Lock()
+				if seen[nonce] {
+					t.Errorf("nonce %d handed out twice", nonce)
+				}
+				seen[nonce] = true
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	if len(seen) != workers*perWorker {
+		t.Fatalf("expected %d distinct nonces, got %d", workers*perWorker, len(seen))
+	}
+}
@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// cancelGas is the gas limit of a plain value transfer
+const cancelGas = 21000
+
+// ReplacementPolicy decides what happens to a PreparedTx that stays pending for too long
+type ReplacementPolicy struct {
+	// StuckAfter is how long a transaction may stay pending before it is replaced
+	StuckAfter time.Duration
+
+	// BumpPercent is the minimum fee increase of a replacement, defaults to 10 which is the
+	// minimum accepted by geth's transaction pool
+	BumpPercent int64
+
+	// MaxReplacements is the number of speed-ups sent before giving up on the transaction
+	MaxReplacements int
+
+	// Cancel replaces the transaction with a zero-value self-transfer once MaxReplacements
+	// speed-ups did not get it mined
+	Cancel bool
+
+	// MaxFeeCap stops replacements once the max fee per gas, or the gas price of legacy
+	// transactions, would go above it, if set
+	MaxFeeCap *big.Int
+}
+
+// pendingTx is a broadcast transaction competing for the nonce of a step
+type pendingTx struct {
+	tx     *types.Transaction
+	cancel bool
+}
+
+// waitMined polls for the receipts of the sent transactions and their replacements until one of them
+// is mined, or until the receipt timeout expires after the last send. With a replacement policy
+// set, the transaction is sped up or cancelled when it stays pending for too long. The hashes of
+// the transactions that did not get mined are returned alongside the receipt.
+func (e *Executor) waitMined(ctx context.Context, preparedTx service.PreparedTx, sent []pendingTx, j *stepJournal) (*types.Receipt, []common.Hash, error) {
+	deadline := time.Now().Add(e.receiptTimeout)
+	lastSent := time.Now()
+	replacing := e.replace != nil
+	// underpriced is the last replacement rejected by the pool, which the next one must outbid
+	var underpriced *types.Transaction
+	for {
+		for i, p := range sent {
+			receipt, err := e.backend.TransactionReceipt(ctx, p.tx.Hash())
+			if err == nil {
+				replaced := replacedHashes(sent, i)
+				if p.cancel {
+					return receipt, replaced, fmt.Errorf("%w: %s", ErrTxCancelled, receipt.TxHash.Hex())
+				}
+				return receipt, replaced, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) && time.Now().After(deadline) {
+				return nil, replacedHashes(sent, -1), fmt.Errorf("%w: %s: %v", ErrReceiptTimeout, p.tx.Hash().Hex(), err)
+			}
+		}
+		last := sent[len(sent)-1].tx
+		if time.Now().After(deadline) {
+			return nil, replacedHashes(sent, -1), fmt.Errorf("%w: %s", ErrReceiptTimeout, last.Hash().Hex())
+		}
+
+		if replacing && time.Since(lastSent) >= e.replace.StuckAfter {
+			next, err := e.replacement(ctx, preparedTx, sent, underpriced)
+			if err != nil {
+				return nil, replacedHashes(sent, -1), err
+			}
+			if next == nil {
+				replacing = false
+			} else {
+				signedTx, err := e.signAndSend(ctx, next.tx, j)
+				switch {
+				case err == nil:
+					sent = append(sent, pendingTx{tx: signedTx, cancel: next.cancel})
+					deadline = time.Now().Add(e.receiptTimeout)
+					underpriced = nil
+				case isNonceTooLow(err):
+					// one of the sent transactions got mined in the meantime
+				case isUnderpriced(err):
+					// the pool wants a larger bump, bump the rejected fees again later
+					underpriced = next.tx
+				default:
+					return nil, replacedHashes(sent, -1), err
+				}
+				lastSent = time.Now()
+			}
+		}
+
+		if err := sleep(ctx, e.pollInterval); err != nil {
+			return nil, replacedHashes(sent, -1), err
+		}
+	}
+}
+
+// replacement returns the unsigned transaction replacing the last sent one, or nil once the
+// policy allows no further replacements. The fees of the last sent transaction are bumped, or
+// those of the last rejected replacement if set.
+func (e *Executor) replacement(ctx context.Context, preparedTx service.PreparedTx, sent []pendingTx, rejected *types.Transaction) (*pendingTx, error) {
+	last := sent[len(sent)-1]
+	if last.cancel {
+		return nil, nil
+	}
+	cancel := len(sent) > e.replace.MaxReplacements
+	if cancel && !e.replace.Cancel {
+		return nil, nil
+	}
+
+	outbid := last.tx
+	if rejected != nil {
+		outbid = rejected
+	}
+	fees := bumpFees(txFees(outbid), e.replace.BumpPercent)
+	current, err := e.builder.strategy.Fees(ctx, e.builder.backend)
+	if err != nil && !errors.Is(err, ErrFeeCapExceeded) {
+		return nil, err
+	}
+	if err == nil {
+		fees = maxFees(fees, current)
+	}
+	if e.replace.MaxFeeCap != nil {
+		feeCap := fees.GasPrice
+		if fees.IsDynamic() {
+			feeCap = fees.GasFeeCap
+		}
+		if feeCap.Cmp(e.replace.MaxFeeCap) > 0 {
+			return nil, nil
+		}
+	}
+
+	if !cancel {
+		tx, err := e.builder.BuildWithFees(preparedTx, e.chainID, last.tx.Nonce(), fees)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build replacement transaction: %w", err)
+		}
+		return &pendingTx{tx: tx}, nil
+	}
+
+	self := e.signer.Address()
+	if fees.IsDynamic() {
+		return &pendingTx{
+			tx: types.NewTx(&types.DynamicFeeTx{
+				ChainID:   e.chainID,
+				Nonce:     last.tx.Nonce(),
+				GasTipCap: fees.GasTipCap,
+				GasFeeCap: fees.GasFeeCap,
+				Gas:       cancelGas,
+				To:        &self,
+				Value:     new(big.Int),
+			}),
+			cancel: true,
+		}, nil
+	}
+	return &pendingTx{
+		tx: types.NewTx(&types.LegacyTx{
+			Nonce:    last.tx.Nonce(),
+			GasPrice: fees.GasPrice,
+			Gas:      cancelGas,
+			To:       &self,
+			Value:    new(big.Int),
+		}),
+		cancel: true,
+	}, nil
+}
+
+// txFees returns the fee fields of a transaction
+func txFees(tx *types.Transaction) Fees {
+	if tx.Type() == types.DynamicFeeTxType {
+		return Fees{GasFeeCap: tx.GasFeeCap(), GasTipCap: tx.GasTipCap()}
+	}
+	return Fees{GasPrice: tx.GasPrice()}
+}
+
+// bumpFees raises every fee field by percent, rounding up
+func bumpFees(fees Fees, percent int64) Fees {
+	return Fees{
+		GasPrice:  bump(fees.GasPrice, percent),
+		GasFeeCap: bump(fees.GasFeeCap, percent),
+		GasTipCap: bump(fees.GasTipCap, percent),
+	}
+}
+
+// bump returns v raised by percent, rounding up
+func bump(v *big.Int, percent int64) *big.Int {
+	if v == nil {
+		return nil
+	}
+	bumped := new(big.Int).Mul(v, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// maxFees keeps the higher of the bumped and the current fees, field by field. The type of
+// the transaction being replaced is kept.
+func maxFees(bumped, current Fees) Fees {
+	if bumped.IsDynamic() {
+		if !current.IsDynamic() {
+			return bumped
+		}
+		return Fees{
+			GasFeeCap: maxBig(bumped.GasFeeCap, current.GasFeeCap),
+			GasTipCap: maxBig(bumped.GasTipCap, current.GasTipCap),
+		}
+	}
+	if current.GasPrice == nil {
+		return bumped
+	}
+	return Fees{GasPrice: maxBig(bumped.GasPrice, current.GasPrice)}
+}
+
+// maxBig returns the larger of a and b
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// replacedHashes returns the hashes of the sent transactions except the one at index mined
+func replacedHashes(sent []pendingTx, mined int) []common.Hash {
+	var hashes []common.Hash
+	for i, p := range sent {
+		if i != mined {
+			hashes = append(hashes, p.tx.Hash())
+		}
+	}
+	return hashes
+}
+
+// isUnderpriced reports whether the node rejected a replacement for not paying enough
+func isUnderpriced(err error) bool {
+	return strings.Contains(err.Error(), "underpriced")
+}
@@ -0,0 +1,209 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// stuckBackend is an in-memory chain that leaves sent transactions pending until mine picks one.
+// Like a node, it rejects a transaction it already has.
+type stuckBackend struct {
+	*testBackend
+	// sendErrs are returned by successive sends, a nil error accepts the transaction
+	sendErrs []error
+	// mine returns the index of the sent transaction to mine, or -1
+	mine     func(sent []*types.Transaction) int
+	attempts []*types.Transaction
+}
+
+func (b *stuckBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	attempt := len(b.attempts)
+	b.attempts = append(b.attempts, tx)
+	if attempt < len(b.sendErrs) && b.sendErrs[attempt] != nil {
+		return b.sendErrs[attempt]
+	}
+	for _, sent := range b.sent {
+		if sent.Hash() == tx.Hash() {
+			return errors.New("already known")
+		}
+	}
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *stuckBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i := b.mine(b.sent); i >= 0 && b.sent[i].Hash() == txHash {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}}, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// mineAt mines the sent transaction at index once count transactions were sent
+func mineAt(index, count int) func(sent []*types.Transaction) int {
+	return func(sent []*types.Transaction) int {
+		if len(sent) < count {
+			return -1
+		}
+		return index
+	}
+}
+
+// mineAfter mines the sent transaction at index once its receipt was asked for polls times, which
+// leaves the executor time to replace it
+func mineAfter(index, polls int) func(sent []*types.Transaction) int {
+	return func(sent []*types.Transaction) int {
+		if polls--; polls > 0 {
+			return -1
+		}
+		return index
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	errUnderpriced := errors.New("replacement transaction underpriced")
+	tests := []struct {
+		name         string
+		policy       ReplacementPolicy
+		sendErrs     []error
+		mine         func(sent []*types.Transaction) int
+		wantErr      error
+		wantAttempts int
+		wantSent     int
+		wantMined    int
+		check        func(t *testing.T, self common.Address, attempts []*types.Transaction)
+	}{
+		{
+			name:         "sped up",
+			policy:       ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, MaxReplacements: 3},
+			mine:         mineAt(1, 2),
+			wantAttempts: 2,
+			wantSent:     2,
+			wantMined:    1,
+			check: func(t *testing.T, self common.Address, attempts []*types.Transaction) {
+				original, replacement := attempts[0], attempts[1]
+				if replacement.Nonce() != original.Nonce() || replacement.To() == nil || *replacement.To() != *original.To() {
+					t.Fatal("expected the replacement to keep the nonce and recipient")
+				}
+				if replacement.GasFeeCap().Cmp(bump(original.GasFeeCap(), 10)) < 0 || replacement.GasTipCap().Cmp(bump(original.GasTipCap(), 10)) < 0 {
+					t.Fatalf("expected fees bumped by 10%%, got %v/%v from %v/%v", replacement.GasFeeCap(), replacement.GasTipCap(), original.GasFeeCap(), original.GasTipCap())
+				}
+			},
+		},
+		{
+			name:         "underpriced replacement is bumped again",
+			policy:       ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, MaxReplacements: 3},
+			sendErrs:     []error{nil, errUnderpriced},
+			mine:         mineAt(1, 2),
+			wantAttempts: 3,
+			wantSent:     2,
+			wantMined:    1,
+			check: func(t *testing.T, self common.Address, attempts []*types.Transaction) {
+				rejected, retried := attempts[1], attempts[2]
+				if retried.Nonce() != rejected.Nonce() {
+					t.Fatal("expected the retry to keep the nonce")
+				}
+				if retried.GasFeeCap().Cmp(bump(rejected.GasFeeCap(), 10)) < 0 || retried.GasTipCap().Cmp(bump(rejected.GasTipCap(), 10)) < 0 {
+					t.Fatalf("expected the retry to outbid the rejected fees %v/%v, got %v/%v", rejected.GasFeeCap(), rejected.GasTipCap(), retried.GasFeeCap(), retried.GasTipCap())
+				}
+			},
+		},
+		{
+			name:         "original mined while a replacement is pending",
+			policy:       ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, MaxReplacements: 3},
+			mine:         mineAt(0, 2),
+			wantAttempts: 2,
+			wantSent:     2,
+			wantMined:    0,
+		},
+		{
+			name:         "cancelled",
+			policy:       ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, Cancel: true},
+			mine:         mineAt(1, 2),
+			wantErr:      ErrTxCancelled,
+			wantAttempts: 2,
+			wantSent:     2,
+			wantMined:    1,
+			check: func(t *testing.T, self common.Address, attempts []*types.Transaction) {
+				original, cancel := attempts[0], attempts[1]
+				if cancel.Nonce() != original.Nonce() {
+					t.Fatalf("expected nonce %d, got %d", original.Nonce(), cancel.Nonce())
+				}
+				if cancel.To() == nil || *cancel.To() != self || cancel.Value().Sign() != 0 || len(cancel.Data()) != 0 || cancel.Gas() != cancelGas {
+					t.Fatalf("expected a zero-value self-transfer, got to %v, value %v, gas %d", cancel.To(), cancel.Value(), cancel.Gas())
+				}
+			},
+		},
+		{
+			name:         "fee cap stops replacements",
+			policy:       ReplacementPolicy{StuckAfter: time.Millisecond, BumpPercent: 10, MaxReplacements: 3, MaxFeeCap: big.NewInt(1)},
+			mine:         mineAfter(0, 20),
+			wantAttempts: 1,
+			wantSent:     1,
+			wantMined:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &stuckBackend{testBackend: newTestBackend(), sendErrs: tt.sendErrs, mine: tt.mine}
+			s := newTestSigner(t)
+			e, err := New(backend, s, WithPollInterval(time.Millisecond), WithReplacementPolicy(tt.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.Run(context.Background(), stepsProvider(preparedTxStep(t, "0x00000000000000000000000000000000000000aa")))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(backend.attempts) != tt.wantAttempts {
+				t.Fatalf("expected %d sends, got %d", tt.wantAttempts, len(backend.attempts))
+			}
+			if len(backend.sent) != tt.wantSent {
+				t.Fatalf("expected %d accepted sends, got %d", tt.wantSent, len(backend.sent))
+			}
+			if tt.wantErr == nil {
+				step := result.Steps[0]
+				mined := backend.sent[tt.wantMined].Hash()
+				if step.TxHash == nil || *step.TxHash != mined {
+					t.Fatalf("expected mined transaction %s, got %v", mined.Hex(), step.TxHash)
+				}
+				if len(step.Replaced) != tt.wantSent-1 {
+					t.Fatalf("expected %d replaced transactions, got %v", tt.wantSent-1, step.Replaced)
+				}
+				for _, hash := range step.Replaced {
+					if hash == mined {
+						t.Fatal("expected the mined transaction not to be reported as replaced")
+					}
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, s.Address(), backend.attempts)
+			}
+		})
+	}
+}
+
+func TestMaxFees(t *testing.T) {
+	bumped := Fees{GasFeeCap: big.NewInt(110), GasTipCap: big.NewInt(3)}
+	got := maxFees(bumped, Fees{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(5)})
+	if got.GasFeeCap.Int64() != 110 || got.GasTipCap.Int64() != 5 {
+		t.Fatalf("expected the higher fee of each field, got %+v", got)
+	}
+	if got := maxFees(bumped, Fees{GasPrice: big.NewInt(500)}); got.GasPrice != nil || got.GasFeeCap.Int64() != 110 {
+		t.Fatalf("expected the type of the replaced transaction to be kept, got %+v", got)
+	}
+}
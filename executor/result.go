@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	// ErrUnsupportedStep is returned for step types the executor does not know how to handle
+	ErrUnsupportedStep = errors.New("unsupported step type")
+
+	// ErrTxFailed is returned when a transaction is mined with a failed status
+	ErrTxFailed = errors.New("transaction failed")
+
+	// ErrReceiptTimeout is returned when a transaction is not mined within the receipt timeout
+	ErrReceiptTimeout = errors.New("transaction not mined within timeout")
+
+	// ErrTxCancelled is returned when a stuck transaction was replaced by a cancellation
+	ErrTxCancelled = errors.New("transaction cancelled")
+
+	// ErrNoProgress is returned when the step provider keeps returning an already executed step
+	ErrNoProgress = errors.New("chain activity did not advance")
+)
+
+// StepError wraps an error that occurred while executing a single step of a chain activity
+type StepError struct {
+	Number int
+	Type   service.ChainActivityStepType
+	Err    error
+}
+
+// Error implements the error interface
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s): %v", e.Number, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// StepResult describes the outcome of a single executed step
+type StepResult struct {
+	Number    int                           `json:"number"`
+	Type      service.ChainActivityStepType `json:"type"`
+	Label     map[string]string             `json:"label,omitempty"`
+	TxHash    *common.Hash                  `json:"txHash,omitempty"`
+	Receipt   *types.Receipt                `json:"receipt,omitempty"`
+	Replaced  []common.Hash                 `json:"replaced,omitempty"`
+	Signature hexutil.Bytes                 `json:"signature,omitempty"`
+}
+
+// Result lists the steps executed while running a chain activity, in order
+type Result struct {
+	Steps []StepResult `json:"steps"`
+}
+
+// TxHashes returns the hashes of all transactions sent, in order
+func (r *Result) TxHashes() []common.Hash {
+	var hashes []common.Hash
+	for _, step := range r.Steps {
+		if step.TxHash != nil {
+			hashes = append(hashes, *step.TxHash)
+		}
+	}
+	return hashes
+}
+
+// Signatures returns all signatures produced by sign request steps, in order
+func (r *Result) Signatures() []hexutil.Bytes {
+	var signatures []hexutil.Bytes
+	for _, step := range r.Steps {
+		if step.Signature != nil {
+			signatures = append(signatures, step.Signature)
+		}
+	}
+	return signatures
+}
+
+// Last returns the most recently executed step, or nil if no step has been executed
+func (r *Result) Last() *StepResult {
+	if len(r.Steps) == 0 {
+		return nil
+	}
+	return &r.Steps[len(r.Steps)-1]
+}
@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	// errorSelector is the selector of the Error(string) revert reason
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+	// panicSelector is the selector of the Panic(uint256) revert reason
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons describes the panic codes emitted by the solidity compiler
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// CallBackend is the subset of the Ethereum client used to simulate transactions.
+//
+// The go-ethereum *ethclient.Client implements this interface.
+type CallBackend interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// SimulationError is returned when a transaction would revert. Depending on the revert
+// data, Reason is set for Error(string), Panic for Panic(uint256) and ErrorName and Args
+// for custom errors found in the given ABIs.
+type SimulationError struct {
+	Reason    string
+	Panic     *big.Int
+	ErrorName string
+	Args      []interface{}
+	Data      hexutil.Bytes
+	Err       error
+}
+
+// Error implements the error interface
+func (e *SimulationError) Error() string {
+	switch {
+	case e.Reason != "":
+		return fmt.Sprintf("execution reverted: %s", e.Reason)
+	case e.Panic != nil:
+		reason := "unknown panic code"
+		if e.Panic.IsUint64() {
+			if known, ok := panicReasons[e.Panic.Uint64()]; ok {
+				reason = known
+			}
+		}
+		return fmt.Sprintf("execution reverted: panic 0x%x (%s)", e.Panic, reason)
+	case e.ErrorName != "":
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = fmt.Sprint(arg)
+		}
+		return fmt.Sprintf("execution reverted: %s(%s)", e.ErrorName, strings.Join(args, ", "))
+	case len(e.Data) > 0:
+		return fmt.Sprintf("execution reverted: %s", e.Data)
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return "execution reverted"
+}
+
+// Unwrap returns the error of the node, if any
+func (e *SimulationError) Unwrap() error {
+	return e.Err
+}
+
+// Simulate runs a prepared transaction against the latest block with eth_call. A
+// *SimulationError is returned if it would revert, custom errors are decoded with abis.
+func Simulate(ctx context.Context, backend CallBackend, from common.Address, preparedTx service.PreparedTx, abis ...abi.ABI) error {
+	to, value, data, err := decodeMethodParameters(preparedTx.MethodParameters)
+	if err != nil {
+		return err
+	}
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	}
+	if _, err := backend.CallContract(ctx, msg, nil); err != nil {
+		reverted, ok := revertData(err)
+		if !ok && !strings.Contains(err.Error(), "execution reverted") {
+			return fmt.Errorf("failed to simulate transaction: %w", err)
+		}
+		simErr := DecodeRevert(reverted, abis...)
+		simErr.Err = err
+		return simErr
+	}
+	return nil
+}
+
+// DecodeRevert decodes the revert data of a failed call. Custom errors are looked up in abis.
+func DecodeRevert(data []byte, abis ...abi.ABI) *SimulationError {
+	simErr := &SimulationError{Data: data}
+	if len(data) < 4 {
+		return simErr
+	}
+	selector, payload := data[:4], data[4:]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			simErr.Reason = reason
+		}
+	case bytes.Equal(selector, panicSelector):
+		if len(payload) == 32 {
+			simErr.Panic = new(big.Int).SetBytes(payload)
+		}
+	default:
+		for _, contractABI := range abis {
+			for name, abiErr := range contractABI.Errors {
+				if !bytes.Equal(selector, abiErr.ID[:4]) {
+					continue
+				}
+				args, err := abiErr.Inputs.Unpack(payload)
+				if err != nil {
+					continue
+				}
+				simErr.ErrorName = name
+				simErr.Args = args
+				return simErr
+			}
+		}
+	}
+	return simErr
+}
+
+// revertData extracts the revert data attached to a JSON-RPC error
+func revertData(err error) ([]byte, bool) {
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,230 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// word is a 32-byte ABI word holding the hex value v
+func word(v string) string {
+	return strings.Repeat("0", 64-len(v)) + v
+}
+
+func TestDecodeRevert(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantMsg   string
+		reason    string
+		panicCode string
+		errorName string
+		args      []interface{}
+	}{
+		{
+			// the revert reason example of the solidity documentation
+			name:    "error string",
+			data:    "0x08c379a0" + word("20") + word("1a") + "4e6f7420656e6f7567682045746865722070726f76696465642e000000000000",
+			reason:  "Not enough Ether provided.",
+			wantMsg: "execution reverted: Not enough Ether provided.",
+		},
+		{
+			name:      "generic panic",
+			data:      "0x4e487b71" + word("00"),
+			panicCode: "0x0",
+			wantMsg:   "execution reverted: panic 0x0 (generic compiler panic)",
+		},
+		{
+			name:      "assertion panic",
+			data:      "0x4e487b71" + word("01"),
+			panicCode: "0x1",
+			wantMsg:   "execution reverted: panic 0x1 (assertion failed)",
+		},
+		{
+			name:      "overflow panic",
+			data:      "0x4e487b71" + word("11"),
+			panicCode: "0x11",
+			wantMsg:   "execution reverted: panic 0x11 (arithmetic overflow or underflow)",
+		},
+		{
+			name:      "division panic",
+			data:      "0x4e487b71" + word("12"),
+			panicCode: "0x12",
+			wantMsg:   "execution reverted: panic 0x12 (division or modulo by zero)",
+		},
+		{
+			name:      "enum panic",
+			data:      "0x4e487b71" + word("21"),
+			panicCode: "0x21",
+			wantMsg:   "execution reverted: panic 0x21 (invalid enum value)",
+		},
+		{
+			name:      "storage encoding panic",
+			data:      "0x4e487b71" + word("22"),
+			panicCode: "0x22",
+			wantMsg:   "execution reverted: panic 0x22 (invalid storage byte array encoding)",
+		},
+		{
+			name:      "empty array panic",
+			data:      "0x4e487b71" + word("31"),
+			panicCode: "0x31",
+			wantMsg:   "execution reverted: panic 0x31 (pop on empty array)",
+		},
+		{
+			name:      "index panic",
+			data:      "0x4e487b71" + word("32"),
+			panicCode: "0x32",
+			wantMsg:   "execution reverted: panic 0x32 (array index out of bounds)",
+		},
+		{
+			name:      "memory panic",
+			data:      "0x4e487b71" + word("41"),
+			panicCode: "0x41",
+			wantMsg:   "execution reverted: panic 0x41 (out of memory)",
+		},
+		{
+			name:      "zero function panic",
+			data:      "0x4e487b71" + word("51"),
+			panicCode: "0x51",
+			wantMsg:   "execution reverted: panic 0x51 (call to zero-initialized function)",
+		},
+		{
+			name:      "unknown panic code",
+			data:      "0x4e487b71" + word("99"),
+			panicCode: "0x99",
+			wantMsg:   "execution reverted: panic 0x99 (unknown panic code)",
+		},
+		{
+			name:      "permit2 error with an argument",
+			data:      "0xf96fb071" + word("64"),
+			errorName: "InsufficientAllowance",
+			args:      []interface{}{big.NewInt(100)},
+			wantMsg:   "execution reverted: InsufficientAllowance(100)",
+		},
+		{
+			name:      "permit2 error without arguments",
+			data:      "0x756688fe",
+			errorName: "InvalidNonce",
+			args:      []interface{}{},
+			wantMsg:   "execution reverted: InvalidNonce()",
+		},
+		{
+			name:    "unknown selector",
+			data:    "0xdeadbeef" + word("01"),
+			wantMsg: "execution reverted: 0xdeadbeef" + word("01"),
+		},
+		{
+			name:    "short data",
+			data:    "0x08c379",
+			wantMsg: "execution reverted: 0x08c379",
+		},
+		{
+			name:    "truncated panic",
+			data:    "0x4e487b71" + "11",
+			wantMsg: "execution reverted: 0x4e487b7111",
+		},
+		{
+			name:    "truncated error string",
+			data:    "0x08c379a0" + word("20"),
+			wantMsg: "execution reverted: 0x08c379a0" + word("20"),
+		},
+		{
+			name:    "empty",
+			data:    "0x",
+			wantMsg: "execution reverted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simErr := DecodeRevert(hexutil.MustDecode(tt.data), calldata.ABIs()...)
+			if got := simErr.Error(); got != tt.wantMsg {
+				t.Fatalf("expected %q, got %q", tt.wantMsg, got)
+			}
+			if simErr.Reason != tt.reason {
+				t.Fatalf("expected reason %q, got %q", tt.reason, simErr.Reason)
+			}
+			panicCode := ""
+			if simErr.Panic != nil {
+				panicCode = hexutil.EncodeBig(simErr.Panic)
+			}
+			if panicCode != tt.panicCode {
+				t.Fatalf("expected panic code %q, got %q", tt.panicCode, panicCode)
+			}
+			if simErr.ErrorName != tt.errorName {
+				t.Fatalf("expected error %q, got %q", tt.errorName, simErr.ErrorName)
+			}
+			if len(simErr.Args) != len(tt.args) {
+				t.Fatalf("expected args %v, got %v", tt.args, simErr.Args)
+			}
+		})
+	}
+}
+
+// rpcError is a JSON-RPC error carrying revert data, like those of the go-ethereum client
+type rpcError struct {
+	data interface{}
+}
+
+func (e rpcError) Error() string          { return "execution reverted" }
+func (e rpcError) ErrorData() interface{} { return e.data }
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name    string
+		callErr error
+		wantMsg string
+		wantSim bool
+	}{
+		{name: "success"},
+		{
+			name:    "revert data",
+			callErr: rpcError{data: "0x4e487b71" + word("11")},
+			wantMsg: "execution reverted: panic 0x11 (arithmetic overflow or underflow)",
+			wantSim: true,
+		},
+		{
+			name:    "revert without data",
+			callErr: errors.New("execution reverted"),
+			wantMsg: "execution reverted",
+			wantSim: true,
+		},
+		{
+			name:    "node failure",
+			callErr: errors.New("connection refused"),
+			wantMsg: "failed to simulate transaction: connection refused",
+		},
+	}
+	preparedTx := service.PreparedTx{
+		MethodParameters: service.MethodParameters{To: "0x00000000000000000000000000000000000000aa", Value: "0", Calldata: "0x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend()
+			backend.callErr = tt.callErr
+			err := Simulate(context.Background(), backend, common.Address{}, preparedTx, calldata.ABIs()...)
+			if tt.callErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantMsg {
+				t.Fatalf("expected %q, got %v", tt.wantMsg, err)
+			}
+			var simErr *SimulationError
+			if errors.As(err, &simErr) != tt.wantSim {
+				t.Fatalf("expected a simulation error %v, got %T", tt.wantSim, err)
+			}
+			if !errors.Is(err, tt.callErr) {
+				t.Fatal("expected the node error to be wrapped")
+			}
+		})
+	}
+}
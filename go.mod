@@ -38,6 +38,8 @@ require (
 	github.com/apapsch/go-jsonmerge/v2 v2.0.0 // indirect
 	github.com/ethereum/go-ethereum v1.11.6
 	github.com/google/uuid v1.5.0 // indirect
+	github.com/tyler-smith/go-bip39 v1.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace github.com/ethereum/go-ethereum => ./go-ethereum
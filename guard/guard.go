@@ -0,0 +1,343 @@
+// Package guard checks prepared transactions against the known protocol contracts before
+// they are signed, protecting against a compromised or misconfigured API endpoint
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	// ErrUnknownContract is returned for transactions to a contract that is neither a protocol
+	// contract nor allow-listed
+	ErrUnknownContract = errors.New("transaction to unknown contract")
+
+	// ErrUnexpectedValue is returned for transactions sending ether to a call that does not expect it
+	ErrUnexpectedValue = errors.New("unexpected transaction value")
+
+	// ErrUnknownRecipient is returned for token approvals and transfers to an address that is
+	// neither a protocol contract nor allow-listed
+	ErrUnknownRecipient = errors.New("unknown spender or recipient")
+
+	// ErrUnsafeCall is returned for calls that cannot be checked, like DSProxy execute deploying
+	// code or without a target
+	ErrUnsafeCall = errors.New("unsafe call")
+)
+
+// RejectedError describes why a transaction was rejected. Kind is ErrUnknownContract,
+// ErrUnexpectedValue, ErrUnknownRecipient or ErrUnsafeCall.
+type RejectedError struct {
+	Kind   error
+	To     common.Address
+	Label  string
+	Value  *big.Int
+	Method string
+}
+
+// Error implements the error interface
+func (e *RejectedError) Error() string {
+	target := e.To.Hex()
+	if e.Label != "" {
+		target = fmt.Sprintf("%s (%s)", e.Label, e.To.Hex())
+	}
+	switch {
+	case errors.Is(e.Kind, ErrUnknownRecipient):
+		return fmt.Sprintf("%v: %s in %s", e.Kind, target, e.Method)
+	case errors.Is(e.Kind, ErrUnexpectedValue) && e.Method != "":
+		return fmt.Sprintf("%v: %s wei sent to %s calling %s", e.Kind, e.Value, target, e.Method)
+	case errors.Is(e.Kind, ErrUnexpectedValue):
+		return fmt.Sprintf("%v: %s wei sent to %s", e.Kind, e.Value, target)
+	case e.Method != "":
+		return fmt.Sprintf("%v: %s called on %s", e.Kind, e.Method, target)
+	}
+	return fmt.Sprintf("%v: %s", e.Kind, target)
+}
+
+// Unwrap returns the kind of rejection
+func (e *RejectedError) Unwrap() error {
+	return e.Kind
+}
+
+// Guard allows transactions to the protocol contracts returned by GetAllAddresses and to an
+// allow-list of token and other contracts. Token approvals and transfers must go to one of
+// these contracts or to an allow-listed recipient.
+type Guard struct {
+	addresses  *service.AddressBook
+	decoder    *calldata.Decoder
+	allowed    map[common.Address]string
+	recipients map[common.Address]string
+	payable    map[common.Address]bool
+}
+
+// Option allows setting custom parameters during construction
+type Option func(*Guard) error
+
+// New creates a Guard checking transactions against the protocol addresses of addresses
+func New(addresses *service.AddressBook, opts ...Option) (*Guard, error) {
+	if addresses == nil {
+		return nil, errors.New("address book is required")
+	}
+	g := Guard{
+		addresses:  addresses,
+		decoder:    calldata.NewDecoder(),
+		allowed:    make(map[common.Address]string),
+		recipients: make(map[common.Address]string),
+		payable:    make(map[common.Address]bool),
+	}
+	for _, o := range opts {
+		if err := o(&g); err != nil {
+			return nil, err
+		}
+	}
+	return &g, nil
+}
+
+// WithToken allows transactions to a token contract, such as approvals of collateral
+func WithToken(symbol string, address common.Address) Option {
+	return func(g *Guard) error {
+		g.allowed[address] = symbol
+		return nil
+	}
+}
+
+// WithContract allows transactions to a contract that is not a protocol contract, such as
+// the DSProxy of the account
+func WithContract(label string, address common.Address) Option {
+	return func(g *Guard) error {
+		g.allowed[address] = label
+		return nil
+	}
+}
+
+// WithRecipient allows token approvals and transfers to an address that is not a contract called
+// by transactions, such as the account itself
+func WithRecipient(label string, address common.Address) Option {
+	return func(g *Guard) error {
+		g.recipients[address] = label
+		return nil
+	}
+}
+
+// WithPayable allows sending ether to the given contracts whatever the call is
+func WithPayable(addresses ...common.Address) Option {
+	return func(g *Guard) error {
+		for _, address := range addresses {
+			g.payable[address] = true
+		}
+		return nil
+	}
+}
+
+// WithDecoder sets the decoder used to find out whether a call expects ether, defaults to
+// the embedded protocol ABIs
+func WithDecoder(decoder *calldata.Decoder) Option {
+	return func(g *Guard) error {
+		g.decoder = decoder
+		return nil
+	}
+}
+
+// Check rejects a prepared transaction to an unknown contract, or sending ether to a call that
+// does not expect it. Calls made through DSProxy execute must target a known contract as well,
+// and token and Permit2 approvals and transfers must go to a known address.
+func (g *Guard) Check(ctx context.Context, preparedTx service.PreparedTx) error {
+	params := preparedTx.MethodParameters
+	if !common.IsHexAddress(params.To) {
+		return fmt.Errorf("invalid recipient address: %q", params.To)
+	}
+	to := common.HexToAddress(params.To)
+	value := new(big.Int)
+	if params.Value != "" {
+		if _, ok := value.SetString(params.Value, 10); !ok || value.Sign() < 0 {
+			return fmt.Errorf("invalid value: %q", params.Value)
+		}
+	}
+	var data []byte
+	if params.Calldata != "" && params.Calldata != "0x" {
+		decoded, err := hexutil.Decode(params.Calldata)
+		if err != nil {
+			return fmt.Errorf("failed to decode calldata: %w", err)
+		}
+		data = decoded
+	}
+
+	label, err := g.label(ctx, to)
+	if err != nil {
+		return err
+	}
+	var method *calldata.Method
+	if len(data) > 0 {
+		method, _ = g.decoder.Decode(data)
+	}
+	methodName := ""
+	if method != nil {
+		methodName = method.Signature
+	} else if len(data) >= 4 {
+		methodName = hexutil.Encode(data[:4])
+	}
+	if label == "" {
+		return &RejectedError{Kind: ErrUnknownContract, To: to, Method: methodName}
+	}
+
+	if value.Sign() > 0 && !g.payable[to] && (method == nil || !method.AcceptsValue()) {
+		return &RejectedError{Kind: ErrUnexpectedValue, To: to, Label: label, Value: value, Method: methodName}
+	}
+	if method != nil {
+		return g.checkCall(ctx, to, method)
+	}
+	return nil
+}
+
+// checkCall checks a call to the contract at to and the calls nested in it. DSProxy execute must
+// target a known contract, which the nested call is then made on, and approvals and transfers
+// must go to a known spender or recipient.
+func (g *Guard) checkCall(ctx context.Context, to common.Address, method *calldata.Method) error {
+	callee := to
+	if method.Contract == "ds_proxy" && method.Name == "execute" {
+		target, err := g.checkExecute(ctx, to, method)
+		if err != nil {
+			return err
+		}
+		callee = target
+	}
+	for _, arg := range method.Args {
+		for _, call := range arg.Calls {
+			if err := g.checkCall(ctx, callee, call); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range recipientArgs(method) {
+		arg, ok := method.Arg(name)
+		if !ok {
+			continue
+		}
+		recipient, ok := arg.Value.(common.Address)
+		if !ok {
+			return &RejectedError{Kind: ErrUnsafeCall, To: to, Method: method.Signature}
+		}
+		if err := g.checkRecipient(ctx, recipient, method); err != nil {
+			return err
+		}
+	}
+	if method.Contract == "permit2" && method.Name == "permit" {
+		for _, arg := range method.Args {
+			spender, ok := permitSpender(arg.Value)
+			if !ok {
+				continue
+			}
+			if err := g.checkRecipient(ctx, spender, method); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkExecute checks that DSProxy execute calls a known contract and returns it. The overload
+// deploying code is rejected, as there is no telling what the code does.
+func (g *Guard) checkExecute(ctx context.Context, to common.Address, method *calldata.Method) (common.Address, error) {
+	arg, ok := method.Arg("target")
+	if !ok {
+		return common.Address{}, &RejectedError{Kind: ErrUnsafeCall, To: to, Method: method.Signature}
+	}
+	target, ok := arg.Value.(common.Address)
+	if !ok {
+		return common.Address{}, &RejectedError{Kind: ErrUnsafeCall, To: to, Method: method.Signature}
+	}
+	label, err := g.label(ctx, target)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if label == "" {
+		inner := ""
+		if data, ok := method.Arg("data"); ok && len(data.Calls) == 1 {
+			inner = data.Calls[0].Signature
+		}
+		return common.Address{}, &RejectedError{Kind: ErrUnknownContract, To: target, Method: inner}
+	}
+	return target, nil
+}
+
+// checkRecipient rejects a spender or recipient that is neither a known contract nor an
+// allow-listed recipient
+func (g *Guard) checkRecipient(ctx context.Context, recipient common.Address, method *calldata.Method) error {
+	if _, ok := g.recipients[recipient]; ok {
+		return nil
+	}
+	label, err := g.label(ctx, recipient)
+	if err != nil {
+		return err
+	}
+	if label == "" {
+		return &RejectedError{Kind: ErrUnknownRecipient, To: recipient, Method: method.Signature}
+	}
+	return nil
+}
+
+// recipientArgs returns the names of the arguments of a token or Permit2 call that receive an
+// allowance or tokens
+func recipientArgs(method *calldata.Method) []string {
+	switch method.Contract {
+	case "erc20":
+		switch method.Name {
+		case "approve", "permit":
+			return []string{"spender"}
+		case "transfer", "transferFrom":
+			return []string{"to"}
+		}
+	case "permit2":
+		switch method.Name {
+		case "approve":
+			return []string{"spender"}
+		case "transferFrom":
+			return []string{"to"}
+		}
+	}
+	return nil
+}
+
+// permitSpender returns the spender of the PermitSingle or PermitBatch argument of Permit2 permit
+func permitSpender(value interface{}) (common.Address, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Struct {
+		return common.Address{}, false
+	}
+	field := v.FieldByName("Spender")
+	if !field.IsValid() {
+		return common.Address{}, false
+	}
+	spender, ok := field.Interface().(common.Address)
+	return spender, ok
+}
+
+// label returns the label of a known contract, or an empty string for unknown contracts
+func (g *Guard) label(ctx context.Context, address common.Address) (string, error) {
+	if label, ok := g.allowed[address]; ok {
+		if label == "" {
+			label = address.Hex()
+		}
+		return label, nil
+	}
+	label, ok, err := g.addresses.Label(ctx, address.Hex())
+	if err != nil {
+		return "", fmt.Errorf("failed to get protocol addresses: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+	if strings.TrimSpace(label) == "" {
+		label = address.Hex()
+	}
+	return label, nil
+}
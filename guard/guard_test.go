@@ -0,0 +1,234 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	proxyActions = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	permit2      = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+	dai          = common.HexToAddress("0x00000000000000000000000000000000000000d1")
+	proxy        = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+	account      = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+	attacker     = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+// newAddressBook returns an address book backed by a stand-in service API knowing the proxy
+// actions and Permit2
+func newAddressBook(t *testing.T) *service.AddressBook {
+	t.Helper()
+	response := service.AddressResponse{Data: []service.Address{
+		{Address: proxyActions.Hex(), Label: "ProxyActions"},
+		{Address: permit2.Hex(), Label: "Permit2"},
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := service.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service.NewAddressBook(client, 0)
+}
+
+// pack encodes a call of an embedded contract ABI
+func pack(t *testing.T, contract, method string, args ...interface{}) []byte {
+	t.Helper()
+	for _, c := range calldata.Embedded() {
+		if c.Name != contract {
+			continue
+		}
+		data, err := c.ABI.Pack(method, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	t.Fatalf("no embedded abi %s", contract)
+	return nil
+}
+
+// permitDetails and permitSingle mirror the PermitSingle struct of Permit2
+type permitDetails struct {
+	Token      common.Address
+	Amount     *big.Int
+	Expiration *big.Int
+	Nonce      *big.Int
+}
+
+type permitSingle struct {
+	Details     permitDetails
+	Spender     common.Address
+	SigDeadline *big.Int
+}
+
+func permit(t *testing.T, spender common.Address) []byte {
+	t.Helper()
+	single := permitSingle{
+		Details:     permitDetails{Token: dai, Amount: big.NewInt(1), Expiration: big.NewInt(1), Nonce: big.NewInt(0)},
+		Spender:     spender,
+		SigDeadline: big.NewInt(1),
+	}
+	return pack(t, "permit2", "permit", account, single, []byte{1})
+}
+
+func preparedTx(to common.Address, value string, data []byte) service.PreparedTx {
+	encoded := "0x"
+	if len(data) > 0 {
+		encoded = hexutil.Encode(data)
+	}
+	return service.PreparedTx{
+		GasUseEstimate:   100000,
+		MethodParameters: service.MethodParameters{To: to.Hex(), Value: value, Calldata: encoded},
+	}
+}
+
+func TestGuardCheck(t *testing.T) {
+	amount := big.NewInt(1000)
+	tests := []struct {
+		name    string
+		tx      func(t *testing.T) service.PreparedTx
+		wantErr error
+	}{
+		{
+			name: "protocol contract",
+			tx:   func(t *testing.T) service.PreparedTx { return preparedTx(proxyActions, "0", nil) },
+		},
+		{
+			name:    "unknown contract",
+			tx:      func(t *testing.T) service.PreparedTx { return preparedTx(attacker, "0", nil) },
+			wantErr: ErrUnknownContract,
+		},
+		{
+			name: "ether to a call that does not expect it",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "1", pack(t, "erc20", "approve", proxyActions, amount))
+			},
+			wantErr: ErrUnexpectedValue,
+		},
+		{
+			name: "approval of a protocol contract",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "0", pack(t, "erc20", "approve", proxyActions, amount))
+			},
+		},
+		{
+			name: "approval of an unknown spender",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "0", pack(t, "erc20", "approve", attacker, amount))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "transfer to an allow-listed recipient",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "0", pack(t, "erc20", "transfer", account, amount))
+			},
+		},
+		{
+			name: "transfer to an unknown recipient",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "0", pack(t, "erc20", "transfer", attacker, amount))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "transferFrom to an unknown recipient",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(dai, "0", pack(t, "erc20", "transferFrom", account, attacker, amount))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "execute on a protocol contract",
+			tx: func(t *testing.T) service.PreparedTx {
+				inner := pack(t, "erc20", "approve", permit2, amount)
+				return preparedTx(proxy, "0", pack(t, "ds_proxy", "execute", proxyActions, inner))
+			},
+		},
+		{
+			name: "execute on an unknown contract",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(proxy, "0", pack(t, "ds_proxy", "execute", attacker, []byte{}))
+			},
+			wantErr: ErrUnknownContract,
+		},
+		{
+			name: "execute approving an unknown spender",
+			tx: func(t *testing.T) service.PreparedTx {
+				inner := pack(t, "erc20", "approve", attacker, amount)
+				return preparedTx(proxy, "0", pack(t, "ds_proxy", "execute", proxyActions, inner))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "execute deploying code",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(proxy, "0", pack(t, "ds_proxy", "execute0", []byte{0x60, 0x00}, []byte{}))
+			},
+			wantErr: ErrUnsafeCall,
+		},
+		{
+			name: "permit2 approval of an unknown spender",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(permit2, "0", pack(t, "permit2", "approve", dai, attacker, amount, big.NewInt(1)))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "permit2 transfer to an unknown recipient",
+			tx: func(t *testing.T) service.PreparedTx {
+				return preparedTx(permit2, "0", pack(t, "permit2", "transferFrom", account, attacker, amount, dai))
+			},
+			wantErr: ErrUnknownRecipient,
+		},
+		{
+			name: "permit2 permit for a protocol contract",
+			tx:   func(t *testing.T) service.PreparedTx { return preparedTx(permit2, "0", permit(t, proxyActions)) },
+		},
+		{
+			name:    "permit2 permit for an unknown spender",
+			tx:      func(t *testing.T) service.PreparedTx { return preparedTx(permit2, "0", permit(t, attacker)) },
+			wantErr: ErrUnknownRecipient,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(newAddressBook(t),
+				WithToken("DAI", dai),
+				WithContract("proxy", proxy),
+				WithRecipient("account", account),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = g.Check(context.Background(), tt.tx(t))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var rejected *RejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("expected a *RejectedError, got %T", err)
+			}
+		})
+	}
+}
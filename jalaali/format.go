@@ -0,0 +1,147 @@
+package jalaali
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts for Format, using the reference time of the time package
+const (
+	RFC3339  = time.RFC3339
+	DateOnly = "2006/01/02"
+	DateTime = "2006/01/02 15:04:05"
+)
+
+// tokens are the layout elements of the time package, longest first where they share a prefix
+var tokens = []string{
+	"January", "Jan", "Monday", "Mon", "MST",
+	"2006", "002",
+	"Z07:00:00", "Z070000", "Z07:00", "Z0700", "Z07",
+	"-07:00:00", "-070000", "-07:00", "-0700", "-07",
+	"01", "02", "03", "04", "05", "06", "15", "_2",
+	"PM", "pm",
+	"1", "2", "3", "4", "5",
+}
+
+// Format returns t formatted with a layout of the time package, with the year, month and day
+// elements replaced by the Jalaali date of t. Month names are the English transliterations,
+// e.g. "2 January 2006" formats as "1 Farvardin 1403".
+func Format(t time.Time, layout string) string {
+	year, month, day := DateOf(t)
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		if n := fractionLength(layout[i:]); n > 0 {
+			b.WriteString(t.Format(layout[i : i+n]))
+			i += n
+			continue
+		}
+		token := ""
+		for _, candidate := range tokens {
+			if strings.HasPrefix(layout[i:], candidate) {
+				token = candidate
+				break
+			}
+		}
+		if token == "" {
+			b.WriteByte(layout[i])
+			i++
+			continue
+		}
+		switch token {
+		case "2006":
+			b.WriteString(strconv.Itoa(year))
+		case "06":
+			fmt.Fprintf(&b, "%02d", year%100)
+		case "January", "Jan":
+			b.WriteString(month.String())
+		case "01":
+			fmt.Fprintf(&b, "%02d", int(month))
+		case "1":
+			b.WriteString(strconv.Itoa(int(month)))
+		case "02":
+			fmt.Fprintf(&b, "%02d", day)
+		case "_2":
+			fmt.Fprintf(&b, "%2d", day)
+		case "2":
+			b.WriteString(strconv.Itoa(day))
+		case "002":
+			fmt.Fprintf(&b, "%03d", dayOfYear(month, day))
+		default:
+			b.WriteString(t.Format(token))
+		}
+		i += len(token)
+	}
+	return b.String()
+}
+
+// Parse parses a Jalaali date with an optional time of day, such as "1403/01/01",
+// "1403-01-01 12:30:00" or the RFC 3339 form "1403-01-01T12:30:00+03:30" used by the APIs.
+// Values without a zone are interpreted in loc.
+func Parse(value string, loc *time.Location) (time.Time, error) {
+	text := strings.TrimSpace(value)
+	date, clock := text, ""
+	if i := strings.IndexAny(text, "T "); i >= 0 {
+		date, clock = text[:i], text[i:]
+	}
+	parts := strings.FieldsFunc(date, func(r rune) bool { return r == '-' || r == '/' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid jalaali date: %q", value)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid jalaali date: %q", value)
+		}
+		numbers[i] = n
+	}
+	year, month, day := numbers[0], Month(numbers[1]), numbers[2]
+	if err := checkYear(year); err != nil {
+		return time.Time{}, fmt.Errorf("invalid jalaali date: %q: %w", value, err)
+	}
+	if !IsValid(year, month, day) {
+		return time.Time{}, fmt.Errorf("invalid jalaali date: %q", value)
+	}
+	if clock == "" {
+		return Date(year, month, day, 0, 0, 0, 0, loc), nil
+	}
+
+	// parse the time of day and the zone on an arbitrary date, then move it to the Jalaali date
+	var t time.Time
+	var err error
+	for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err = time.ParseInLocation(layout, "2000-01-01"+clock, loc); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid jalaali time: %q", value)
+	}
+	return Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), nil
+}
+
+// dayOfYear returns the day of the Jalaali year, Farvardin 1 = 1
+func dayOfYear(month Month, day int) int {
+	if month <= Mehr {
+		return (int(month)-1)*31 + day
+	}
+	return 6*31 + (int(month)-7)*30 + day
+}
+
+// fractionLength returns the length of a fractional seconds element at the start of layout,
+// like ".000" or ",999", or 0
+func fractionLength(layout string) int {
+	if len(layout) < 2 || (layout[0] != '.' && layout[0] != ',') || (layout[1] != '0' && layout[1] != '9') {
+		return 0
+	}
+	n := 1
+	for n < len(layout) && layout[n] == layout[1] {
+		n++
+	}
+	if n < len(layout) && layout[n] >= '0' && layout[n] <= '9' {
+		return 0
+	}
+	return n
+}
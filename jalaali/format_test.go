@@ -0,0 +1,85 @@
+package jalaali
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	instant := time.Date(2024, time.October, 16, 9, 5, 7, 250000000, time.UTC).In(Tehran())
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{layout: RFC3339, want: "1403-07-25T12:35:07+03:30"},
+		{layout: DateOnly, want: "1403/07/25"},
+		{layout: DateTime, want: "1403/07/25 12:35:07"},
+		{layout: "2 January 2006", want: "25 Mehr 1403"},
+		{layout: "Jan _2, 06", want: "Mehr 25, 03"},
+		{layout: "1/2 002", want: "7/25 211"},
+		{layout: "15:04:05.000 PM", want: "12:35:07.250 PM"},
+		{layout: "Monday", want: "Wednesday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			if got := Format(instant, tt.layout); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tehran := Tehran()
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr error
+		errOnly bool
+	}{
+		{value: "1403/01/01", want: time.Date(2024, time.March, 20, 0, 0, 0, 0, tehran)},
+		{value: " 1403-1-1 ", want: time.Date(2024, time.March, 20, 0, 0, 0, 0, tehran)},
+		{value: "1403-07-25 12:35", want: time.Date(2024, time.October, 16, 12, 35, 0, 0, tehran)},
+		{value: "1403-07-25 12:35:07", want: time.Date(2024, time.October, 16, 12, 35, 7, 0, tehran)},
+		{value: "1403-07-25T12:35:07+03:30", want: time.Date(2024, time.October, 16, 9, 5, 7, 0, time.UTC)},
+		{value: "1403-07-25T09:05:07Z", want: time.Date(2024, time.October, 16, 9, 5, 7, 0, time.UTC)},
+		{value: "1403/12/30", want: time.Date(2025, time.March, 20, 0, 0, 0, 0, tehran)},
+		{value: "1402/12/30", errOnly: true},
+		{value: "1403/13/01", errOnly: true},
+		{value: "1403/07/32", errOnly: true},
+		{value: "1403/07", errOnly: true},
+		{value: "1403/07/xx", errOnly: true},
+		{value: "1403-07-25T25:00:00", errOnly: true},
+		{value: "3178/01/01", wantErr: ErrOutOfRange},
+		{value: "9999/01/01", wantErr: ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value, tehran)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.errOnly:
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	instant := time.Date(2025, time.March, 20, 23, 59, 59, 0, time.UTC)
+	parsed, err := Parse(Format(instant, RFC3339), time.UTC)
+	if err != nil || !parsed.Equal(instant) {
+		t.Fatalf("expected %s back, got %s, %v", instant, parsed, err)
+	}
+}
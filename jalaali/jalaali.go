@@ -0,0 +1,270 @@
+// Package jalaali converts between the Gregorian and the Jalaali (Persian) calendars, and
+// formats and parses Jalaali dates. Conversions use the 2820-year-free algorithm of Kazimierz
+// M. Borkowski, which is exact for the Jalaali years -61 to 3177.
+package jalaali
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The Jalaali years for which conversions are exact
+const (
+	MinYear = -61
+	MaxYear = 3177
+)
+
+// ErrOutOfRange is returned for Jalaali years outside MinYear to MaxYear
+var ErrOutOfRange = errors.New("jalaali year out of range")
+
+// Month is a month of the Jalaali year, Farvardin = 1
+type Month int
+
+// Months of the Jalaali year
+const (
+	Farvardin Month = 1 + iota
+	Ordibehesht
+	Khordad
+	Tir
+	Mordad
+	Shahrivar
+	Mehr
+	Aban
+	Azar
+	Dey
+	Bahman
+	Esfand
+)
+
+var monthNames = [...]string{
+	"Farvardin", "Ordibehesht", "Khordad", "Tir", "Mordad", "Shahrivar",
+	"Mehr", "Aban", "Azar", "Dey", "Bahman", "Esfand",
+}
+
+var persianMonthNames = [...]string{
+	"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور",
+	"مهر", "آبان", "آذر", "دی", "بهمن", "اسفند",
+}
+
+// String returns the English transliteration of the month name, e.g. "Farvardin"
+func (m Month) String() string {
+	if m < Farvardin || m > Esfand {
+		return fmt.Sprintf("%%!Month(%d)", int(m))
+	}
+	return monthNames[m-1]
+}
+
+// Persian returns the month name in Persian, e.g. "فروردین"
+func (m Month) Persian() string {
+	if m < Farvardin || m > Esfand {
+		return m.String()
+	}
+	return persianMonthNames[m-1]
+}
+
+// breaks are the Jalaali years starting a new leap cycle
+var breaks = [...]int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+var (
+	tehranOnce sync.Once
+	tehran     *time.Location
+)
+
+// Tehran returns the Asia/Tehran location, or a fixed +03:30 zone if the time zone database is
+// not available. Iran has not observed daylight saving time since 2022.
+func Tehran() *time.Location {
+	tehranOnce.Do(func() {
+		loc, err := time.LoadLocation("Asia/Tehran")
+		if err != nil {
+			loc = time.FixedZone("+0330", 3*60*60+30*60)
+		}
+		tehran = loc
+	})
+	return tehran
+}
+
+// Date returns the time corresponding to the Jalaali date and the time of day in loc. Like
+// time.Date, month and day values outside their usual ranges are normalized, so that
+// Esfand 30 of a common year is Farvardin 1 of the next year. The result is not exact outside
+// the years MinYear to MaxYear, which ToGregorian rejects.
+func Date(year int, month Month, day, hour, min, sec, nsec int, loc *time.Location) time.Time {
+	m := int(month) - 1
+	year += floorDiv(m, 12)
+	m = m - floorDiv(m, 12)*12 + 1
+	jdn := jalaaliToDay(year, m, 1) + day - 1
+	gy, gm, gd := dayToGregorian(jdn)
+	return time.Date(gy, time.Month(gm), gd, hour, min, sec, nsec, loc)
+}
+
+// DateOf returns the Jalaali date of t in the location of t. The result is not exact outside
+// the years MinYear to MaxYear, which ToJalaali rejects.
+func DateOf(t time.Time) (year int, month Month, day int) {
+	gy, gm, gd := t.Date()
+	jy, jm, jd := dayToJalaali(gregorianToDay(gy, int(gm), gd))
+	return jy, Month(jm), jd
+}
+
+// ToJalaali converts a Gregorian date to a Jalaali date, or returns ErrOutOfRange if the
+// Jalaali year is outside MinYear to MaxYear
+func ToJalaali(gy int, gm time.Month, gd int) (year int, month Month, day int, err error) {
+	jy, jm, jd := dayToJalaali(gregorianToDay(gy, int(gm), gd))
+	if err := checkYear(jy); err != nil {
+		return 0, 0, 0, err
+	}
+	return jy, Month(jm), jd, nil
+}
+
+// ToGregorian converts a Jalaali date to a Gregorian date, or returns ErrOutOfRange if the
+// year is outside MinYear to MaxYear
+func ToGregorian(jy int, jm Month, jd int) (year int, month time.Month, day int, err error) {
+	if err := checkYear(jy); err != nil {
+		return 0, 0, 0, err
+	}
+	gy, gm, gd := dayToGregorian(jalaaliToDay(jy, int(jm), jd))
+	return gy, time.Month(gm), gd, nil
+}
+
+// IsLeap reports whether the Jalaali year has 366 days
+func IsLeap(year int) bool {
+	leap, _, _ := calendar(year)
+	return leap == 0
+}
+
+// MonthLength returns the number of days of a Jalaali month
+func MonthLength(year int, month Month) int {
+	switch {
+	case month <= Shahrivar:
+		return 31
+	case month <= Bahman:
+		return 30
+	case IsLeap(year):
+		return 30
+	}
+	return 29
+}
+
+// IsValid reports whether the Jalaali date exists
+func IsValid(year int, month Month, day int) bool {
+	return checkYear(year) == nil &&
+		month >= Farvardin && month <= Esfand &&
+		day >= 1 && day <= MonthLength(year, month)
+}
+
+// MonthRange returns the start of the Jalaali month of t and the start of the next month, in
+// the location of t
+func MonthRange(t time.Time) (start, end time.Time) {
+	year, month, _ := DateOf(t)
+	start = Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	end = Date(year, month+1, 1, 0, 0, 0, 0, t.Location())
+	return start, end
+}
+
+// YearRange returns the start of the Jalaali year of t, Nowruz, and the start of the next
+// year, in the location of t
+func YearRange(t time.Time) (start, end time.Time) {
+	year, _, _ := DateOf(t)
+	start = Date(year, Farvardin, 1, 0, 0, 0, 0, t.Location())
+	end = Date(year+1, Farvardin, 1, 0, 0, 0, 0, t.Location())
+	return start, end
+}
+
+// checkYear returns ErrOutOfRange if the conversions are not exact for the Jalaali year
+func checkYear(year int) error {
+	if year < MinYear || year > MaxYear {
+		return fmt.Errorf("%w: %d", ErrOutOfRange, year)
+	}
+	return nil
+}
+
+// calendar returns the number of years since the last leap year (0 to 4), the Gregorian year
+// starting in the Jalaali year and the day of March of Farvardin 1
+func calendar(jy int) (leap, gy, march int) {
+	gy = jy + 621
+	leapJ := -14
+	jp := breaks[0]
+	jump := 0
+	for i := 1; i < len(breaks); i++ {
+		jm := breaks[i]
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := jy - jp
+
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return leap, gy, march
+}
+
+// jalaaliToDay returns the Julian day number of a Jalaali date
+func jalaaliToDay(jy, jm, jd int) int {
+	_, gy, march := calendar(jy)
+	return gregorianToDay(gy, 3, march) + (jm-1)*31 - jm/7*(jm-7) + jd - 1
+}
+
+// dayToJalaali returns the Jalaali date of a Julian day number
+func dayToJalaali(jdn int) (jy, jm, jd int) {
+	gy, _, _ := dayToGregorian(jdn)
+	jy = gy - 621
+	leap, _, march := calendar(jy)
+	k := jdn - gregorianToDay(gy, 3, march)
+	if k >= 0 {
+		if k <= 185 {
+			return jy, 1 + k/31, k%31 + 1
+		}
+		k -= 186
+	} else {
+		jy--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+	return jy, 7 + k/30, k%30 + 1
+}
+
+// gregorianToDay returns the Julian day number of a Gregorian date
+func gregorianToDay(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+// dayToGregorian returns the Gregorian date of a Julian day number
+func dayToGregorian(jdn int) (gy, gm, gd int) {
+	j := 4*jdn + 139361631
+	j += (4*jdn+183187720)/146097*3/4*4 - 3908
+	i := j%1461/4*5 + 308
+	gd = i%153/5 + 1
+	gm = i/153%12 + 1
+	gy = j/1461 - 100100 + (8-gm)/6
+	return gy, gm, gd
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,157 @@
+package jalaali
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConversion(t *testing.T) {
+	tests := []struct {
+		name      string
+		jy        int
+		jm        Month
+		jd        int
+		gregorian string
+	}{
+		{name: "nowruz 1403", jy: 1403, jm: Farvardin, jd: 1, gregorian: "2024-03-20"},
+		{name: "nowruz 1402", jy: 1402, jm: Farvardin, jd: 1, gregorian: "2023-03-21"},
+		{name: "nowruz 1354", jy: 1354, jm: Farvardin, jd: 1, gregorian: "1975-03-21"},
+		{name: "last day of leap year 1399", jy: 1399, jm: Esfand, jd: 30, gregorian: "2021-03-20"},
+		{name: "last day of leap year 1403", jy: 1403, jm: Esfand, jd: 30, gregorian: "2025-03-20"},
+		{name: "last day of common year 1402", jy: 1402, jm: Esfand, jd: 29, gregorian: "2024-03-19"},
+		{name: "first day of mehr", jy: 1403, jm: Mehr, jd: 1, gregorian: "2024-09-22"},
+		{name: "last day of shahrivar", jy: 1403, jm: Shahrivar, jd: 31, gregorian: "2024-09-21"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := time.Parse("2006-01-02", tt.gregorian)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gy, gm, gd, err := ToGregorian(tt.jy, tt.jm, tt.jd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wy, wm, wd := want.Date(); gy != wy || gm != wm || gd != wd {
+				t.Fatalf("expected %s, got %d-%02d-%02d", tt.gregorian, gy, gm, gd)
+			}
+			jy, jm, jd, err := ToJalaali(gy, gm, gd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if jy != tt.jy || jm != tt.jm || jd != tt.jd {
+				t.Fatalf("expected %d %s %d, got %d %s %d", tt.jd, tt.jm, tt.jy, jd, jm, jy)
+			}
+		})
+	}
+}
+
+// TestYears checks every supported year: Nowruz converts back, the years are 365 or 366 days
+// long as IsLeap reports, and the day before Nowruz is the last day of Esfand
+func TestYears(t *testing.T) {
+	nowruz := func(year int) time.Time {
+		t.Helper()
+		gy, gm, gd, err := ToGregorian(year, Farvardin, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(gy, gm, gd, 0, 0, 0, 0, time.UTC)
+	}
+	start := nowruz(MinYear)
+	for year := MinYear; year <= MaxYear; year++ {
+		jy, jm, jd, err := ToJalaali(start.Date())
+		if err != nil || jy != year || jm != Farvardin || jd != 1 {
+			t.Fatalf("expected Nowruz %d at %s, got %d %s %d, %v", year, start.Format("2006-01-02"), jd, jm, jy, err)
+		}
+		last := start.AddDate(0, 0, -1)
+		if jy, jm, jd, err := ToJalaali(last.Date()); year > MinYear && (err != nil || jy != year-1 || jm != Esfand || jd != MonthLength(year-1, Esfand)) {
+			t.Fatalf("expected the last day of %d at %s, got %d %s %d, %v", year-1, last.Format("2006-01-02"), jd, jm, jy, err)
+		}
+		if year == MaxYear {
+			break
+		}
+		next := nowruz(year + 1)
+		days := int(next.Sub(start).Hours() / 24)
+		if want := map[bool]int{false: 365, true: 366}[IsLeap(year)]; days != want {
+			t.Fatalf("expected year %d to have %d days, got %d", year, want, days)
+		}
+		start = next
+	}
+}
+
+func TestIsLeap(t *testing.T) {
+	for year, want := range map[int]bool{1395: true, 1399: true, 1400: false, 1402: false, 1403: true, 1404: false, 1408: true} {
+		if IsLeap(year) != want {
+			t.Fatalf("expected IsLeap(%d) to be %v", year, want)
+		}
+	}
+	if !IsValid(1403, Esfand, 30) || IsValid(1402, Esfand, 30) {
+		t.Fatal("expected Esfand 30 to exist only in leap years")
+	}
+	if MonthLength(1403, Farvardin) != 31 || MonthLength(1403, Mehr) != 30 || MonthLength(1402, Esfand) != 29 {
+		t.Fatal("unexpected month length")
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	for _, year := range []int{MinYear - 1, MaxYear + 1, -1000, 10000} {
+		if _, _, _, err := ToGregorian(year, Farvardin, 1); !errors.Is(err, ErrOutOfRange) {
+			t.Fatalf("expected %v for year %d, got %v", ErrOutOfRange, year, err)
+		}
+		if IsValid(year, Farvardin, 1) {
+			t.Fatalf("expected year %d to be invalid", year)
+		}
+	}
+	for _, date := range []time.Time{
+		time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(560, time.March, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(3799, time.June, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if _, _, _, err := ToJalaali(date.Date()); !errors.Is(err, ErrOutOfRange) {
+			t.Fatalf("expected %v for %s, got %v", ErrOutOfRange, date.Format("2006-01-02"), err)
+		}
+	}
+	for _, year := range []int{MinYear, MaxYear} {
+		if _, _, _, err := ToGregorian(year, Esfand, 29); err != nil {
+			t.Fatalf("expected year %d to be supported, got %v", year, err)
+		}
+	}
+}
+
+func TestDate(t *testing.T) {
+	tehran := Tehran()
+	if got, want := Date(1402, Esfand, 30, 0, 0, 0, 0, tehran), Date(1403, Farvardin, 1, 0, 0, 0, 0, tehran); !got.Equal(want) {
+		t.Fatalf("expected Esfand 30 of a common year to be Nowruz, got %s", got)
+	}
+	if got, want := Date(1403, Esfand+1, 1, 0, 0, 0, 0, tehran), Date(1404, Farvardin, 1, 0, 0, 0, 0, tehran); !got.Equal(want) {
+		t.Fatalf("expected month 13 to be Farvardin of the next year, got %s", got)
+	}
+	if got, want := Date(1403, Farvardin, 0, 0, 0, 0, 0, tehran), Date(1402, Esfand, 29, 0, 0, 0, 0, tehran); !got.Equal(want) {
+		t.Fatalf("expected day 0 to be the last day of the previous month, got %s", got)
+	}
+
+	// 21:00 UTC is already Nowruz in Tehran
+	instant := time.Date(2024, time.March, 19, 21, 0, 0, 0, time.UTC)
+	if year, month, day := DateOf(instant.In(tehran)); year != 1403 || month != Farvardin || day != 1 {
+		t.Fatalf("expected 1 Farvardin 1403 in Tehran, got %d %s %d", day, month, year)
+	}
+	if year, month, day := DateOf(instant); year != 1402 || month != Esfand || day != 29 {
+		t.Fatalf("expected 29 Esfand 1402 in UTC, got %d %s %d", day, month, year)
+	}
+
+	start, end := MonthRange(time.Date(2024, time.October, 16, 12, 0, 0, 0, tehran))
+	if !start.Equal(time.Date(2024, time.September, 22, 0, 0, 0, 0, tehran)) || !end.Equal(time.Date(2024, time.October, 22, 0, 0, 0, 0, tehran)) {
+		t.Fatalf("expected Mehr 1403, got [%s, %s)", start, end)
+	}
+	start, end = YearRange(time.Date(2025, time.March, 20, 12, 0, 0, 0, tehran))
+	if !start.Equal(time.Date(2024, time.March, 20, 0, 0, 0, 0, tehran)) || !end.Equal(time.Date(2025, time.March, 21, 0, 0, 0, 0, tehran)) {
+		t.Fatalf("expected the leap year 1403, got [%s, %s)", start, end)
+	}
+}
+
+func TestMonth(t *testing.T) {
+	if Farvardin.String() != "Farvardin" || Esfand.Persian() != "اسفند" || Month(13).String() != "%!Month(13)" {
+		t.Fatal("unexpected month name")
+	}
+}
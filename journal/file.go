@@ -0,0 +1,125 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// activityIDPattern restricts activity IDs to safe file names
+var activityIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// FileStore keeps the journal of every activity in a JSON file of its own directory.
+// Files are replaced atomically, so a crash never leaves a partially written journal.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore writing to dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements Store
+func (s *FileStore) Load(_ context.Context, activityID string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(activityID)
+}
+
+// Save implements Store
+func (s *FileStore) Save(_ context.Context, activityID string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(activityID)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range entries {
+		if entries[i].Step == entry.Step {
+			entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Step < entries[j].Step
+		})
+	}
+	return s.write(activityID, entries)
+}
+
+// load reads the journal of an activity, s.mu must be held
+func (s *FileStore) load(activityID string) ([]Entry, error) {
+	path, err := s.path(activityID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode journal %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// write replaces the journal of an activity, s.mu must be held
+func (s *FileStore) write(activityID string, entries []Entry) error {
+	path, err := s.path(activityID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, activityID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+	return nil
+}
+
+// path returns the journal file of an activity
+func (s *FileStore) path(activityID string) (string, error) {
+	if !activityIDPattern.MatchString(activityID) {
+		return "", fmt.Errorf("invalid activity id: %q", activityID)
+	}
+	return filepath.Join(s.dir, activityID+".json"), nil
+}
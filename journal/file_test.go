@@ -0,0 +1,155 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.Load(ctx, "activity-1")
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries for an unknown activity, got %v, %v", entries, err)
+	}
+
+	saves := []Entry{
+		{Step: 2, Type: service.ChainActivityStepTypePersonalSignRequest, Status: StatusPending},
+		{Step: 1, Type: service.ChainActivityStepTypePreparedTx, Status: StatusSent, SignedTxs: []hexutil.Bytes{{1, 2}}},
+		{Step: 1, Type: service.ChainActivityStepTypePreparedTx, Status: StatusConfirmed, SignedTxs: []hexutil.Bytes{{1, 2}}},
+	}
+	for _, entry := range saves {
+		if err := store.Save(ctx, "activity-1", entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err = store.Load(ctx, "activity-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Step != 1 || entries[1].Step != 2 {
+		t.Fatalf("expected steps 1 and 2 in order, got %+v", entries)
+	}
+	if entries[0].Status != StatusConfirmed || len(entries[0].SignedTxs) != 1 {
+		t.Fatalf("expected step 1 to be replaced, got %+v", entries[0])
+	}
+
+	// activities are journaled separately
+	if entries, err := store.Load(ctx, "activity-2"); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries for another activity, got %v, %v", entries, err)
+	}
+}
+
+func TestFileStoreActivityID(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{id: "0c1f5b6e-7d0a-4e0b-9a43-2f4c1f0b9d11"},
+		{id: "vault_42.open"},
+		{id: "", wantErr: true},
+		{id: "../escape", wantErr: true},
+		{id: "a/b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			err := store.Save(context.Background(), tt.id, Entry{Step: 1})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "activity-1.json"), []byte(`[{"step": 1`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(context.Background(), "activity-1"); err == nil {
+		t.Fatal("expected an error for a corrupt journal")
+	}
+	if err := store.Save(context.Background(), "activity-1", Entry{Step: 2}); err == nil {
+		t.Fatal("expected a corrupt journal not to be overwritten")
+	}
+}
+
+// TestFileStoreAtomic reads the journal file while it is being replaced, which must always
+// find a complete journal
+func TestFileStoreAtomic(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := store.Save(ctx, "activity-1", Entry{Step: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// large entries make a partial write likely to be observed
+		errMsg := strings.Repeat("x", 64<<10)
+		for i := 0; i < 50; i++ {
+			if err := store.Save(ctx, "activity-1", Entry{Step: 1 + i%2, Error: errMsg}); err != nil {
+				t.Error(err)
+				break
+			}
+		}
+		close(done)
+	}()
+
+	path := filepath.Join(dir, "activity-1.json")
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			t.Fatalf("read a partially written journal: %v", err)
+		}
+	}
+	wg.Wait()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "activity-1.json" {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Fatalf("expected only the journal file to be left, got %v", names)
+	}
+}
@@ -0,0 +1,98 @@
+// Package journal durably records the progress of multi-step service.ChainActivity flows
+// so an interrupted activity can be resumed without sending its transactions twice
+package journal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// Status is the state of a journaled step
+type Status string
+
+const (
+	// StatusPending is recorded before a step is executed
+	StatusPending Status = "pending"
+
+	// StatusSent is recorded before a signed transaction is broadcast
+	StatusSent Status = "sent"
+
+	// StatusConfirmed is recorded once a transaction is mined successfully or a request is signed
+	StatusConfirmed Status = "confirmed"
+
+	// StatusFailed is recorded when a step failed and nothing of it is pending on chain
+	StatusFailed Status = "failed"
+)
+
+// Entry is the journal record of a single step of a chain activity
+type Entry struct {
+	Step      int                           `json:"step"`
+	Type      service.ChainActivityStepType `json:"type"`
+	Request   service.ChainActivityStep     `json:"request"`
+	Status    Status                        `json:"status"`
+	SignedTxs []hexutil.Bytes               `json:"signedTxs,omitempty"`
+	TxHash    *common.Hash                  `json:"txHash,omitempty"`
+	Receipt   *types.Receipt                `json:"receipt,omitempty"`
+	Signature hexutil.Bytes                 `json:"signature,omitempty"`
+	IDs       map[string]string             `json:"ids,omitempty"`
+	Error     string                        `json:"error,omitempty"`
+	UpdatedAt time.Time                     `json:"updatedAt"`
+}
+
+// Transactions decodes the signed transactions broadcast for the step, in order
+func (e *Entry) Transactions() ([]*types.Transaction, error) {
+	txs := make([]*types.Transaction, 0, len(e.SignedTxs))
+	for _, raw := range e.SignedTxs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("failed to decode signed transaction of step %d: %w", e.Step, err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// Store persists journal entries per chain activity
+type Store interface {
+	// Load returns the entries of an activity ordered by step, or none if it is unknown
+	Load(ctx context.Context, activityID string) ([]Entry, error)
+
+	// Save inserts or replaces the entry of entry.Step
+	Save(ctx context.Context, activityID string, entry Entry) error
+}
+
+// SetID records an ID derived from a step, such as the vault ID created by it
+func SetID(ctx context.Context, store Store, activityID string, step int, key, value string) error {
+	entries, err := store.Load(ctx, activityID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Step != step {
+			continue
+		}
+		if entry.IDs == nil {
+			entry.IDs = make(map[string]string)
+		}
+		entry.IDs[key] = value
+		entry.UpdatedAt = time.Now().UTC()
+		return store.Save(ctx, activityID, entry)
+	}
+	return fmt.Errorf("step %d of activity %q is not journaled", step, activityID)
+}
+
+// ID returns an ID recorded with SetID on any step of the activity
+func ID(entries []Entry, key string) (string, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if value, ok := entries[i].IDs[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
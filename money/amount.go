@@ -0,0 +1,297 @@
+// Package money provides an exact decimal Amount type for token amounts and conversions
+// between human readable amounts and the native token units used by the service API
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// ErrPrecision is returned when an amount has more decimals than the token it is converted to
+var ErrPrecision = errors.New("amount exceeds token precision")
+
+// Amount is an exact decimal amount, stored as an integer coefficient and a number of
+// decimals. The zero value is 0.
+type Amount struct {
+	coef     *big.Int
+	decimals int
+}
+
+// Zero is the zero amount
+var Zero = Amount{}
+
+// New creates the amount coef * 10^-decimals
+func New(coef *big.Int, decimals int) Amount {
+	if coef == nil {
+		return Zero
+	}
+	if decimals < 0 {
+		scale := pow10(-decimals)
+		return Amount{coef: new(big.Int).Mul(coef, scale)}.normalize()
+	}
+	return Amount{coef: new(big.Int).Set(coef), decimals: decimals}.normalize()
+}
+
+// Parse parses a decimal string like "12", "-0.5" or "1234.000001". Exponents and
+// thousands separators are not accepted.
+func Parse(s string) (Amount, error) {
+	text := strings.TrimSpace(s)
+	digits := strings.TrimLeft(text, "+-")
+	if len(text)-len(digits) > 1 {
+		return Zero, fmt.Errorf("invalid amount: %q", s)
+	}
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" {
+		return Zero, fmt.Errorf("invalid amount: %q", s)
+	}
+	for _, part := range []string{whole, fraction} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return Zero, fmt.Errorf("invalid amount: %q", s)
+			}
+		}
+	}
+	coef, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid amount: %q", s)
+	}
+	if strings.HasPrefix(text, "-") {
+		coef.Neg(coef)
+	}
+	return Amount{coef: coef, decimals: len(fraction)}.normalize(), nil
+}
+
+// MustParse parses a decimal string and panics if it is invalid, for constants in code
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// FromNative converts an amount in native units, as returned by the service API, of a token
+// with the given number of decimals
+func FromNative(native string, decimals int) (Amount, error) {
+	coef, ok := new(big.Int).SetString(strings.TrimSpace(native), 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid native amount: %q", native)
+	}
+	return New(coef, decimals), nil
+}
+
+// FromTokenNative converts an amount in native units of token
+func FromTokenNative(native string, token service.Token) (Amount, error) {
+	return FromNative(native, int(token.Decimals))
+}
+
+// Native returns the amount in native units of a token with the given number of decimals. It
+// returns ErrPrecision rather than rounding if the amount has more decimals than the token.
+func (a Amount) Native(decimals int) (*big.Int, error) {
+	coef := a.coefficient()
+	if a.decimals <= decimals {
+		return coef.Mul(coef, pow10(decimals-a.decimals)), nil
+	}
+	return nil, fmt.Errorf("%w: %s has more than %d decimals", ErrPrecision, a, decimals)
+}
+
+// NativeString returns the amount in native units as a decimal string
+func (a Amount) NativeString(decimals int) (string, error) {
+	native, err := a.Native(decimals)
+	if err != nil {
+		return "", err
+	}
+	return native.String(), nil
+}
+
+// ToNative returns the amount in native units of token, ready to be used as the amount of a
+// request, e.g. LendingpoolDepositTxRequest.Amount
+func (a Amount) ToNative(token service.Token) (*string, error) {
+	native, err := a.NativeString(int(token.Decimals))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", token.Symbol, err)
+	}
+	return &native, nil
+}
+
+// String returns the amount as a decimal string without trailing zeros
+func (a Amount) String() string {
+	coef := a.coefficient()
+	negative := coef.Sign() < 0
+	digits := coef.Abs(coef).String()
+	if a.decimals > 0 {
+		if len(digits) <= a.decimals {
+			digits = strings.Repeat("0", a.decimals-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-a.decimals] + "." + digits[len(digits)-a.decimals:]
+	}
+	if negative {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed returns the amount rounded half away from zero to the given number of decimals,
+// padded with trailing zeros
+func (a Amount) StringFixed(decimals int) string {
+	r := a.Round(decimals)
+	if r.decimals < decimals {
+		r.coef = r.coefficient().Mul(r.coefficient(), pow10(decimals-r.decimals))
+		r.decimals = decimals
+	}
+	return r.String()
+}
+
+// Round rounds the amount half away from zero to the given number of decimals, which may be
+// negative to round to tens, hundreds and so on
+func (a Amount) Round(decimals int) Amount {
+	if a.decimals <= decimals {
+		return a
+	}
+	scale := pow10(a.decimals - decimals)
+	q, rem := new(big.Int).QuoRem(a.coefficient(), scale, new(big.Int))
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(scale) >= 0 {
+		if a.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return New(q, decimals)
+}
+
+// Decimals returns the number of significant decimals of the amount
+func (a Amount) Decimals() int {
+	return a.decimals
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	x, y, decimals := align(a, b)
+	return Amount{coef: x.Add(x, y), decimals: decimals}.normalize()
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	x, y, decimals := align(a, b)
+	return Amount{coef: x.Sub(x, y), decimals: decimals}.normalize()
+}
+
+// Mul returns a * b
+func (a Amount) Mul(b Amount) Amount {
+	coef := a.coefficient()
+	return Amount{coef: coef.Mul(coef, b.coefficient()), decimals: a.decimals + b.decimals}.normalize()
+}
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	coef := a.coefficient()
+	return Amount{coef: coef.Neg(coef), decimals: a.decimals}
+}
+
+// Cmp compares a and b and returns -1, 0 or +1
+func (a Amount) Cmp(b Amount) int {
+	x, y, _ := align(a, b)
+	return x.Cmp(y)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of a
+func (a Amount) Sign() int {
+	if a.coef == nil {
+		return 0
+	}
+	return a.coef.Sign()
+}
+
+// IsZero reports whether a is 0
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Rat returns the amount as a big.Rat
+func (a Amount) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(a.coefficient(), pow10(a.decimals))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (a *Amount) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// MarshalJSON encodes the amount as a JSON string, so that no precision is lost by clients
+// decoding numbers as floats
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON decodes a JSON string or number
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid amount: %s", data)
+		}
+		s = n.String()
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+// coefficient returns a copy of the coefficient of a
+func (a Amount) coefficient() *big.Int {
+	if a.coef == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.coef)
+}
+
+// normalize drops trailing zero decimals
+func (a Amount) normalize() Amount {
+	if a.coef == nil || a.coef.Sign() == 0 {
+		return Zero
+	}
+	ten := big.NewInt(10)
+	q, rem := new(big.Int), new(big.Int)
+	for a.decimals > 0 {
+		q.QuoRem(a.coef, ten, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+		a.coef = new(big.Int).Set(q)
+		a.decimals--
+	}
+	return a
+}
+
+// align returns the coefficients of a and b scaled to the same number of decimals
+func align(a, b Amount) (*big.Int, *big.Int, int) {
+	x, y := a.coefficient(), b.coefficient()
+	switch {
+	case a.decimals < b.decimals:
+		x.Mul(x, pow10(b.decimals-a.decimals))
+		return x, y, b.decimals
+	case a.decimals > b.decimals:
+		y.Mul(y, pow10(a.decimals-b.decimals))
+	}
+	return x, y, a.decimals
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,180 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/zarbanio/zarban-go/service"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     string
+		decimals int
+		wantErr  bool
+	}{
+		{in: "12", want: "12"},
+		{in: "-0.5", want: "-0.5", decimals: 1},
+		{in: "+1234.000001", want: "1234.000001", decimals: 6},
+		{in: " 1.50 ", want: "1.5", decimals: 1},
+		{in: ".5", want: "0.5", decimals: 1},
+		{in: "1.", want: "1"},
+		{in: "-0", want: "0"},
+		{in: "0.000000000000000000000001", want: "0.000000000000000000000001", decimals: 24},
+		{in: "1e3", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "+-1", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "1 000", wantErr: true},
+		{in: "0x10", wantErr: true},
+		{in: "۱۲", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			a, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if a.String() != tt.want || a.Decimals() != tt.decimals {
+				t.Fatalf("expected %s with %d decimals, got %s with %d", tt.want, tt.decimals, a, a.Decimals())
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		want     string
+	}{
+		{in: "1.5", decimals: 0, want: "2"},
+		{in: "2.5", decimals: 0, want: "3"},
+		{in: "1.49", decimals: 0, want: "1"},
+		{in: "-1.5", decimals: 0, want: "-2"},
+		{in: "-2.5", decimals: 0, want: "-3"},
+		{in: "-1.49", decimals: 0, want: "-1"},
+		{in: "-0.5", decimals: 0, want: "-1"},
+		{in: "-0.4", decimals: 0, want: "0"},
+		{in: "-1.045", decimals: 2, want: "-1.05"},
+		{in: "1.045", decimals: 2, want: "1.05"},
+		{in: "-1.044", decimals: 2, want: "-1.04"},
+		{in: "1.25", decimals: 4, want: "1.25"},
+		{in: "125", decimals: -1, want: "130"},
+		{in: "0", decimals: 2, want: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := MustParse(tt.in).Round(tt.decimals).String(); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+	if got := MustParse("-1.005").StringFixed(2); got != "-1.01" {
+		t.Fatalf("expected -1.01, got %s", got)
+	}
+	if got := MustParse("-1").StringFixed(2); got != "-1.00" {
+		t.Fatalf("expected -1.00, got %s", got)
+	}
+}
+
+func TestNative(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		want     string
+		wantErr  error
+	}{
+		{in: "1.5", decimals: 6, want: "1500000"},
+		{in: "-0.000001", decimals: 6, want: "-1"},
+		{in: "1000", decimals: 18, want: "1000000000000000000000"},
+		{in: "0", decimals: 18, want: "0"},
+		{in: "1.5", decimals: 0, wantErr: ErrPrecision},
+		{in: "0.0000001", decimals: 6, wantErr: ErrPrecision},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			native, err := MustParse(tt.in).NativeString(tt.decimals)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if native != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, native)
+			}
+			back, err := FromNative(native, tt.decimals)
+			if err != nil || back.Cmp(MustParse(tt.in)) != 0 {
+				t.Fatalf("expected %s back, got %s, %v", tt.in, back, err)
+			}
+		})
+	}
+
+	usdc := service.Token{Symbol: "USDC", Decimals: 6}
+	if _, err := MustParse("0.0000001").ToNative(usdc); !errors.Is(err, ErrPrecision) {
+		t.Fatalf("expected %v, got %v", ErrPrecision, err)
+	}
+	if native, err := MustParse("2.5").ToNative(usdc); err != nil || *native != "2500000" {
+		t.Fatalf("expected 2500000, got %v, %v", native, err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("1.25"), MustParse("-0.005")
+	if got := a.Add(b).String(); got != "1.245" {
+		t.Fatalf("expected 1.245, got %s", got)
+	}
+	if got := a.Sub(b).String(); got != "1.255" {
+		t.Fatalf("expected 1.255, got %s", got)
+	}
+	if got := a.Mul(b).String(); got != "-0.00625" {
+		t.Fatalf("expected -0.00625, got %s", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(MustParse("1.250")) != 0 {
+		t.Fatal("unexpected comparison")
+	}
+	if !Zero.IsZero() || Zero.String() != "0" || Zero.Add(a).Cmp(a) != 0 {
+		t.Fatal("expected the zero value to be usable")
+	}
+	if got := New(big.NewInt(15), -2).String(); got != "1500" {
+		t.Fatalf("expected 1500, got %s", got)
+	}
+	if r := a.Rat(); r.Cmp(big.NewRat(5, 4)) != 0 {
+		t.Fatalf("expected 5/4, got %s", r)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var v struct {
+		Amount Amount `json:"amount"`
+	}
+	for _, in := range []string{`{"amount":"12.50"}`, `{"amount":12.50}`} {
+		if err := json.Unmarshal([]byte(in), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.Amount.String() != "12.5" {
+			t.Fatalf("expected 12.5, got %s", v.Amount)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"1e3"}`), &v); err == nil {
+		t.Fatal("expected an error for an exponent")
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) != `{"amount":"12.5"}` {
+		t.Fatalf("expected the amount as a string, got %s, %v", data, err)
+	}
+}
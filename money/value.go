@@ -0,0 +1,130 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// ErrMissingDenomination is returned when a value is not expressed in the requested denomination
+var ErrMissingDenomination = errors.New("missing denomination")
+
+// Denomination is the unit an amount of a Currency is expressed in, a fiat currency like ZAR or
+// the symbol of a token
+type Denomination string
+
+// Fiat denominations returned by the APIs
+const (
+	ZAR Denomination = "ZAR"
+	USD Denomination = "USD"
+	TMN Denomination = "TMN"
+)
+
+// Value is an amount expressed in several denominations, the common shape of service.Currency
+// and wallet.Currency. Amounts are kept as returned by the API and parsed when they are read.
+type Value map[Denomination]string
+
+// FromService converts a service.Currency
+func FromService(c service.Currency) Value {
+	v := make(Value, len(c))
+	for key, amount := range c {
+		v[Denomination(key)] = amount
+	}
+	return v
+}
+
+// FromWallet converts a wallet.Currency, which may have no values
+func FromWallet(c wallet.Currency) Value {
+	if c.Values == nil {
+		return Value{}
+	}
+	v := make(Value, len(*c.Values))
+	for key, amount := range *c.Values {
+		v[Denomination(key)] = amount
+	}
+	return v
+}
+
+// Get returns the amount in denomination d. Denominations are matched case-insensitively.
+func (v Value) Get(d Denomination) (Amount, error) {
+	raw, ok := v.lookup(d)
+	if !ok {
+		return Zero, fmt.Errorf("%w: %s", ErrMissingDenomination, d)
+	}
+	amount, err := Parse(raw)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to parse %s amount: %w", d, err)
+	}
+	return amount, nil
+}
+
+// ZAR returns the amount in ZAR
+func (v Value) ZAR() (Amount, error) {
+	return v.Get(ZAR)
+}
+
+// USD returns the amount in USD
+func (v Value) USD() (Amount, error) {
+	return v.Get(USD)
+}
+
+// Native returns the amount in units of the token itself, keyed by its symbol
+func (v Value) Native(symbol string) (Amount, error) {
+	return v.Get(Denomination(symbol))
+}
+
+// Has reports whether the value is expressed in denomination d
+func (v Value) Has(d Denomination) bool {
+	_, ok := v.lookup(d)
+	return ok
+}
+
+// Denominations returns the denominations of the value in alphabetical order
+func (v Value) Denominations() []Denomination {
+	denominations := make([]Denomination, 0, len(v))
+	for d := range v {
+		denominations = append(denominations, d)
+	}
+	sort.Slice(denominations, func(i, j int) bool { return denominations[i] < denominations[j] })
+	return denominations
+}
+
+// Set sets the amount in denomination d
+func (v Value) Set(d Denomination, amount Amount) {
+	v[d] = amount.String()
+}
+
+// Service converts the value to a service.Currency
+func (v Value) Service() service.Currency {
+	c := make(service.Currency, len(v))
+	for d, amount := range v {
+		c[string(d)] = amount
+	}
+	return c
+}
+
+// Wallet converts the value to a wallet.Currency
+func (v Value) Wallet() wallet.Currency {
+	values := make(map[string]string, len(v))
+	for d, amount := range v {
+		values[string(d)] = amount
+	}
+	return wallet.Currency{Values: &values}
+}
+
+// lookup finds the raw amount of denomination d, preferring an exact match
+func (v Value) lookup(d Denomination) (string, bool) {
+	if raw, ok := v[d]; ok {
+		return raw, true
+	}
+	for key, raw := range v {
+		if strings.EqualFold(string(key), string(d)) {
+			return raw, true
+		}
+	}
+	return "", false
+}
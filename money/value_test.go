@@ -0,0 +1,83 @@
+package money
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+func TestValueGet(t *testing.T) {
+	v := Value{"ZAR": "1250000.5", "usd": "3.2", "USDC": "1e3", "Usdc": "7", "TMN": ""}
+	tests := []struct {
+		name         string
+		denomination Denomination
+		want         string
+		wantErr      error
+		wantParseErr bool
+	}{
+		{name: "exact key", denomination: ZAR, want: "1250000.5"},
+		{name: "case-insensitive key", denomination: USD, want: "3.2"},
+		{name: "exact key preferred", denomination: "Usdc", want: "7"},
+		{name: "missing denomination", denomination: "DAI", wantErr: ErrMissingDenomination},
+		{name: "malformed amount", denomination: "USDC", wantParseErr: true},
+		{name: "empty amount", denomination: TMN, wantParseErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Get(tt.denomination)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.wantParseErr:
+				if err == nil || errors.Is(err, ErrMissingDenomination) {
+					t.Fatalf("expected a parse error, got %v", err)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if !v.Has("zar") || v.Has("DAI") {
+		t.Fatal("unexpected Has")
+	}
+	if amount, err := v.Native("usd"); err != nil || amount.String() != "3.2" {
+		t.Fatalf("expected 3.2, got %s, %v", amount, err)
+	}
+}
+
+func TestValueConversions(t *testing.T) {
+	values := map[string]string{"ZAR": "10", "USD": "0.2"}
+	fromWallet := FromWallet(wallet.Currency{Values: &values})
+	fromService := FromService(service.Currency{"ZAR": "10", "USD": "0.2"})
+	if !reflect.DeepEqual(fromWallet, fromService) {
+		t.Fatalf("expected the same value, got %v and %v", fromWallet, fromService)
+	}
+	if got := fromService.Denominations(); !reflect.DeepEqual(got, []Denomination{USD, ZAR}) {
+		t.Fatalf("expected sorted denominations, got %v", got)
+	}
+	if v := FromWallet(wallet.Currency{}); len(v) != 0 {
+		t.Fatalf("expected an empty value, got %v", v)
+	}
+
+	fromService.Set(TMN, MustParse("1.50"))
+	if fromService[TMN] != "1.5" {
+		t.Fatalf("expected 1.5, got %s", fromService[TMN])
+	}
+	if c := fromService.Service(); c["TMN"] != "1.5" || c["ZAR"] != "10" {
+		t.Fatalf("unexpected service currency %v", c)
+	}
+	if c := fromService.Wallet(); (*c.Values)["TMN"] != "1.5" || len(*c.Values) != 3 {
+		t.Fatalf("unexpected wallet currency %v", *c.Values)
+	}
+}
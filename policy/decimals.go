@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// decimalsSelector is the selector of the ERC-20 decimals() method
+var decimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+
+// DecimalsFunc returns the number of decimals of the collateral token of an ilk
+type DecimalsFunc func(ctx context.Context, ilk service.Ilk) (int, error)
+
+// GemDecimals reads the decimals of the collateral token of an ilk from its gem contract and
+// caches them. The go-ethereum *ethclient.Client implements ethereum.ContractCaller.
+func GemDecimals(caller ethereum.ContractCaller) DecimalsFunc {
+	var cache sync.Map
+	return func(ctx context.Context, ilk service.Ilk) (int, error) {
+		if !common.IsHexAddress(ilk.Gem) {
+			return 0, fmt.Errorf("invalid gem address of ilk %s: %q", ilk.Name, ilk.Gem)
+		}
+		gem := common.HexToAddress(ilk.Gem)
+		if decimals, ok := cache.Load(gem); ok {
+			return decimals.(int), nil
+		}
+		result, err := caller.CallContract(ctx, ethereum.CallMsg{To: &gem, Data: decimalsSelector}, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get decimals of %s: %w", ilk.Symbol, err)
+		}
+		if len(result) != 32 {
+			return 0, fmt.Errorf("failed to get decimals of %s: unexpected result %x", ilk.Symbol, result)
+		}
+		value := new(big.Int).SetBytes(result)
+		if !value.IsInt64() || value.Int64() > 77 {
+			return 0, fmt.Errorf("invalid decimals of %s: %s", ilk.Symbol, value)
+		}
+		decimals := int(value.Int64())
+		cache.Store(gem, decimals)
+		return decimals, nil
+	}
+}
+
+// collateralDecimals returns the decimals of the collateral of ilk
+func (e *Enforcer) collateralDecimals(ctx context.Context, ilk service.Ilk) (int, error) {
+	if e.decimals == nil {
+		return 0, fmt.Errorf("decimals of %s collateral are unknown, see WithCollateralDecimals", ilk.Symbol)
+	}
+	return e.decimals(ctx, ilk)
+}
@@ -0,0 +1,316 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zarbanio/zarban-go/dsmath"
+	"github.com/zarbanio/zarban-go/money"
+)
+
+// window is the period daily limits apply to
+const window = 24 * time.Hour
+
+// zarDecimals is the number of decimals of ZAR, a wad
+const zarDecimals = dsmath.WadDecimals
+
+// Enforcer checks operations against a policy and keeps track of the amounts used by the
+// daily limits. An Enforcer can be shared by a ServiceClient, a WalletClient and a TxValidator.
+type Enforcer struct {
+	policy   Policy
+	now      func() time.Time
+	decimals DecimalsFunc
+	store    UsageStore
+	signing  bool
+
+	mu    sync.Mutex
+	usage map[string][]usage
+}
+
+// usage is an amount counted against a daily limit
+type usage struct {
+	at     time.Time
+	amount *big.Rat
+}
+
+// reservation is an amount counted against daily limits before an operation is sent, which is
+// released if the operation fails
+type reservation struct {
+	e      *Enforcer
+	rules  []string
+	amount *big.Rat
+}
+
+// Option allows setting custom parameters during construction
+type Option func(*Enforcer) error
+
+// NewEnforcer creates an Enforcer for policy. With a usage store, the amounts used in the last
+// 24 hours are loaded from it.
+func NewEnforcer(policy *Policy, opts ...Option) (*Enforcer, error) {
+	if policy == nil {
+		return nil, errors.New("policy is required")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	e := Enforcer{
+		policy: *policy,
+		now:    time.Now,
+		usage:  make(map[string][]usage),
+	}
+	for _, o := range opts {
+		if err := o(&e); err != nil {
+			return nil, err
+		}
+	}
+	if e.store != nil {
+		if err := e.load(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+// WithClock sets the clock used by the daily limits, defaults to time.Now
+func WithClock(now func() time.Time) Option {
+	return func(e *Enforcer) error {
+		e.now = now
+		return nil
+	}
+}
+
+// WithCollateralDecimals sets how the decimals of vault collateral are found, e.g. GemDecimals.
+// It is required to check collateral amounts given in native token units against
+// minCollateralizationRatio.
+func WithCollateralDecimals(decimals DecimalsFunc) Option {
+	return func(e *Enforcer) error {
+		e.decimals = decimals
+		return nil
+	}
+}
+
+// WithSigningTimeMints counts minted ZAR against maxZarMintedPerDay when a TxValidator checks
+// the transactions minting it, instead of when a ServiceClient requests them. The ServiceClient
+// then only checks that minted ZAR fits in the daily limit. It is required by NewTxValidator, so
+// that the same mint is not counted twice. Without a TxValidator, minted ZAR is not counted.
+func WithSigningTimeMints() Option {
+	return func(e *Enforcer) error {
+		e.signing = true
+		return nil
+	}
+}
+
+// WithUsageStore persists the amounts counted against daily limits, so that the limits hold
+// across restarts. The store must not be shared by several enforcers.
+func WithUsageStore(store UsageStore) Option {
+	return func(e *Enforcer) error {
+		e.store = store
+		return nil
+	}
+}
+
+// Policy returns the enforced policy
+func (e *Enforcer) Policy() Policy {
+	return e.policy
+}
+
+// CheckSymbol returns a ViolationError if symbol is not allowed
+func (e *Enforcer) CheckSymbol(symbol string) error {
+	if !e.policy.allows(symbol) {
+		return &ViolationError{Kind: ErrSymbolNotAllowed, Rule: "allowedSymbols", Actual: symbol}
+	}
+	return nil
+}
+
+// CheckCollateralization returns a ViolationError if ratio is below the minimum
+// collateralization ratio
+func (e *Enforcer) CheckCollateralization(ratio *big.Rat) error {
+	if e.policy.MinCollateralizationRatio == "" {
+		return nil
+	}
+	limit, _ := parseAmount(e.policy.MinCollateralizationRatio)
+	if ratio.Cmp(limit) < 0 {
+		return &ViolationError{
+			Kind:   ErrCollateralization,
+			Rule:   "minCollateralizationRatio",
+			Limit:  formatAmount(limit),
+			Actual: ratio.FloatString(4),
+		}
+	}
+	return nil
+}
+
+// Used returns the amount counted against the daily limit of a rule in the last 24 hours
+func (e *Enforcer) Used(rule string) *big.Rat {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.used(rule, e.now())
+}
+
+// reserveMint counts minted ZAR against maxZarMintedPerDay. With WithSigningTimeMints, minted
+// ZAR is counted by the TxValidator and only checked here.
+func (e *Enforcer) reserveMint(ctx context.Context, amount *big.Rat) (*reservation, error) {
+	return e.reserve(ctx, map[string]string{"maxZarMintedPerDay": e.policy.MaxZarMintedPerDay}, amount, !e.signing)
+}
+
+// reserveRedemption counts redeemed ZAR against maxRedemptionPerDay
+func (e *Enforcer) reserveRedemption(ctx context.Context, amount *big.Rat) (*reservation, error) {
+	return e.reserve(ctx, map[string]string{"maxRedemptionPerDay": e.policy.MaxRedemptionPerDay}, amount, true)
+}
+
+// reserveWithdrawal counts a withdrawal against the matching maxWithdrawalPerDay limits
+func (e *Enforcer) reserveWithdrawal(ctx context.Context, network, symbol string, amount *big.Rat) (*reservation, error) {
+	limits := make(map[string]string)
+	for _, limit := range e.policy.MaxWithdrawalPerDay {
+		if !strings.EqualFold(limit.Network, network) {
+			continue
+		}
+		if limit.Symbol != "" && !strings.EqualFold(limit.Symbol, symbol) {
+			continue
+		}
+		rule := fmt.Sprintf("maxWithdrawalPerDay[%s]", strings.ToUpper(limit.Network))
+		if limit.Symbol != "" {
+			rule = fmt.Sprintf("maxWithdrawalPerDay[%s/%s]", strings.ToUpper(limit.Network), strings.ToUpper(limit.Symbol))
+		}
+		limits[rule] = limit.Amount
+	}
+	return e.reserve(ctx, limits, amount, true)
+}
+
+// reserve checks that amount fits in the daily limits, keyed by rule, and counts it against
+// them unless count is false, in which case no reservation is returned. Empty limits are not
+// enforced. Counted amounts are saved to the usage store before reserve returns.
+func (e *Enforcer) reserve(ctx context.Context, limits map[string]string, amount *big.Rat, count bool) (*reservation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	r := reservation{e: e, amount: new(big.Rat).Set(amount)}
+	for rule, limit := range limits {
+		if limit == "" {
+			continue
+		}
+		maxAmount, err := parseAmount(limit)
+		if err != nil {
+			return nil, err
+		}
+		total := new(big.Rat).Add(e.used(rule, now), amount)
+		if total.Cmp(maxAmount) > 0 {
+			return nil, &ViolationError{
+				Kind:   ErrLimitExceeded,
+				Rule:   rule,
+				Limit:  formatAmount(maxAmount),
+				Actual: formatAmount(total),
+			}
+		}
+		r.rules = append(r.rules, rule)
+	}
+	if !count || len(r.rules) == 0 {
+		return nil, nil
+	}
+	for _, rule := range r.rules {
+		e.usage[rule] = append(e.usage[rule], usage{at: now, amount: r.amount})
+	}
+	if err := e.save(ctx); err != nil {
+		r.remove()
+		return nil, err
+	}
+	return &r, nil
+}
+
+// used sums the usage of a rule since now-window and drops older usage, e.mu must be held
+func (e *Enforcer) used(rule string, now time.Time) *big.Rat {
+	total := new(big.Rat)
+	entries := e.usage[rule][:0]
+	for _, entry := range e.usage[rule] {
+		if now.Sub(entry.at) >= window {
+			continue
+		}
+		entries = append(entries, entry)
+		total.Add(total, entry.amount)
+	}
+	e.usage[rule] = entries
+	return total
+}
+
+// load reads the usage of the last 24 hours from the store
+func (e *Enforcer) load(ctx context.Context) error {
+	records, err := e.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load policy usage: %w", err)
+	}
+	now := e.now()
+	for _, record := range records {
+		amount, ok := new(big.Rat).SetString(record.Amount)
+		if !ok {
+			return fmt.Errorf("failed to load policy usage: invalid amount %q of %s", record.Amount, record.Rule)
+		}
+		if now.Sub(record.At) >= window {
+			continue
+		}
+		e.usage[record.Rule] = append(e.usage[record.Rule], usage{at: record.At, amount: amount})
+	}
+	return nil
+}
+
+// save writes the usage of the last 24 hours to the store, e.mu must be held
+func (e *Enforcer) save(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+	now := e.now()
+	var records []Usage
+	for rule := range e.usage {
+		e.used(rule, now)
+		for _, entry := range e.usage[rule] {
+			records = append(records, Usage{Rule: rule, At: entry.at, Amount: entry.amount.RatString()})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].At.Equal(records[j].At) {
+			return records[i].At.Before(records[j].At)
+		}
+		return records[i].Rule < records[j].Rule
+	})
+	if err := e.store.Save(ctx, records); err != nil {
+		return fmt.Errorf("failed to save policy usage: %w", err)
+	}
+	return nil
+}
+
+// release returns the reserved amounts to the daily limits. A failure to save the released
+// usage is ignored, it only leaves the amounts counted after a restart.
+func (r *reservation) release(ctx context.Context) {
+	r.e.mu.Lock()
+	defer r.e.mu.Unlock()
+	r.remove()
+	_ = r.e.save(ctx)
+}
+
+// remove drops the reserved amounts from the usage, r.e.mu must be held
+func (r *reservation) remove() {
+	for _, rule := range r.rules {
+		entries := r.e.usage[rule]
+		for i, entry := range entries {
+			if entry.amount == r.amount {
+				r.e.usage[rule] = append(entries[:i], entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// fromNative converts an amount in native units of a token with the given decimals to whole units
+func fromNative(amount string, decimals int) (*big.Rat, error) {
+	value, err := money.FromNative(amount, decimals)
+	if err != nil || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %q", amount)
+	}
+	return value.Rat(), nil
+}
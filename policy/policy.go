@@ -0,0 +1,169 @@
+// Package policy enforces declarative spending policies on the client side, before chain
+// activities are requested from the service API, before their transactions are signed and before
+// wallet withdrawals, swaps and redemptions are sent
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/money"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrSymbolNotAllowed is returned for operations on a symbol missing from allowedSymbols
+	ErrSymbolNotAllowed = errors.New("symbol not allowed")
+
+	// ErrLimitExceeded is returned for operations that would exceed a daily limit
+	ErrLimitExceeded = errors.New("daily limit exceeded")
+
+	// ErrCollateralization is returned for vault operations that would leave the vault below the
+	// minimum collateralization ratio
+	ErrCollateralization = errors.New("collateralization ratio below minimum")
+)
+
+// ViolationError describes the policy rule an operation violates. Kind is one of
+// ErrSymbolNotAllowed, ErrLimitExceeded or ErrCollateralization.
+type ViolationError struct {
+	Kind   error
+	Rule   string
+	Limit  string
+	Actual string
+}
+
+// Error implements the error interface
+func (e *ViolationError) Error() string {
+	if errors.Is(e.Kind, ErrSymbolNotAllowed) {
+		return fmt.Sprintf("policy %s: %v: %s", e.Rule, e.Kind, e.Actual)
+	}
+	return fmt.Sprintf("policy %s: %v: %s, limit %s", e.Rule, e.Kind, e.Actual, e.Limit)
+}
+
+// Unwrap returns the kind of violation
+func (e *ViolationError) Unwrap() error {
+	return e.Kind
+}
+
+// Policy is a set of spending rules. Amounts are decimal strings in whole units, e.g. "1000"
+// ZAR, and empty rules are not enforced. Daily limits apply to a rolling 24 hour window.
+//
+// An example policy in YAML:
+//
+//	allowedSymbols: [ZAR, USDT, ETH]
+//	maxZarMintedPerDay: "50000000"
+//	maxRedemptionPerDay: "10000000"
+//	maxWithdrawalPerDay:
+//	  - network: TRON
+//	    symbol: USDT
+//	    amount: "500"
+//	minCollateralizationRatio: "1.8"
+type Policy struct {
+	// AllowedSymbols restricts the symbols that can be withdrawn, swapped or used as vault collateral
+	AllowedSymbols []string `json:"allowedSymbols,omitempty" yaml:"allowedSymbols,omitempty"`
+
+	// MaxZarMintedPerDay limits the ZAR minted by creating vaults and minting from vaults
+	MaxZarMintedPerDay string `json:"maxZarMintedPerDay,omitempty" yaml:"maxZarMintedPerDay,omitempty"`
+
+	// MaxRedemptionPerDay limits the ZAR redeemed to bank cards
+	MaxRedemptionPerDay string `json:"maxRedemptionPerDay,omitempty" yaml:"maxRedemptionPerDay,omitempty"`
+
+	// MaxWithdrawalPerDay limits the withdrawals on a network
+	MaxWithdrawalPerDay []WithdrawalLimit `json:"maxWithdrawalPerDay,omitempty" yaml:"maxWithdrawalPerDay,omitempty"`
+
+	// MinCollateralizationRatio is the lowest collateralization ratio a vault may have after
+	// creating it, minting from it or withdrawing collateral from it, e.g. "1.5"
+	MinCollateralizationRatio string `json:"minCollateralizationRatio,omitempty" yaml:"minCollateralizationRatio,omitempty"`
+}
+
+// WithdrawalLimit limits the withdrawals of a symbol on a network. An empty symbol limits the
+// sum of the withdrawals of all symbols on the network.
+type WithdrawalLimit struct {
+	Network string `json:"network" yaml:"network"`
+	Symbol  string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	Amount  string `json:"amount" yaml:"amount"`
+}
+
+// Load reads a policy from a YAML or JSON file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML or JSON policy. Unknown fields are rejected so that a
+// misspelled rule is not silently ignored.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that the amounts of the policy are valid
+func (p *Policy) Validate() error {
+	amounts := map[string]string{
+		"maxZarMintedPerDay":        p.MaxZarMintedPerDay,
+		"maxRedemptionPerDay":       p.MaxRedemptionPerDay,
+		"minCollateralizationRatio": p.MinCollateralizationRatio,
+	}
+	for rule, amount := range amounts {
+		if amount == "" {
+			continue
+		}
+		if _, err := parseAmount(amount); err != nil {
+			return fmt.Errorf("invalid %s: %w", rule, err)
+		}
+	}
+	for i, limit := range p.MaxWithdrawalPerDay {
+		if limit.Network == "" {
+			return fmt.Errorf("invalid maxWithdrawalPerDay[%d]: network is required", i)
+		}
+		if _, err := parseAmount(limit.Amount); err != nil {
+			return fmt.Errorf("invalid maxWithdrawalPerDay[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// allows reports whether symbol is allowed
+func (p *Policy) allows(symbol string) bool {
+	if len(p.AllowedSymbols) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedSymbols {
+		if strings.EqualFold(allowed, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAmount parses a non-negative decimal amount. Fractions and exponents are rejected.
+func parseAmount(amount string) (*big.Rat, error) {
+	a, err := money.Parse(amount)
+	if err != nil || a.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %q", amount)
+	}
+	return a.Rat(), nil
+}
+
+// formatAmount renders an amount as a decimal string without trailing zeros
+func formatAmount(r *big.Rat) string {
+	s := r.FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,477 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// zar returns a ZAR amount in native units
+func zar(amount int64) string {
+	return new(big.Int).Mul(big.NewInt(amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)).String()
+}
+
+var testIlks = map[string]service.Ilk{
+	"USDTA": {Name: "USDTA", Symbol: "USDT", Gem: "0x00000000000000000000000000000000000000c6", Price: service.Currency{"ZAR": "50000"}},
+	"ETHA":  {Name: "ETHA", Symbol: "ETH", Gem: "0x00000000000000000000000000000000000000e1", Price: service.Currency{"ZAR": "100000000"}},
+}
+
+// newServiceAPI returns a client of a stand-in service API serving testIlks and accepting vault
+// creations, and the number of vaults created
+func newServiceAPI(t *testing.T) (service.ClientInterface, *int32) {
+	t.Helper()
+	var created int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v2/ilks/"):
+			ilk, ok := testIlks[strings.TrimPrefix(r.URL.Path, "/v2/ilks/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"msg":"not found"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(ilk)
+		case r.URL.Path == "/v2/stablecoinsystem/tx/createvault":
+			atomic.AddInt32(&created, 1)
+			_ = json.NewEncoder(w).Encode(service.ChainActivity{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client, err := service.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &created
+}
+
+// staticDecimals returns the given decimals for every ilk
+func staticDecimals(decimals int) DecimalsFunc {
+	return func(ctx context.Context, ilk service.Ilk) (int, error) {
+		return decimals, nil
+	}
+}
+
+func TestServiceClientCreateVault(t *testing.T) {
+	usdt := "1000000000" // 1000 USDT with 6 decimals, worth 50M ZAR
+	tests := []struct {
+		name     string
+		policy   Policy
+		decimals DecimalsFunc
+		body     service.CreateStableCoinVaultJSONRequestBody
+		wantErr  error
+	}{
+		{
+			name:     "collateral with six decimals",
+			policy:   Policy{MinCollateralizationRatio: "1.8"},
+			decimals: staticDecimals(6),
+			body:     service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", CollateralAmount: &usdt, MintAmount: zar(20000000)},
+		},
+		{
+			name:     "below the collateralization ratio",
+			policy:   Policy{MinCollateralizationRatio: "1.8"},
+			decimals: staticDecimals(6),
+			body:     service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", CollateralAmount: &usdt, MintAmount: zar(30000000)},
+			wantErr:  ErrCollateralization,
+		},
+		{
+			name:    "unknown collateral decimals",
+			policy:  Policy{MinCollateralizationRatio: "1.8"},
+			body:    service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", CollateralAmount: &usdt, MintAmount: zar(20000000)},
+			wantErr: errors.New("decimals of USDT collateral are unknown"),
+		},
+		{
+			name:    "symbol not allowed",
+			policy:  Policy{AllowedSymbols: []string{"USDT"}},
+			body:    service.CreateStableCoinVaultJSONRequestBody{IlkName: "ETHA", MintAmount: zar(1)},
+			wantErr: ErrSymbolNotAllowed,
+		},
+		{
+			name:    "daily mint limit",
+			policy:  Policy{MaxZarMintedPerDay: "10000000"},
+			body:    service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", CollateralAmount: &usdt, MintAmount: zar(20000000)},
+			wantErr: ErrLimitExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer, err := NewEnforcer(&tt.policy, WithCollateralDecimals(tt.decimals))
+			if err != nil {
+				t.Fatal(err)
+			}
+			api, created := newServiceAPI(t)
+			_, err = NewServiceClient(api, enforcer).CreateStableCoinVault(context.Background(), tt.body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) && (err == nil || !strings.Contains(err.Error(), tt.wantErr.Error())) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if *created != 0 {
+					t.Fatal("expected no vault to be requested")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *created != 1 {
+				t.Fatalf("expected one vault to be requested, got %d", *created)
+			}
+		})
+	}
+}
+
+// withdrawal is a withdrawal made at an offset from the start of a test
+type withdrawal struct {
+	after   time.Duration
+	amount  string
+	release bool
+	wantErr error
+}
+
+func TestEnforcerDailyLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		withdrawals []withdrawal
+	}{
+		{
+			name: "within the limit",
+			withdrawals: []withdrawal{
+				{amount: "300"},
+				{after: time.Hour, amount: "200"},
+			},
+		},
+		{
+			name: "above the limit",
+			withdrawals: []withdrawal{
+				{amount: "300"},
+				{after: time.Hour, amount: "201", wantErr: ErrLimitExceeded},
+			},
+		},
+		{
+			name: "rolling window",
+			withdrawals: []withdrawal{
+				{amount: "500"},
+				{after: 23 * time.Hour, amount: "1", wantErr: ErrLimitExceeded},
+				{after: 24 * time.Hour, amount: "500"},
+			},
+		},
+		{
+			name: "released withdrawal",
+			withdrawals: []withdrawal{
+				{amount: "500", release: true},
+				{after: time.Minute, amount: "500"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			now := start
+			p := Policy{MaxWithdrawalPerDay: []WithdrawalLimit{{Network: "TRON", Symbol: "USDT", Amount: "500"}}}
+			e, err := NewEnforcer(&p, WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, w := range tt.withdrawals {
+				now = start.Add(w.after)
+				amount, _ := parseAmount(w.amount)
+				r, err := e.reserveWithdrawal(context.Background(), "tron", "usdt", amount)
+				if w.wantErr != nil {
+					if !errors.Is(err, w.wantErr) {
+						t.Fatalf("withdrawal %d: expected %v, got %v", i, w.wantErr, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("withdrawal %d: %v", i, err)
+				}
+				if w.release {
+					r.release(context.Background())
+				}
+			}
+		})
+	}
+}
+
+func TestUsageStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	p := Policy{MaxRedemptionPerDay: "100"}
+	newEnforcer := func() *Enforcer {
+		store, err := NewFileUsageStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := NewEnforcer(&p, WithUsageStore(store), WithClock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	e := newEnforcer()
+	if _, err := e.reserveRedemption(context.Background(), big.NewRat(301, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	now = start.Add(time.Hour)
+	restarted := newEnforcer()
+	if used := restarted.Used("maxRedemptionPerDay"); used.Cmp(big.NewRat(301, 4)) != 0 {
+		t.Fatalf("expected 75.25 used after a restart, got %s", used.FloatString(2))
+	}
+	if _, err := restarted.reserveRedemption(context.Background(), big.NewRat(25, 1)); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected %v, got %v", ErrLimitExceeded, err)
+	}
+
+	now = start.Add(25 * time.Hour)
+	if used := newEnforcer().Used("maxRedemptionPerDay"); used.Sign() != 0 {
+		t.Fatalf("expected nothing used a day later, got %s", used.FloatString(2))
+	}
+}
+
+// openLockGemAndDraw returns DSProxy calldata opening a vault of ilk and minting wadD ZAR
+func openLockGemAndDraw(t *testing.T, ilk string, wadD string) string {
+	t.Helper()
+	abis := make(map[string]calldata.ContractABI)
+	for _, c := range calldata.Embedded() {
+		abis[c.Name] = c
+	}
+	var name [32]byte
+	copy(name[:], ilk)
+	wad, _ := new(big.Int).SetString(wadD, 10)
+	zero := common.Address{}
+	inner, err := abis["proxy_actions"].ABI.Pack("openLockGemAndDraw", zero, zero, zero, zero, name, big.NewInt(1), wad, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := abis["ds_proxy"].ABI.Pack("execute", common.HexToAddress("0x00000000000000000000000000000000000000a1"), inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(data)
+}
+
+func TestTxValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		calldata []string
+		wantErr  error
+		wantUsed string
+	}{
+		{
+			name:     "mint within the limit",
+			policy:   Policy{MaxZarMintedPerDay: "10000000"},
+			calldata: []string{openLockGemAndDraw(t, "USDTA", zar(4000000)), openLockGemAndDraw(t, "USDTA", zar(6000000))},
+			wantUsed: "10000000",
+		},
+		{
+			name:     "mint above the limit",
+			policy:   Policy{MaxZarMintedPerDay: "10000000"},
+			calldata: []string{openLockGemAndDraw(t, "USDTA", zar(6000000)), openLockGemAndDraw(t, "USDTA", zar(6000000))},
+			wantErr:  ErrLimitExceeded,
+			wantUsed: "6000000",
+		},
+		{
+			name:     "collateral not allowed",
+			policy:   Policy{AllowedSymbols: []string{"USDT"}},
+			calldata: []string{openLockGemAndDraw(t, "ETHA", zar(1))},
+			wantErr:  ErrSymbolNotAllowed,
+			wantUsed: "0",
+		},
+		{
+			name:     "unknown calldata",
+			policy:   Policy{MaxZarMintedPerDay: "1"},
+			calldata: []string{"0xdeadbeef", "0x"},
+			wantUsed: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEnforcer(&tt.policy, WithSigningTimeMints())
+			if err != nil {
+				t.Fatal(err)
+			}
+			api, _ := newServiceAPI(t)
+			v, err := NewTxValidator(e, api)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range tt.calldata {
+				err = v.Check(context.Background(), service.PreparedTx{
+					MethodParameters: service.MethodParameters{To: "0x00000000000000000000000000000000000000a2", Value: "0", Calldata: data},
+				})
+				if err != nil {
+					break
+				}
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if used := formatAmount(e.Used("maxZarMintedPerDay")); used != tt.wantUsed {
+				t.Fatalf("expected %s minted, got %s", tt.wantUsed, used)
+			}
+		})
+	}
+}
+
+func TestTxValidatorCountsMintOnce(t *testing.T) {
+	e, err := NewEnforcer(&Policy{MaxZarMintedPerDay: "10000000"}, WithSigningTimeMints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, _ := newServiceAPI(t)
+	v, err := NewTxValidator(e, api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", MintAmount: zar(6000000)}
+	if _, err := NewServiceClient(api, e).CreateStableCoinVault(context.Background(), body); err != nil {
+		t.Fatal(err)
+	}
+	if used := e.Used("maxZarMintedPerDay"); used.Sign() != 0 {
+		t.Fatalf("expected the request not to be counted, got %s", formatAmount(used))
+	}
+	err = v.Check(context.Background(), service.PreparedTx{
+		MethodParameters: service.MethodParameters{To: "0x00000000000000000000000000000000000000a2", Value: "0", Calldata: openLockGemAndDraw(t, "USDTA", zar(6000000))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := formatAmount(e.Used("maxZarMintedPerDay")); used != "6000000" {
+		t.Fatalf("expected 6000000 minted, got %s", used)
+	}
+}
+
+func TestTxValidatorRequiresSigningTimeMints(t *testing.T) {
+	e, err := NewEnforcer(&Policy{MaxZarMintedPerDay: "10000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, _ := newServiceAPI(t)
+	if _, err := NewTxValidator(e, api); err == nil {
+		t.Fatal("expected an error without WithSigningTimeMints")
+	}
+	// the service client keeps counting mints itself
+	body := service.CreateStableCoinVaultJSONRequestBody{IlkName: "USDTA", MintAmount: zar(6000000)}
+	if _, err := NewServiceClient(api, e).CreateStableCoinVault(context.Background(), body); err != nil {
+		t.Fatal(err)
+	}
+	if used := formatAmount(e.Used("maxZarMintedPerDay")); used != "6000000" {
+		t.Fatalf("expected 6000000 minted, got %s", used)
+	}
+}
+
+func TestTxValidatorReserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		release  bool
+		wantUsed string
+	}{
+		{name: "broadcast", wantUsed: "6000000"},
+		{name: "not broadcast", release: true, wantUsed: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEnforcer(&Policy{MaxZarMintedPerDay: "10000000"}, WithSigningTimeMints())
+			if err != nil {
+				t.Fatal(err)
+			}
+			api, _ := newServiceAPI(t)
+			v, err := NewTxValidator(e, api)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release, err := v.Reserve(context.Background(), service.PreparedTx{
+				MethodParameters: service.MethodParameters{To: "0x00000000000000000000000000000000000000a2", Value: "0", Calldata: openLockGemAndDraw(t, "USDTA", zar(6000000))},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.release {
+				release()
+			}
+			if used := formatAmount(e.Used("maxZarMintedPerDay")); used != tt.wantUsed {
+				t.Fatalf("expected %s minted, got %s", tt.wantUsed, used)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    string
+		wantErr bool
+	}{
+		{amount: "12", want: "12"},
+		{amount: " 0.5 ", want: "0.5"},
+		{amount: "1234.000001", want: "1234.000001"},
+		{amount: "-1", wantErr: true},
+		{amount: "1/3", wantErr: true},
+		{amount: "1e3", wantErr: true},
+		{amount: "0x10", wantErr: true},
+		{amount: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.amount)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAmount(%q): expected error %v, got %v", tt.amount, tt.wantErr, err)
+			continue
+		}
+		if err == nil && formatAmount(got) != tt.want {
+			t.Errorf("parseAmount(%q) = %s, expected %s", tt.amount, formatAmount(got), tt.want)
+		}
+	}
+}
+
+// decimalsCaller answers decimals() calls and counts them
+type decimalsCaller struct {
+	decimals int64
+	calls    int
+}
+
+func (c *decimalsCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls++
+	return common.LeftPadBytes(big.NewInt(c.decimals).Bytes(), 32), nil
+}
+
+func TestGemDecimals(t *testing.T) {
+	caller := &decimalsCaller{decimals: 6}
+	decimals := GemDecimals(caller)
+	for i := 0; i < 2; i++ {
+		got, err := decimals(context.Background(), testIlks["USDTA"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 6 {
+			t.Fatalf("expected 6 decimals, got %d", got)
+		}
+	}
+	if caller.calls != 1 {
+		t.Fatalf("expected decimals to be cached, got %d calls", caller.calls)
+	}
+	if _, err := decimals(context.Background(), service.Ilk{Name: "BAD", Gem: "0x12"}); err == nil {
+		t.Fatal("expected an error for an invalid gem address")
+	}
+}
@@ -0,0 +1,299 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	"github.com/zarbanio/zarban-go/money"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// ServiceClient wraps a service.ClientInterface and checks vault creation, minting and
+// collateral withdrawals against a policy before the chain activity is requested
+type ServiceClient struct {
+	service.ClientInterface
+	enforcer *Enforcer
+}
+
+// NewServiceClient creates a ServiceClient enforcing the policy of enforcer on client
+func NewServiceClient(client service.ClientInterface, enforcer *Enforcer) *ServiceClient {
+	return &ServiceClient{ClientInterface: client, enforcer: enforcer}
+}
+
+// CreateStableCoinVault checks the collateral symbol, the minted ZAR and the collateralization
+// ratio of the new vault before requesting the chain activity
+func (c *ServiceClient) CreateStableCoinVault(ctx context.Context, body service.CreateStableCoinVaultJSONRequestBody, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	r, err := c.checkCreateVault(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.CreateStableCoinVault(ctx, body, reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// CreateStableCoinVaultWithBody decodes the JSON body and checks it like CreateStableCoinVault
+func (c *ServiceClient) CreateStableCoinVaultWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	var request service.CreateStableCoinVaultJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.checkCreateVault(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.CreateStableCoinVaultWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// MintZarTransaction checks the minted ZAR and the collateralization ratio of the vault after
+// minting before requesting the chain activity
+func (c *ServiceClient) MintZarTransaction(ctx context.Context, body service.MintZarTransactionJSONRequestBody, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	r, err := c.checkMint(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.MintZarTransaction(ctx, body, reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// MintZarTransactionWithBody decodes the JSON body and checks it like MintZarTransaction
+func (c *ServiceClient) MintZarTransactionWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	var request service.MintZarTransactionJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.checkMint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.MintZarTransactionWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// WithdrawCollateralTransaction checks the collateralization ratio of the vault after the
+// withdrawal before requesting the chain activity
+func (c *ServiceClient) WithdrawCollateralTransaction(ctx context.Context, body service.WithdrawCollateralTransactionJSONRequestBody, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	if err := c.checkWithdrawCollateral(ctx, body); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.WithdrawCollateralTransaction(ctx, body, reqEditors...)
+}
+
+// WithdrawCollateralTransactionWithBody decodes the JSON body and checks it like
+// WithdrawCollateralTransaction
+func (c *ServiceClient) WithdrawCollateralTransactionWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...service.RequestEditorFn) (*http.Response, error) {
+	var request service.WithdrawCollateralTransactionJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkWithdrawCollateral(ctx, request); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.WithdrawCollateralTransactionWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+}
+
+// checkCreateVault checks a vault creation and reserves the minted ZAR
+func (c *ServiceClient) checkCreateVault(ctx context.Context, body service.StablecoinSystemCreateVaultTxRequest) (*reservation, error) {
+	p := c.enforcer.policy
+	minted, err := fromNative(body.MintAmount, zarDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint amount: %w", err)
+	}
+	if len(p.AllowedSymbols) > 0 || p.MinCollateralizationRatio != "" {
+		ilk, err := c.ilk(ctx, body.IlkName)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.enforcer.CheckSymbol(string(ilk.Symbol)); err != nil {
+			return nil, err
+		}
+		if p.MinCollateralizationRatio != "" && minted.Sign() > 0 {
+			if body.CollateralAmount == nil {
+				return nil, fmt.Errorf("collateral amount is required by minCollateralizationRatio")
+			}
+			decimals, err := c.enforcer.collateralDecimals(ctx, *ilk)
+			if err != nil {
+				return nil, err
+			}
+			collateral, err := fromNative(*body.CollateralAmount, decimals)
+			if err != nil {
+				return nil, fmt.Errorf("invalid collateral amount: %w", err)
+			}
+			if err := c.checkRatio(*ilk, collateral, minted); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.enforcer.reserveMint(ctx, minted)
+}
+
+// checkMint checks minting from a vault and reserves the minted ZAR. Without an amount, the
+// amount available to mint is minted.
+func (c *ServiceClient) checkMint(ctx context.Context, body service.StablecoinSystemMintZarTxRequest) (*reservation, error) {
+	p := c.enforcer.policy
+	var minted *big.Rat
+	if body.Amount != nil {
+		amount, err := fromNative(*body.Amount, zarDecimals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mint amount: %w", err)
+		}
+		minted = amount
+	}
+	if minted == nil && p.MaxZarMintedPerDay == "" && p.MinCollateralizationRatio == "" {
+		return nil, nil
+	}
+
+	var vault *service.Vault
+	if minted == nil || p.MinCollateralizationRatio != "" {
+		v, err := c.vault(ctx, body.VaultId)
+		if err != nil {
+			return nil, err
+		}
+		vault = v
+	}
+	if minted == nil {
+		amount, err := currency(vault.AvailableToMint, "ZAR")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get amount available to mint: %w", err)
+		}
+		minted = amount
+	}
+	if p.MinCollateralizationRatio != "" && minted.Sign() > 0 {
+		collateral, debt, err := position(vault)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkRatio(vault.Ilk, collateral, debt.Add(debt, minted)); err != nil {
+			return nil, err
+		}
+	}
+	return c.enforcer.reserveMint(ctx, minted)
+}
+
+// checkWithdrawCollateral checks a collateral withdrawal. Without an amount, the amount
+// available to withdraw is withdrawn.
+func (c *ServiceClient) checkWithdrawCollateral(ctx context.Context, body service.StablecoinSystemWithdrawCollateralTxRequest) error {
+	if c.enforcer.policy.MinCollateralizationRatio == "" {
+		return nil
+	}
+	vault, err := c.vault(ctx, body.VaultId)
+	if err != nil {
+		return err
+	}
+	var withdrawn *big.Rat
+	if body.Amount != nil {
+		decimals, err := c.enforcer.collateralDecimals(ctx, vault.Ilk)
+		if err != nil {
+			return err
+		}
+		withdrawn, err = fromNative(*body.Amount, decimals)
+		if err != nil {
+			return fmt.Errorf("invalid collateral amount: %w", err)
+		}
+	} else {
+		withdrawn, err = currency(vault.AvailableToWithdraw, string(vault.Ilk.Symbol))
+		if err != nil {
+			return fmt.Errorf("failed to get amount available to withdraw: %w", err)
+		}
+	}
+	collateral, debt, err := position(vault)
+	if err != nil {
+		return err
+	}
+	return c.checkRatio(vault.Ilk, collateral.Sub(collateral, withdrawn), debt)
+}
+
+// checkRatio checks the collateralization ratio of a vault of ilk holding collateral and debt
+func (c *ServiceClient) checkRatio(ilk service.Ilk, collateral, debt *big.Rat) error {
+	if debt.Sign() == 0 {
+		return nil
+	}
+	price, err := currency(ilk.Price, "ZAR")
+	if err != nil {
+		return fmt.Errorf("failed to get %s price: %w", ilk.Name, err)
+	}
+	ratio := new(big.Rat).Mul(collateral, price)
+	ratio.Quo(ratio, debt)
+	return c.enforcer.CheckCollateralization(ratio)
+}
+
+// ilk fetches an ilk by name
+func (c *ServiceClient) ilk(ctx context.Context, name string) (*service.Ilk, error) {
+	return getIlk(ctx, c.ClientInterface, name)
+}
+
+// getIlk fetches an ilk by name with client
+func getIlk(ctx context.Context, client service.ClientInterface, name string) (*service.Ilk, error) {
+	httpResponse, err := client.GetIlkByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ilk %s: %w", name, err)
+	}
+	var ilk service.Ilk
+	if err := service.HandleAPIResponse(ctx, httpResponse, &ilk); err != nil {
+		return nil, fmt.Errorf("failed to get ilk %s: %w", name, err)
+	}
+	return &ilk, nil
+}
+
+// vault fetches a vault by id
+func (c *ServiceClient) vault(ctx context.Context, id int) (*service.Vault, error) {
+	httpResponse, err := c.ClientInterface.GetVaultById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault %d: %w", id, err)
+	}
+	var vault service.Vault
+	if err := service.HandleAPIResponse(ctx, httpResponse, &vault); err != nil {
+		return nil, fmt.Errorf("failed to get vault %d: %w", id, err)
+	}
+	return &vault, nil
+}
+
+// position returns the collateral locked in a vault and its ZAR debt
+func position(vault *service.Vault) (*big.Rat, *big.Rat, error) {
+	collateral, err := currency(vault.CollateralLocked, string(vault.Ilk.Symbol))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get collateral of vault %d: %w", vault.Id, err)
+	}
+	debt, err := currency(vault.Debt, "ZAR")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get debt of vault %d: %w", vault.Id, err)
+	}
+	return collateral, debt, nil
+}
+
+// currency returns the amount of a Currency in the given denomination
+func currency(c service.Currency, denomination string) (*big.Rat, error) {
+	amount, err := money.FromService(c).Get(money.Denomination(denomination))
+	if err != nil {
+		return nil, err
+	}
+	return amount.Rat(), nil
+}
+
+// decodeBody reads a JSON request body so it can be checked and sent
+func decodeBody(body io.Reader, v interface{}) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("failed to decode request body: %w", err)
+	}
+	return data, nil
+}
+
+// settle keeps the amounts reserved for a request that was accepted and releases them otherwise
+func settle(ctx context.Context, r *reservation, resp *http.Response, err error) (*http.Response, error) {
+	if r != nil && (err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		r.release(ctx)
+	}
+	return resp, err
+}
@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/zarbanio/zarban-go/calldata"
+	"github.com/zarbanio/zarban-go/money"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// mintArgs maps the proxy actions minting ZAR to their argument holding the minted wad
+var mintArgs = map[string]string{
+	"draw":                      "wad",
+	"lockETHAndDraw":            "wadD",
+	"openLockETHAndDraw":        "wadD",
+	"openLockETHAndGiveToProxy": "wadD",
+	"lockGemAndDraw":            "wadD",
+	"openLockGemAndDraw":        "wadD",
+	"openLockGemAndGiveToProxy": "wadD",
+}
+
+// TxValidator enforces a policy on prepared transactions right before they are signed, so that
+// chain activities requested without a ServiceClient are checked as well. It decodes the proxy
+// actions called by a transaction, checks the collateral of opened vaults against
+// allowedSymbols and counts the minted ZAR against maxZarMintedPerDay. It implements
+// executor.TxReserver, so that an executor counts minted ZAR only once the transaction minting
+// it is broadcast. Minted ZAR stays counted even if the transaction fails on chain.
+//
+// Its enforcer must be created WithSigningTimeMints, so that a ServiceClient sharing the
+// enforcer leaves counting minted ZAR to the TxValidator.
+type TxValidator struct {
+	enforcer *Enforcer
+	client   service.ClientInterface
+	decoder  *calldata.Decoder
+}
+
+// NewTxValidator creates a TxValidator enforcing the policy of enforcer, looking ilks up with client
+func NewTxValidator(enforcer *Enforcer, client service.ClientInterface) (*TxValidator, error) {
+	if enforcer == nil {
+		return nil, errors.New("enforcer is required")
+	}
+	if client == nil {
+		return nil, errors.New("client is required")
+	}
+	if !enforcer.signing {
+		return nil, errors.New("enforcer must be created WithSigningTimeMints")
+	}
+	return &TxValidator{enforcer: enforcer, client: client, decoder: calldata.NewDecoder()}, nil
+}
+
+// Check implements executor.TxValidator. The minted ZAR stays counted, use Reserve to count it
+// only if the transaction is sent. Calls that cannot be decoded are left to the guard.
+func (v *TxValidator) Check(ctx context.Context, preparedTx service.PreparedTx) error {
+	_, err := v.Reserve(ctx, preparedTx)
+	return err
+}
+
+// Reserve implements executor.TxReserver. The returned function gives the minted ZAR back to the
+// daily limit.
+func (v *TxValidator) Reserve(ctx context.Context, preparedTx service.PreparedTx) (func(), error) {
+	data := preparedTx.MethodParameters.Calldata
+	if data == "" || data == "0x" {
+		return nil, nil
+	}
+	method, err := v.decoder.DecodeHex(data)
+	if errors.Is(err, calldata.ErrUnknownMethod) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	minted := new(big.Rat)
+	if err := v.check(ctx, method, minted); err != nil {
+		return nil, err
+	}
+	if minted.Sign() == 0 {
+		return nil, nil
+	}
+	r, err := v.enforcer.reserve(ctx, map[string]string{"maxZarMintedPerDay": v.enforcer.policy.MaxZarMintedPerDay}, minted, true)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return func() {
+		// the caller may have given up already, the usage is saved regardless
+		r.release(context.Background())
+	}, nil
+}
+
+// check checks a call and the calls nested in it, adding the ZAR they mint to minted
+func (v *TxValidator) check(ctx context.Context, method *calldata.Method, minted *big.Rat) error {
+	for _, arg := range method.Args {
+		for _, call := range arg.Calls {
+			if err := v.check(ctx, call, minted); err != nil {
+				return err
+			}
+		}
+	}
+	if method.Contract != "proxy_actions" {
+		return nil
+	}
+
+	if arg, ok := method.Arg("ilk"); ok && len(v.enforcer.policy.AllowedSymbols) > 0 {
+		name, ok := arg.Value.([32]byte)
+		if !ok {
+			return fmt.Errorf("unexpected ilk argument of %s", method.Signature)
+		}
+		ilk, err := getIlk(ctx, v.client, strings.TrimRight(string(name[:]), "\x00"))
+		if err != nil {
+			return err
+		}
+		if err := v.enforcer.CheckSymbol(string(ilk.Symbol)); err != nil {
+			return err
+		}
+	}
+	if name, ok := mintArgs[method.Name]; ok {
+		arg, ok := method.Arg(name)
+		if !ok {
+			return fmt.Errorf("missing %s argument of %s", name, method.Signature)
+		}
+		wad, ok := arg.Value.(*big.Int)
+		if !ok {
+			return fmt.Errorf("unexpected %s argument of %s", name, method.Signature)
+		}
+		minted.Add(minted, money.New(wad, zarDecimals).Rat())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Usage is an amount counted against the daily limit of a rule. Amount is an exact fraction
+// like "3/2" or an integer.
+type Usage struct {
+	Rule   string    `json:"rule"`
+	At     time.Time `json:"at"`
+	Amount string    `json:"amount"`
+}
+
+// UsageStore persists the amounts counted against daily limits
+type UsageStore interface {
+	// Load returns the saved usage, or none if nothing was saved yet
+	Load(ctx context.Context) ([]Usage, error)
+
+	// Save replaces the saved usage
+	Save(ctx context.Context, usage []Usage) error
+}
+
+// FileUsageStore keeps the usage in a JSON file. The file is replaced atomically, so a crash
+// never leaves a partially written file.
+type FileUsageStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileUsageStore creates a FileUsageStore writing to path, creating its directory if needed
+func NewFileUsageStore(path string) (*FileUsageStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	return &FileUsageStore{path: path}, nil
+}
+
+// Load implements UsageStore
+func (s *FileUsageStore) Load(_ context.Context) ([]Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+	var usage []Usage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("failed to decode usage %s: %w", s.path, err)
+	}
+	return usage, nil
+}
+
+// Save implements UsageStore
+func (s *FileUsageStore) Save(_ context.Context, usage []Usage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create usage file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync usage: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// WalletClient wraps a wallet.ClientInterface and checks withdrawals, swaps and redemptions
+// against a policy before they are sent
+type WalletClient struct {
+	wallet.ClientInterface
+	enforcer *Enforcer
+}
+
+// NewWalletClient creates a WalletClient enforcing the policy of enforcer on client
+func NewWalletClient(client wallet.ClientInterface, enforcer *Enforcer) *WalletClient {
+	return &WalletClient{ClientInterface: client, enforcer: enforcer}
+}
+
+// RequestWithdrawal checks the symbol and the daily withdrawal limits of the network before
+// requesting the withdrawal
+func (c *WalletClient) RequestWithdrawal(ctx context.Context, body wallet.RequestWithdrawalJSONRequestBody, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	r, err := c.checkWithdrawal(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.RequestWithdrawal(ctx, body, reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// RequestWithdrawalWithBody decodes the JSON body and checks it like RequestWithdrawal
+func (c *WalletClient) RequestWithdrawalWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	var request wallet.RequestWithdrawalJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.checkWithdrawal(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.RequestWithdrawalWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// SwapCoins checks the symbols swapped from and to before sending the swap request
+func (c *WalletClient) SwapCoins(ctx context.Context, body wallet.SwapCoinsJSONRequestBody, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	if err := c.checkSwap(body); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.SwapCoins(ctx, body, reqEditors...)
+}
+
+// SwapCoinsWithBody decodes the JSON body and checks it like SwapCoins
+func (c *WalletClient) SwapCoinsWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	var request wallet.SwapCoinsJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSwap(request); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.SwapCoinsWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+}
+
+// RedeemZar checks the daily redemption limit before requesting the redemption
+func (c *WalletClient) RedeemZar(ctx context.Context, body wallet.RedeemZarJSONRequestBody, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	r, err := c.checkRedemption(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.RedeemZar(ctx, body, reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// RedeemZarWithBody decodes the JSON body and checks it like RedeemZar
+func (c *WalletClient) RedeemZarWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...wallet.RequestEditorFn) (*http.Response, error) {
+	var request wallet.RedeemZarJSONRequestBody
+	data, err := decodeBody(body, &request)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.checkRedemption(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.ClientInterface.RedeemZarWithBody(ctx, contentType, bytes.NewReader(data), reqEditors...)
+	return settle(ctx, r, resp, err)
+}
+
+// checkWithdrawal checks a withdrawal and reserves its amount
+func (c *WalletClient) checkWithdrawal(ctx context.Context, body wallet.WithdrawRequestBody) (*reservation, error) {
+	if err := c.enforcer.CheckSymbol(body.Symbol); err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(body.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal amount: %w", err)
+	}
+	return c.enforcer.reserveWithdrawal(ctx, body.Network, body.Symbol, amount)
+}
+
+// checkSwap checks the symbols of a swap
+func (c *WalletClient) checkSwap(body wallet.SwapRequest) error {
+	for _, symbol := range []*string{body.In, body.Out} {
+		if symbol == nil {
+			continue
+		}
+		if err := c.enforcer.CheckSymbol(*symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRedemption checks a redemption and reserves its amount
+func (c *WalletClient) checkRedemption(ctx context.Context, body wallet.RedemptionRequest) (*reservation, error) {
+	if err := c.enforcer.CheckSymbol("ZAR"); err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(body.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redemption amount: %w", err)
+	}
+	return c.enforcer.reserveRedemption(ctx, amount)
+}
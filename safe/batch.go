@@ -0,0 +1,251 @@
+// Package safe converts chain activities into transactions a Safe multisig can execute,
+// either as a Safe Transaction Builder batch file or as a single MultiSend call
+package safe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+const (
+	// batchVersion is the batch file version understood by the Transaction Builder
+	batchVersion = "1.0"
+
+	// txBuilderVersion is the Transaction Builder release the batch format is taken from
+	txBuilderVersion = "1.16.5"
+
+	// defaultLocale is the label used for the batch description
+	defaultLocale = "en-US"
+)
+
+// ErrSignRequest is returned for sign request steps, which a Safe cannot execute
+var ErrSignRequest = errors.New("sign request steps cannot be executed by a safe")
+
+// Batch is a Safe Transaction Builder batch file
+type Batch struct {
+	Version      string        `json:"version"`
+	ChainID      string        `json:"chainId"`
+	CreatedAt    int64         `json:"createdAt"`
+	Meta         Meta          `json:"meta"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Meta describes a batch
+type Meta struct {
+	Name                    string `json:"name"`
+	Description             string `json:"description"`
+	TxBuilderVersion        string `json:"txBuilderVersion"`
+	CreatedFromSafeAddress  string `json:"createdFromSafeAddress"`
+	CreatedFromOwnerAddress string `json:"createdFromOwnerAddress"`
+	Checksum                string `json:"checksum,omitempty"`
+}
+
+// Transaction is a single call of a batch. Value is in wei.
+type Transaction struct {
+	To    string `json:"to"`
+	Value string `json:"value"`
+	Data  string `json:"data"`
+}
+
+// BatchOption allows setting custom parameters on a Batch
+type BatchOption func(*Batch) error
+
+// WithName sets the name of the batch shown by the Transaction Builder
+func WithName(name string) BatchOption {
+	return func(b *Batch) error {
+		b.Meta.Name = name
+		return nil
+	}
+}
+
+// WithDescription sets the description of the batch, defaults to the en-US labels of the steps
+func WithDescription(description string) BatchOption {
+	return func(b *Batch) error {
+		b.Meta.Description = description
+		return nil
+	}
+}
+
+// WithOwner records the owner proposing the batch
+func WithOwner(owner common.Address) BatchOption {
+	return func(b *Batch) error {
+		b.Meta.CreatedFromOwnerAddress = owner.Hex()
+		return nil
+	}
+}
+
+// NewBatch converts the remaining PreparedTx steps of a chain activity into a batch for the
+// given safe. The activity must have been requested for the safe address, and must not contain
+// sign request steps.
+func NewBatch(safe common.Address, chainID *big.Int, activity service.ChainActivity, opts ...BatchOption) (*Batch, error) {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid chain id: %v", chainID)
+	}
+	first := activity.StepNumber
+	if first < 1 {
+		first = 1
+	}
+
+	var labels []string
+	b := Batch{
+		Version:   batchVersion,
+		ChainID:   chainID.String(),
+		CreatedAt: time.Now().UnixMilli(),
+		Meta: Meta{
+			Name:                   "Zarban transactions batch",
+			TxBuilderVersion:       txBuilderVersion,
+			CreatedFromSafeAddress: safe.Hex(),
+		},
+	}
+	for number := first; number <= len(activity.Steps); number++ {
+		step := activity.Steps[number-1]
+		if step.Type != service.ChainActivityStepTypePreparedTx {
+			return nil, fmt.Errorf("step %d: %w: %q", number, ErrSignRequest, step.Type)
+		}
+		preparedTx, err := step.Data.AsPreparedTx()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: failed to decode prepared tx: %w", number, err)
+		}
+		tx, err := newTransaction(preparedTx.MethodParameters)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", number, err)
+		}
+		b.Transactions = append(b.Transactions, tx)
+		if label := preparedTx.Label[defaultLocale]; label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(b.Transactions) == 0 {
+		return nil, errors.New("chain activity has no transactions")
+	}
+	b.Meta.Description = strings.Join(labels, "\n")
+
+	for _, o := range opts {
+		if err := o(&b); err != nil {
+			return nil, err
+		}
+	}
+	checksum, err := b.checksum()
+	if err != nil {
+		return nil, err
+	}
+	b.Meta.Checksum = checksum
+	return &b, nil
+}
+
+// Marshal returns the batch file as indented JSON
+func (b *Batch) Marshal() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// newTransaction validates the method parameters of a prepared transaction
+func newTransaction(params service.MethodParameters) (Transaction, error) {
+	if !common.IsHexAddress(params.To) {
+		return Transaction{}, fmt.Errorf("invalid recipient address: %q", params.To)
+	}
+	value := new(big.Int)
+	if params.Value != "" {
+		if _, ok := value.SetString(params.Value, 10); !ok || value.Sign() < 0 {
+			return Transaction{}, fmt.Errorf("invalid value: %q", params.Value)
+		}
+	}
+	data := params.Calldata
+	if data == "" {
+		data = "0x"
+	}
+	if _, err := hexutil.Decode(data); err != nil {
+		return Transaction{}, fmt.Errorf("failed to decode calldata: %w", err)
+	}
+	return Transaction{
+		To:    common.HexToAddress(params.To).Hex(),
+		Value: value.String(),
+		Data:  strings.ToLower(data),
+	}, nil
+}
+
+// checksum computes the batch checksum the way the Transaction Builder does: the keccak256
+// hash of its own serialization of the file, without the checksum and with a null name
+func (b *Batch) checksum() (string, error) {
+	c := *b
+	c.Meta.Checksum = ""
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode batch: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value map[string]interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("failed to decode batch: %w", err)
+	}
+	if meta, ok := value["meta"].(map[string]interface{}); ok {
+		meta["name"] = nil
+	}
+
+	var serialized strings.Builder
+	if err := serialize(&serialized, value); err != nil {
+		return "", err
+	}
+	return hexutil.Encode(crypto.Keccak256([]byte(serialized.String()))), nil
+}
+
+// serialize writes a JSON value with the key ordering and layout used by the Transaction
+// Builder checksum: objects are written as their sorted keys followed by their values
+func serialize(w *strings.Builder, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		w.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := serialize(w, element); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.WriteByte('{')
+		if err := writeJSON(w, keys); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := serialize(w, v[key]); err != nil {
+				return err
+			}
+			w.WriteByte(',')
+		}
+		w.WriteByte('}')
+	default:
+		return writeJSON(w, v)
+	}
+	return nil
+}
+
+// writeJSON writes value as JSON without escaping HTML characters, like JSON.stringify
+func writeJSON(w *strings.Builder, value interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+	w.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+	return nil
+}
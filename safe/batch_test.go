@@ -0,0 +1,164 @@
+package safe
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var testSafe = common.HexToAddress("0x5afe5afE5afE5afE5afE5aFe5aFe5Afe5Afe5AfE")
+
+// step returns a chain activity step of the given type
+func step(t *testing.T, stepType service.ChainActivityStepType, to, value, data, label string) service.ChainActivityStep {
+	t.Helper()
+	s := service.ChainActivityStep{Type: stepType}
+	if stepType != service.ChainActivityStepTypePreparedTx {
+		return s
+	}
+	preparedTx := service.PreparedTx{MethodParameters: service.MethodParameters{To: to, Value: value, Calldata: data}}
+	if label != "" {
+		preparedTx.Label = map[string]string{defaultLocale: label, "fa-IR": "برچسب"}
+	}
+	if err := s.Data.FromPreparedTx(preparedTx); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestBatchChecksum checks the checksum against the serialization of the Transaction Builder,
+// which lists the sorted keys of an object before its values, leaves HTML characters unescaped
+// and hashes the file with a null name and without its checksum
+func TestBatchChecksum(t *testing.T) {
+	activity := service.ChainActivity{
+		NumberOfSteps: 2,
+		StepNumber:    1,
+		Steps: []service.ChainActivityStep{
+			step(t, service.ChainActivityStepTypePreparedTx, "0x00000000000000000000000000000000000000aa", "0", "0x095EA7B3", "Approve DAI & <ZAR>"),
+			step(t, service.ChainActivityStepTypePreparedTx, "0x00000000000000000000000000000000000000bb", "1000", "", "Open vault"),
+		},
+	}
+	b, err := NewBatch(testSafe, big.NewInt(1), activity, WithName("Open vault"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.CreatedAt = 1700000000000
+	checksum, err := b.checksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serialized := `{["chainId","createdAt","meta","transactions","version"]"1",1700000000000,` +
+		`{["createdFromOwnerAddress","createdFromSafeAddress","description","name","txBuilderVersion"]` +
+		`"","0x5afe5afE5afE5afE5afE5aFe5aFe5Afe5Afe5AfE","Approve DAI & <ZAR>\nOpen vault",null,"1.16.5",},` +
+		`[{["data","to","value"]"0x095ea7b3","0x00000000000000000000000000000000000000AA","0",},` +
+		`{["data","to","value"]"0x","0x00000000000000000000000000000000000000bb","1000",}],"1.0",}`
+	if want := hexutil.Encode(crypto.Keccak256([]byte(serialized))); checksum != want {
+		t.Fatalf("expected checksum %s, got %s", want, checksum)
+	}
+
+	// the Transaction Builder validates an imported file by hashing it without its checksum
+	b.Meta.Checksum = checksum
+	file, err := b.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported Batch
+	if err := json.Unmarshal(file, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if imported.Meta.Checksum != checksum {
+		t.Fatalf("expected checksum %s in the file, got %s", checksum, imported.Meta.Checksum)
+	}
+	imported.Meta.Name = "Renamed"
+	if recomputed, err := imported.checksum(); err != nil || recomputed != checksum {
+		t.Fatalf("expected the checksum not to depend on the name, got %s, %v", recomputed, err)
+	}
+	imported.Transactions[1].Value = "1001"
+	if recomputed, err := imported.checksum(); err != nil || recomputed == checksum {
+		t.Fatalf("expected the checksum to change with a transaction, got %s, %v", recomputed, err)
+	}
+}
+
+func TestNewBatch(t *testing.T) {
+	to := "0x00000000000000000000000000000000000000aa"
+	tests := []struct {
+		name     string
+		activity service.ChainActivity
+		chainID  *big.Int
+		wantTxs  int
+		wantErr  error
+		errOnly  bool
+		wantDesc string
+	}{
+		{
+			name: "remaining steps",
+			activity: service.ChainActivity{StepNumber: 2, Steps: []service.ChainActivityStep{
+				step(t, service.ChainActivityStepTypePersonalSignRequest, "", "", "", ""),
+				step(t, service.ChainActivityStepTypePreparedTx, to, "", "0x", "Deposit"),
+			}},
+			chainID:  big.NewInt(1),
+			wantTxs:  1,
+			wantDesc: "Deposit",
+		},
+		{
+			name: "sign request",
+			activity: service.ChainActivity{StepNumber: 1, Steps: []service.ChainActivityStep{
+				step(t, service.ChainActivityStepTypePersonalSignRequest, "", "", "", ""),
+			}},
+			chainID: big.NewInt(1),
+			wantErr: ErrSignRequest,
+		},
+		{
+			name:     "no steps",
+			activity: service.ChainActivity{StepNumber: 1},
+			chainID:  big.NewInt(1),
+			errOnly:  true,
+		},
+		{
+			name: "negative value",
+			activity: service.ChainActivity{StepNumber: 1, Steps: []service.ChainActivityStep{
+				step(t, service.ChainActivityStepTypePreparedTx, to, "-1", "0x", ""),
+			}},
+			chainID: big.NewInt(1),
+			errOnly: true,
+		},
+		{
+			name: "invalid chain id",
+			activity: service.ChainActivity{StepNumber: 1, Steps: []service.ChainActivityStep{
+				step(t, service.ChainActivityStepTypePreparedTx, to, "", "0x", ""),
+			}},
+			errOnly: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBatch(testSafe, tt.chainID, tt.activity)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.errOnly:
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if len(b.Transactions) != tt.wantTxs || b.Meta.Description != tt.wantDesc {
+				t.Fatalf("expected %d transactions described as %q, got %+v", tt.wantTxs, tt.wantDesc, b)
+			}
+			if b.Meta.Checksum == "" {
+				t.Fatal("expected a checksum")
+			}
+		})
+	}
+}
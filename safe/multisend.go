@@ -0,0 +1,83 @@
+package safe
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Operation is the kind of call a Safe makes
+type Operation uint8
+
+const (
+	// Call is a regular call
+	Call Operation = 0
+
+	// DelegateCall runs the target code in the context of the Safe
+	DelegateCall Operation = 1
+)
+
+// MultiSendCallOnlyAddress is the canonical deployment of MultiSendCallOnly v1.3.0, which
+// refuses nested delegate calls
+var MultiSendCallOnlyAddress = common.HexToAddress("0x40A2aCCbd92BCA938b02010E17A5b8929b49130D")
+
+// multiSendSelector is the selector of multiSend(bytes)
+var multiSendSelector = crypto.Keccak256([]byte("multiSend(bytes)"))[:4]
+
+// SafeTx is a single transaction to propose to a Safe
+type SafeTx struct {
+	To        common.Address
+	Value     *big.Int
+	Data      []byte
+	Operation Operation
+}
+
+// MultiSend encodes the transactions of the batch as one delegate call to the MultiSend
+// contract at multiSend, typically MultiSendCallOnlyAddress
+func (b *Batch) MultiSend(multiSend common.Address) (SafeTx, error) {
+	data, err := EncodeMultiSend(b.Transactions)
+	if err != nil {
+		return SafeTx{}, err
+	}
+	return SafeTx{
+		To:        multiSend,
+		Value:     new(big.Int),
+		Data:      data,
+		Operation: DelegateCall,
+	}, nil
+}
+
+// EncodeMultiSend returns the multiSend(bytes) calldata executing txs in order. Each call is
+// packed as operation (1 byte), to (20 bytes), value (32 bytes), data length (32 bytes) and data.
+func EncodeMultiSend(txs []Transaction) ([]byte, error) {
+	var packed []byte
+	for i, tx := range txs {
+		if !common.IsHexAddress(tx.To) {
+			return nil, fmt.Errorf("transaction %d: invalid recipient address: %q", i, tx.To)
+		}
+		value, ok := new(big.Int).SetString(tx.Value, 10)
+		if !ok || value.Sign() < 0 || value.BitLen() > 256 {
+			return nil, fmt.Errorf("transaction %d: invalid value: %q", i, tx.Value)
+		}
+		data, err := hexutil.Decode(tx.Data)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: failed to decode data: %w", i, err)
+		}
+		packed = append(packed, byte(Call))
+		packed = append(packed, common.HexToAddress(tx.To).Bytes()...)
+		packed = append(packed, math.U256Bytes(value)...)
+		packed = append(packed, math.U256Bytes(big.NewInt(int64(len(data))))...)
+		packed = append(packed, data...)
+	}
+
+	// abi encoding of a single dynamic bytes argument: offset, length, padded content
+	calldata := append([]byte{}, multiSendSelector...)
+	calldata = append(calldata, math.U256Bytes(big.NewInt(32))...)
+	calldata = append(calldata, math.U256Bytes(big.NewInt(int64(len(packed))))...)
+	calldata = append(calldata, common.RightPadBytes(packed, (len(packed)+31)/32*32)...)
+	return calldata, nil
+}
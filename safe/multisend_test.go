@@ -0,0 +1,67 @@
+package safe
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestEncodeMultiSend(t *testing.T) {
+	txs := []Transaction{
+		{To: "0x00000000000000000000000000000000000000aa", Value: "1", Data: "0x"},
+		{To: "0x00000000000000000000000000000000000000bb", Value: "0", Data: "0xdeadbeef"},
+	}
+	// multiSend(bytes) with 174 packed bytes, padded to 192
+	want := "0x8d80ff0a" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"00000000000000000000000000000000000000000000000000000000000000ae" +
+		// call, to, value 1, no data
+		"00" + "00000000000000000000000000000000000000aa" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		// call, to, value 0, 4 bytes of data
+		"00" + "00000000000000000000000000000000000000bb" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"deadbeef" +
+		strings.Repeat("00", 18)
+
+	data, err := EncodeMultiSend(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hexutil.Encode(data); got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	b := &Batch{Transactions: txs}
+	safeTx, err := b.MultiSend(MultiSendCallOnlyAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if safeTx.To != MultiSendCallOnlyAddress || safeTx.Operation != DelegateCall || safeTx.Value.Sign() != 0 || hexutil.Encode(safeTx.Data) != want {
+		t.Fatalf("unexpected safe transaction %+v", safeTx)
+	}
+}
+
+func TestEncodeMultiSendInvalid(t *testing.T) {
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), 256).String()
+	tests := []struct {
+		name string
+		tx   Transaction
+	}{
+		{name: "invalid recipient", tx: Transaction{To: "0x1234", Value: "0", Data: "0x"}},
+		{name: "negative value", tx: Transaction{To: "0x00000000000000000000000000000000000000aa", Value: "-1", Data: "0x"}},
+		{name: "value above 256 bits", tx: Transaction{To: "0x00000000000000000000000000000000000000aa", Value: tooLarge, Data: "0x"}},
+		{name: "invalid data", tx: Transaction{To: "0x00000000000000000000000000000000000000aa", Value: "0", Data: "0xzz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeMultiSend([]Transaction{tt.tx}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
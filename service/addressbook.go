@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AddressBook caches the protocol contract addresses returned by GetAllAddresses
+type AddressBook struct {
+	client ClientInterface
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	addresses []Address
+	labels    map[string]string
+	fetchedAt time.Time
+}
+
+// NewAddressBook creates an AddressBook that refreshes its cache once ttl has elapsed.
+// A zero ttl caches the addresses for the lifetime of the AddressBook.
+func NewAddressBook(client ClientInterface, ttl time.Duration) *AddressBook {
+	return &AddressBook{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Addresses returns all known protocol addresses
+func (b *AddressBook) Addresses(ctx context.Context) ([]Address, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.load(ctx); err != nil {
+		return nil, err
+	}
+	addresses := make([]Address, len(b.addresses))
+	copy(addresses, b.addresses)
+	return addresses, nil
+}
+
+// Label returns the label of a protocol address and whether the address is known
+func (b *AddressBook) Label(ctx context.Context, address string) (string, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.load(ctx); err != nil {
+		return "", false, err
+	}
+	label, ok := b.labels[strings.ToLower(address)]
+	return label, ok, nil
+}
+
+// Refresh discards the cached addresses and fetches them again
+func (b *AddressBook) Refresh(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.labels = nil
+	return b.load(ctx)
+}
+
+// load fetches the addresses if the cache is empty or expired, b.mu must be held
+func (b *AddressBook) load(ctx context.Context) error {
+	if b.labels != nil && (b.ttl == 0 || time.Since(b.fetchedAt) < b.ttl) {
+		return nil
+	}
+
+	httpResponse, err := b.client.GetAllAddresses(ctx, &GetAllAddressesParams{})
+	if err != nil {
+		return err
+	}
+	var response AddressResponse
+	if err := HandleAPIResponse(ctx, httpResponse, &response); err != nil {
+		return err
+	}
+
+	labels := make(map[string]string, len(response.Data))
+	for _, address := range response.Data {
+		labels[strings.ToLower(address.Address)] = address.Label
+	}
+	b.addresses = response.Data
+	b.labels = labels
+	b.fetchedAt = time.Now()
+	return nil
+}
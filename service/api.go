@@ -0,0 +1,588 @@
+// Code generated by zarban-apigen from api_specs/service.openapi.yaml. DO NOT EDIT.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Operation is an operation of the API, as declared in the OpenAPI spec
+type Operation struct {
+	ID     string
+	Method string
+	Path   string
+}
+
+// operations lists the operations of the API in the order of the spec
+var operations = []Operation{
+	{ID: "getUnfilledOrdersWebsocket", Method: "GET", Path: "/v2/ws"},
+	{ID: "getSingleTokenPermit", Method: "GET", Path: "/v2/permit/single"},
+	{ID: "getSwapQuote", Method: "POST", Path: "/v2/swap/quote"},
+	{ID: "multiStepSwap", Method: "POST", Path: "/v2/swap/tx/swap"},
+	{ID: "syncOrder", Method: "POST", Path: "/v2/orders/sync"},
+	{ID: "getUnfilledOrders", Method: "GET", Path: "/v2/orders"},
+	{ID: "getAllAddresses", Method: "GET", Path: "/v2/addresses"},
+	{ID: "createLendingPoolDeposit", Method: "POST", Path: "/v2/lendingpool/tx/deposit"},
+	{ID: "createLendingPoolWithdraw", Method: "POST", Path: "/v2/lendingpool/tx/withdraw"},
+	{ID: "createLendingPoolBorrow", Method: "POST", Path: "/v2/lendingpool/tx/borrow"},
+	{ID: "createLendingPoolRepay", Method: "POST", Path: "/v2/lendingpool/tx/repay"},
+	{ID: "setLendingPoolAssetCollateral", Method: "POST", Path: "/v2/lendingpool/tx/useassetascollateral"},
+	{ID: "createStableCoinVault", Method: "POST", Path: "/v2/stablecoinsystem/tx/createvault"},
+	{ID: "depositStableCoinCollateral", Method: "POST", Path: "/v2/stablecoinsystem/tx/depositcollateral"},
+	{ID: "withdrawCollateralTransaction", Method: "POST", Path: "/v2/stablecoinsystem/tx/withdrawcollateral"},
+	{ID: "mintZarTransaction", Method: "POST", Path: "/v2/stablecoinsystem/tx/mintzar"},
+	{ID: "repayZarTransaction", Method: "POST", Path: "/v2/stablecoinsystem/tx/repayzar"},
+	{ID: "liquidateVaultTransaction", Method: "POST", Path: "/v2/stablecoinsystem/tx/bark"},
+	{ID: "approveAndJoinZarTransaction", Method: "POST", Path: "/v2/stablecoinsystem/auctions/tx/zarjoin"},
+	{ID: "exitZarTransaction", Method: "POST", Path: "/v2/stablecoinsystem/auctions/tx/zarexit"},
+	{ID: "exitGemTransaction", Method: "POST", Path: "/v2/stablecoinsystem/auctions/tx/gemexit"},
+	{ID: "resetAuctionTransaction", Method: "POST", Path: "/v2/stablecoinsystem/auctions/tx/redo"},
+	{ID: "takeAuctionTransaction", Method: "POST", Path: "/v2/stablecoinsystem/auctions/tx/take"},
+	{ID: "fetchReserveDataByAsset", Method: "GET", Path: "/v2/lendingpool/reserves"},
+	{ID: "getAllIlks", Method: "GET", Path: "/v2/ilks"},
+	{ID: "getIlkByName", Method: "GET", Path: "/v2/ilks/{name}"},
+	{ID: "getCollectorData", Method: "GET", Path: "/v2/stats"},
+	{ID: "getUserDeposits", Method: "GET", Path: "/v2/lendingpool/deposits"},
+	{ID: "getUserBorrows", Method: "GET", Path: "/v2/lendingpool/borrows"},
+	{ID: "getLogsByTransactionHash", Method: "GET", Path: "/v2/logs/{txHash}"},
+	{ID: "listPrices", Method: "GET", Path: "/v2/prices"},
+	{ID: "getVaultById", Method: "GET", Path: "/v2/vaults/{id}"},
+	{ID: "getVaultEventsById", Method: "GET", Path: "/v2/vaults/{id}/events"},
+	{ID: "getVaultsByOwner", Method: "GET", Path: "/v2/vaults"},
+	{ID: "getAccountByAddress", Method: "GET", Path: "/v2/accounts/{address}"},
+	{ID: "getScoreboard", Method: "GET", Path: "/v2/points/scoreboard"},
+	{ID: "stakeToStakingContract", Method: "POST", Path: "/v2/staking/tx/stake"},
+	{ID: "withdrawStakedAsset", Method: "POST", Path: "/v2/staking/tx/withdraw"},
+	{ID: "collectStakingReward", Method: "POST", Path: "/v2/staking/tx/collectreward"},
+	{ID: "getUserStakingStats", Method: "GET", Path: "/v2/staking/stats"},
+	{ID: "getStakingPlans", Method: "GET", Path: "/v2/staking/plans"},
+	{ID: "collectLendingpoolRewards", Method: "POST", Path: "/v2/lendingpool/tx/collectreward"},
+	{ID: "createUniswapV3Position", Method: "POST", Path: "/v2/uniswap/tx/createposition"},
+	{ID: "collectUniswapV3Rewards", Method: "POST", Path: "/v2/uniswap/tx/collectreward"},
+	{ID: "increaseUniswapV3PositionLiquidity", Method: "POST", Path: "/v2/uniswap/tx/increaseliquidity"},
+	{ID: "decreaseUniswapV3PositionLiquidity", Method: "POST", Path: "/v2/uniswap/tx/decreaseliquidity"},
+	{ID: "burnUniswapV3PositionNFT", Method: "POST", Path: "/v2/uniswap/tx/burn"},
+	{ID: "getUserUniswapV3Positions", Method: "GET", Path: "/v2/uniswap/positions"},
+	{ID: "getUniswapV3PositionDetails", Method: "GET", Path: "/v2/uniswap/positions/{tokenId}"},
+	{ID: "stakeUniswapV3PositionNFT", Method: "POST", Path: "/v2/uniswap/tx/stake"},
+	{ID: "unstakeUniswapV3PositionNFT", Method: "POST", Path: "/v2/uniswap/tx/unstake"},
+	{ID: "collectUniswapV3StakingRewards", Method: "POST", Path: "/v2/uniswap/tx/collectstakingreward"},
+	{ID: "getUniswapV3PoolByTokens", Method: "GET", Path: "/v2/uniswap/pools"},
+	{ID: "getUniswapV3PoolTicksPrices", Method: "GET", Path: "/v2/uniswap/pools/ticks"},
+	{ID: "getUniswapV3StakerActiveStakes", Method: "GET", Path: "/v2/uniswap/staking/{address}"},
+	{ID: "getUniswapV3StakerIncentives", Method: "GET", Path: "/v2/uniswap/staking/incentives"},
+}
+
+// Operations returns the operations of the API
+func Operations() []Operation {
+	return append([]Operation(nil), operations...)
+}
+
+// OperationID returns the operationId of a request to the API, or an empty string if the
+// request does not match any operation. Paths are matched against the end of the request
+// path, so servers with a path prefix are supported, and literal segments take precedence over
+// path parameters.
+func OperationID(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	id, best := "", -1
+	for _, op := range operations {
+		if op.Method != req.Method {
+			continue
+		}
+		if literals, ok := matchPath(op.Path, segments); ok && literals > best {
+			id, best = op.ID, literals
+		}
+	}
+	return id
+}
+
+// matchPath matches a path template against the trailing request path segments, and returns
+// the number of literal segments matched
+func matchPath(template string, segments []string) (int, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) > len(segments) {
+		return 0, false
+	}
+	segments = segments[len(segments)-len(parts):]
+	literals := 0
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// API calls the operations of the API and returns their decoded responses. Failed requests
+// and error responses are returned as *APIError.
+type API struct {
+	client ClientInterface
+}
+
+// NewAPI creates an API calling the operations with client
+func NewAPI(client ClientInterface) *API {
+	return &API{client: client}
+}
+
+// NewAPIWithServer creates an API with a new Client for server
+func NewAPIWithServer(server string, opts ...ClientOption) (*API, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPI(client), nil
+}
+
+// Client returns the client used by the API
+func (a *API) Client() ClientInterface {
+	return a.client
+}
+
+// GetSingleTokenPermit calls GET /v2/permit/single: Get permit for single token
+func (a *API) GetSingleTokenPermit(ctx context.Context, params *GetSingleTokenPermitParams, reqEditors ...RequestEditorFn) (PermitSingle, error) {
+	var response PermitSingle
+	httpResponse, err := a.client.GetSingleTokenPermit(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetSwapQuote calls POST /v2/swap/quote: Get a quote for a swap
+func (a *API) GetSwapQuote(ctx context.Context, body GetSwapQuoteJSONRequestBody, reqEditors ...RequestEditorFn) (QuoteResponse, error) {
+	var response QuoteResponse
+	httpResponse, err := a.client.GetSwapQuote(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// MultiStepSwap calls POST /v2/swap/tx/swap: Get steps for a swap
+func (a *API) MultiStepSwap(ctx context.Context, body MultiStepSwapJSONRequestBody, reqEditors ...RequestEditorFn) (MultiStepSwapTxResponse, error) {
+	var response MultiStepSwapTxResponse
+	httpResponse, err := a.client.MultiStepSwap(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SyncOrder calls POST /v2/orders/sync: Updates Order Entity
+func (a *API) SyncOrder(ctx context.Context, body SyncOrderJSONRequestBody, reqEditors ...RequestEditorFn) (Error, error) {
+	var response Error
+	httpResponse, err := a.client.SyncOrder(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUnfilledOrders calls GET /v2/orders: Fetch Unfilled Orders
+func (a *API) GetUnfilledOrders(ctx context.Context, params *GetUnfilledOrdersParams, reqEditors ...RequestEditorFn) (OrderResponse, error) {
+	var response OrderResponse
+	httpResponse, err := a.client.GetUnfilledOrders(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetAllAddresses calls GET /v2/addresses: Get all addresses
+func (a *API) GetAllAddresses(ctx context.Context, params *GetAllAddressesParams, reqEditors ...RequestEditorFn) (AddressResponse, error) {
+	var response AddressResponse
+	httpResponse, err := a.client.GetAllAddresses(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateLendingPoolDeposit calls POST /v2/lendingpool/tx/deposit: Deposit to lending pool
+func (a *API) CreateLendingPoolDeposit(ctx context.Context, body CreateLendingPoolDepositJSONRequestBody, reqEditors ...RequestEditorFn) (LendingpoolDepositTxResponse, error) {
+	var response LendingpoolDepositTxResponse
+	httpResponse, err := a.client.CreateLendingPoolDeposit(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateLendingPoolWithdraw calls POST /v2/lendingpool/tx/withdraw: Withdraw from lending pool
+func (a *API) CreateLendingPoolWithdraw(ctx context.Context, body CreateLendingPoolWithdrawJSONRequestBody, reqEditors ...RequestEditorFn) (LendingpoolWithdrawTxResponse, error) {
+	var response LendingpoolWithdrawTxResponse
+	httpResponse, err := a.client.CreateLendingPoolWithdraw(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateLendingPoolBorrow calls POST /v2/lendingpool/tx/borrow: Borrow from lending pool
+func (a *API) CreateLendingPoolBorrow(ctx context.Context, body CreateLendingPoolBorrowJSONRequestBody, reqEditors ...RequestEditorFn) (LendingpoolBorrowTxResponse, error) {
+	var response LendingpoolBorrowTxResponse
+	httpResponse, err := a.client.CreateLendingPoolBorrow(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateLendingPoolRepay calls POST /v2/lendingpool/tx/repay: Repay to lending pool
+func (a *API) CreateLendingPoolRepay(ctx context.Context, body CreateLendingPoolRepayJSONRequestBody, reqEditors ...RequestEditorFn) (LendingpoolRepayTxResponse, error) {
+	var response LendingpoolRepayTxResponse
+	httpResponse, err := a.client.CreateLendingPoolRepay(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SetLendingPoolAssetCollateral calls POST /v2/lendingpool/tx/useassetascollateral: Enable/Disable asset as collateral
+func (a *API) SetLendingPoolAssetCollateral(ctx context.Context, body SetLendingPoolAssetCollateralJSONRequestBody, reqEditors ...RequestEditorFn) (LendingpoolUseAssetAsCollateralTxResponse, error) {
+	var response LendingpoolUseAssetAsCollateralTxResponse
+	httpResponse, err := a.client.SetLendingPoolAssetCollateral(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateStableCoinVault calls POST /v2/stablecoinsystem/tx/createvault: Create vault
+func (a *API) CreateStableCoinVault(ctx context.Context, body CreateStableCoinVaultJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.CreateStableCoinVault(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// DepositStableCoinCollateral calls POST /v2/stablecoinsystem/tx/depositcollateral: Deposit collateral
+func (a *API) DepositStableCoinCollateral(ctx context.Context, body DepositStableCoinCollateralJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.DepositStableCoinCollateral(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// WithdrawCollateralTransaction calls POST /v2/stablecoinsystem/tx/withdrawcollateral: Withdraw collateral
+func (a *API) WithdrawCollateralTransaction(ctx context.Context, body WithdrawCollateralTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.WithdrawCollateralTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// MintZarTransaction calls POST /v2/stablecoinsystem/tx/mintzar: Mint ZAR
+func (a *API) MintZarTransaction(ctx context.Context, body MintZarTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.MintZarTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// RepayZarTransaction calls POST /v2/stablecoinsystem/tx/repayzar: Repay ZAR
+func (a *API) RepayZarTransaction(ctx context.Context, body RepayZarTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.RepayZarTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// LiquidateVaultTransaction calls POST /v2/stablecoinsystem/tx/bark: liquidate a vault
+func (a *API) LiquidateVaultTransaction(ctx context.Context, body LiquidateVaultTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.LiquidateVaultTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ApproveAndJoinZarTransaction calls POST /v2/stablecoinsystem/auctions/tx/zarjoin: approve and join ZAR token into Vat contract
+func (a *API) ApproveAndJoinZarTransaction(ctx context.Context, body ApproveAndJoinZarTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.ApproveAndJoinZarTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ExitZarTransaction calls POST /v2/stablecoinsystem/auctions/tx/zarexit: exit ZAR token from Vat contract
+func (a *API) ExitZarTransaction(ctx context.Context, body ExitZarTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.ExitZarTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ExitGemTransaction calls POST /v2/stablecoinsystem/auctions/tx/gemexit: exit Gem token (which can be used as collateral) from Vat contract
+func (a *API) ExitGemTransaction(ctx context.Context, body ExitGemTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.ExitGemTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ResetAuctionTransaction calls POST /v2/stablecoinsystem/auctions/tx/redo: reset a auction
+func (a *API) ResetAuctionTransaction(ctx context.Context, body ResetAuctionTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.ResetAuctionTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// TakeAuctionTransaction calls POST /v2/stablecoinsystem/auctions/tx/take: take a auction
+func (a *API) TakeAuctionTransaction(ctx context.Context, body TakeAuctionTransactionJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.TakeAuctionTransaction(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// FetchReserveDataByAsset calls GET /v2/lendingpool/reserves: Fetch Reserve Data By Asset
+func (a *API) FetchReserveDataByAsset(ctx context.Context, params *FetchReserveDataByAssetParams, reqEditors ...RequestEditorFn) (FormattedReserveData, error) {
+	var response FormattedReserveData
+	httpResponse, err := a.client.FetchReserveDataByAsset(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetAllIlks calls GET /v2/ilks: Get all Ilks
+func (a *API) GetAllIlks(ctx context.Context, reqEditors ...RequestEditorFn) (IlksResponse, error) {
+	var response IlksResponse
+	httpResponse, err := a.client.GetAllIlks(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetIlkByName calls GET /v2/ilks/{name}: Get Ilk by name
+func (a *API) GetIlkByName(ctx context.Context, name string, reqEditors ...RequestEditorFn) (Ilk, error) {
+	var response Ilk
+	httpResponse, err := a.client.GetIlkByName(ctx, name, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetCollectorData calls GET /v2/stats: Get collector data
+func (a *API) GetCollectorData(ctx context.Context, reqEditors ...RequestEditorFn) (Stats, error) {
+	var response Stats
+	httpResponse, err := a.client.GetCollectorData(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserDeposits calls GET /v2/lendingpool/deposits: Get user deposits of Lendingpool
+func (a *API) GetUserDeposits(ctx context.Context, params *GetUserDepositsParams, reqEditors ...RequestEditorFn) (UserDepositsResponse, error) {
+	var response UserDepositsResponse
+	httpResponse, err := a.client.GetUserDeposits(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserBorrows calls GET /v2/lendingpool/borrows: Get user borrows of lendingpool
+func (a *API) GetUserBorrows(ctx context.Context, params *GetUserBorrowsParams, reqEditors ...RequestEditorFn) (UserBorrowsResponse, error) {
+	var response UserBorrowsResponse
+	httpResponse, err := a.client.GetUserBorrows(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetLogsByTransactionHash calls GET /v2/logs/{txHash}: Get raw and decoded logs by transaction hash
+func (a *API) GetLogsByTransactionHash(ctx context.Context, txHash string, reqEditors ...RequestEditorFn) (EventDetailsResponse, error) {
+	var response EventDetailsResponse
+	httpResponse, err := a.client.GetLogsByTransactionHash(ctx, txHash, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ListPrices calls GET /v2/prices: List prices based on query parameters
+func (a *API) ListPrices(ctx context.Context, params *ListPricesParams, reqEditors ...RequestEditorFn) (PriceListResponse, error) {
+	var response PriceListResponse
+	httpResponse, err := a.client.ListPrices(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetVaultById calls GET /v2/vaults/{id}: Get a vault by ID
+func (a *API) GetVaultById(ctx context.Context, id int, reqEditors ...RequestEditorFn) (Vault, error) {
+	var response Vault
+	httpResponse, err := a.client.GetVaultById(ctx, id, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetVaultEventsById calls GET /v2/vaults/{id}/events: Get vault events by ID
+func (a *API) GetVaultEventsById(ctx context.Context, id int, params *GetVaultEventsByIdParams, reqEditors ...RequestEditorFn) (VaultEventsResponse, error) {
+	var response VaultEventsResponse
+	httpResponse, err := a.client.GetVaultEventsById(ctx, id, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetVaultsByOwner calls GET /v2/vaults: Get vaults by owner query
+func (a *API) GetVaultsByOwner(ctx context.Context, params *GetVaultsByOwnerParams, reqEditors ...RequestEditorFn) (VaultsResponse, error) {
+	var response VaultsResponse
+	httpResponse, err := a.client.GetVaultsByOwner(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetAccountByAddress calls GET /v2/accounts/{address}: Get account by address
+func (a *API) GetAccountByAddress(ctx context.Context, address string, reqEditors ...RequestEditorFn) (Account, error) {
+	var response Account
+	httpResponse, err := a.client.GetAccountByAddress(ctx, address, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetScoreboard calls GET /v2/points/scoreboard: Get scoreboard
+func (a *API) GetScoreboard(ctx context.Context, reqEditors ...RequestEditorFn) (Scoreboard, error) {
+	var response Scoreboard
+	httpResponse, err := a.client.GetScoreboard(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// StakeToStakingContract calls POST /v2/staking/tx/stake: Stake to staking contract
+func (a *API) StakeToStakingContract(ctx context.Context, body StakeToStakingContractJSONRequestBody, reqEditors ...RequestEditorFn) (StakingStakeTxResponse, error) {
+	var response StakingStakeTxResponse
+	httpResponse, err := a.client.StakeToStakingContract(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// WithdrawStakedAsset calls POST /v2/staking/tx/withdraw: Withdraw staked asset
+func (a *API) WithdrawStakedAsset(ctx context.Context, body WithdrawStakedAssetJSONRequestBody, reqEditors ...RequestEditorFn) (StakingWithdrawTxResponse, error) {
+	var response StakingWithdrawTxResponse
+	httpResponse, err := a.client.WithdrawStakedAsset(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CollectStakingReward calls POST /v2/staking/tx/collectreward: Collect staking reward
+func (a *API) CollectStakingReward(ctx context.Context, body CollectStakingRewardJSONRequestBody, reqEditors ...RequestEditorFn) (StakingCollectRewardTxResponse, error) {
+	var response StakingCollectRewardTxResponse
+	httpResponse, err := a.client.CollectStakingReward(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserStakingStats calls GET /v2/staking/stats: Get user staking stats
+func (a *API) GetUserStakingStats(ctx context.Context, params *GetUserStakingStatsParams, reqEditors ...RequestEditorFn) (UserStakesResponse, error) {
+	var response UserStakesResponse
+	httpResponse, err := a.client.GetUserStakingStats(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetStakingPlans calls GET /v2/staking/plans: Get staking plans
+func (a *API) GetStakingPlans(ctx context.Context, reqEditors ...RequestEditorFn) (StakePlansResponse, error) {
+	var response StakePlansResponse
+	httpResponse, err := a.client.GetStakingPlans(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CollectLendingpoolRewards calls POST /v2/lendingpool/tx/collectreward: Collect lendingpool rewards
+func (a *API) CollectLendingpoolRewards(ctx context.Context, body CollectLendingpoolRewardsJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.CollectLendingpoolRewards(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateUniswapV3Position calls POST /v2/uniswap/tx/createposition: Create Uniswap V3 position
+func (a *API) CreateUniswapV3Position(ctx context.Context, body CreateUniswapV3PositionJSONRequestBody, reqEditors ...RequestEditorFn) (CreateUniswapV3PositionTxResponse, error) {
+	var response CreateUniswapV3PositionTxResponse
+	httpResponse, err := a.client.CreateUniswapV3Position(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CollectUniswapV3Rewards calls POST /v2/uniswap/tx/collectreward: Collect Uniswap V3 position rewards
+func (a *API) CollectUniswapV3Rewards(ctx context.Context, body CollectUniswapV3RewardsJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.CollectUniswapV3Rewards(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// IncreaseUniswapV3PositionLiquidity calls POST /v2/uniswap/tx/increaseliquidity: Increase Uniswap V3 position liquidity
+func (a *API) IncreaseUniswapV3PositionLiquidity(ctx context.Context, body IncreaseUniswapV3PositionLiquidityJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.IncreaseUniswapV3PositionLiquidity(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// DecreaseUniswapV3PositionLiquidity calls POST /v2/uniswap/tx/decreaseliquidity: Decrease Uniswap V3 position liquidity
+func (a *API) DecreaseUniswapV3PositionLiquidity(ctx context.Context, body DecreaseUniswapV3PositionLiquidityJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.DecreaseUniswapV3PositionLiquidity(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// BurnUniswapV3PositionNFT calls POST /v2/uniswap/tx/burn: Burn Uniswap V3 position nft
+func (a *API) BurnUniswapV3PositionNFT(ctx context.Context, body BurnUniswapV3PositionNFTJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.BurnUniswapV3PositionNFT(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserUniswapV3Positions calls GET /v2/uniswap/positions: Get the list of a user's uniswap v3 positions
+func (a *API) GetUserUniswapV3Positions(ctx context.Context, params *GetUserUniswapV3PositionsParams, reqEditors ...RequestEditorFn) (UserPositions, error) {
+	var response UserPositions
+	httpResponse, err := a.client.GetUserUniswapV3Positions(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUniswapV3PositionDetails calls GET /v2/uniswap/positions/{tokenId}: Get details of a specific uniswap v3 position
+func (a *API) GetUniswapV3PositionDetails(ctx context.Context, tokenId int, reqEditors ...RequestEditorFn) (PositionDetails, error) {
+	var response PositionDetails
+	httpResponse, err := a.client.GetUniswapV3PositionDetails(ctx, tokenId, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// StakeUniswapV3PositionNFT calls POST /v2/uniswap/tx/stake: Stake Uniswap V3 position nft
+func (a *API) StakeUniswapV3PositionNFT(ctx context.Context, body StakeUniswapV3PositionNFTJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.StakeUniswapV3PositionNFT(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// UnstakeUniswapV3PositionNFT calls POST /v2/uniswap/tx/unstake: Unstake Uniswap V3 position nft
+func (a *API) UnstakeUniswapV3PositionNFT(ctx context.Context, body UnstakeUniswapV3PositionNFTJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.UnstakeUniswapV3PositionNFT(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CollectUniswapV3StakingRewards calls POST /v2/uniswap/tx/collectstakingreward: Collect Uniswap V3 staking rewards
+func (a *API) CollectUniswapV3StakingRewards(ctx context.Context, body CollectUniswapV3StakingRewardsJSONRequestBody, reqEditors ...RequestEditorFn) (ChainActivity, error) {
+	var response ChainActivity
+	httpResponse, err := a.client.CollectUniswapV3StakingRewards(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUniswapV3PoolByTokens calls GET /v2/uniswap/pools: Get Uniswap V3 pool details
+func (a *API) GetUniswapV3PoolByTokens(ctx context.Context, params *GetUniswapV3PoolByTokensParams, reqEditors ...RequestEditorFn) (UniswapV3PoolsList, error) {
+	var response UniswapV3PoolsList
+	httpResponse, err := a.client.GetUniswapV3PoolByTokens(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUniswapV3PoolTicksPrices calls GET /v2/uniswap/pools/ticks: Get Uniswap V3 ticks prices around a specific price
+func (a *API) GetUniswapV3PoolTicksPrices(ctx context.Context, params *GetUniswapV3PoolTicksPricesParams, reqEditors ...RequestEditorFn) (UniswapV3PoolSurroundingTicks, error) {
+	var response UniswapV3PoolSurroundingTicks
+	httpResponse, err := a.client.GetUniswapV3PoolTicksPrices(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUniswapV3StakerActiveStakes calls GET /v2/uniswap/staking/{address}: Get active stakes in UniswapV3Staker
+func (a *API) GetUniswapV3StakerActiveStakes(ctx context.Context, address string, reqEditors ...RequestEditorFn) (UniswapV3StakerUserStakesResponse, error) {
+	var response UniswapV3StakerUserStakesResponse
+	httpResponse, err := a.client.GetUniswapV3StakerActiveStakes(ctx, address, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUniswapV3StakerIncentives calls GET /v2/uniswap/staking/incentives: Get UniswapV3Staker incentives
+func (a *API) GetUniswapV3StakerIncentives(ctx context.Context, params *GetUniswapV3StakerIncentivesParams, reqEditors ...RequestEditorFn) (UniswapV3StakerIncentivesResponse, error) {
+	var response UniswapV3StakerIncentivesResponse
+	httpResponse, err := a.client.GetUniswapV3StakerIncentives(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{method: "GET", url: "https://testapi.zarban.io/v2/vaults", want: "getVaultsByOwner"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/vaults/42", want: "getVaultById"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/vaults/42/events", want: "getVaultEventsById"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/vaults/42/events/?page=2", want: "getVaultEventsById"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/ilks/ETHA", want: "getIlkByName"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/ilks", want: "getAllIlks"},
+		{method: "GET", url: "https://testapi.zarban.io/api/v2/accounts/0x00000000000000000000000000000000000000aa", want: "getAccountByAddress"},
+		{method: "POST", url: "https://testapi.zarban.io/api/v2/swap/quote", want: "getSwapQuote"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/swap/quote"},
+		{method: "GET", url: "https://testapi.zarban.io/v2/vaults//events"},
+		{method: "GET", url: "https://testapi.zarban.io/v3/vaults/42/events/all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			if got := OperationID(httptest.NewRequest(tt.method, tt.url, nil)); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	ops := Operations()
+	ops[0].ID = "changed"
+	if Operations()[0].ID == "changed" {
+		t.Fatal("expected Operations to return a copy")
+	}
+}
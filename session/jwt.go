@@ -0,0 +1,42 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for tokens that are not a JWT
+var ErrInvalidToken = errors.New("invalid jwt")
+
+// Expiry returns the expiry of a JWT from its exp claim, or the zero time if it has none. The
+// signature is not verified.
+func Expiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidToken, len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: failed to decode payload: %v", ErrInvalidToken, err)
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("%w: failed to parse claims: %v", ErrInvalidToken, err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, nil
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid exp claim %q", ErrInvalidToken, claims.Exp.String())
+	}
+	sec, frac := math.Modf(exp)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
@@ -0,0 +1,289 @@
+// Package session keeps wallet and service clients authenticated. A Session logs in, stores
+// the JWT, renews it with GenerateJwtToken before it expires and sets the Authorization header
+// of requests:
+//
+//	s, err := session.New(authClient, session.EmailPassword(email, password))
+//	doer, err := session.NewTransport(http.DefaultClient, s)
+//	client, err := wallet.NewClient(server, wallet.WithHTTPClient(doer))
+//
+// The Transport sends a request once more with a new token when the API rejects its token.
+// Authorize only sets the header, so requests authorized with it are not retried unless they
+// are made with Call.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// Login authenticates with the wallet API and returns a JWT
+type Login func(ctx context.Context, api *wallet.API) (string, error)
+
+// EmailPassword logs in with LoginWithEmailAndPassword
+func EmailPassword(email, password string) Login {
+	return func(ctx context.Context, api *wallet.API) (string, error) {
+		resp, err := api.LoginWithEmailAndPassword(ctx, wallet.LoginRequest{Email: email, Password: password})
+		return resp.Token, err
+	}
+}
+
+// Telegram logs in with AuthenticateWithTelegram, using the init data of a Telegram mini app
+func Telegram(initData string) Login {
+	return func(ctx context.Context, api *wallet.API) (string, error) {
+		resp, err := api.AuthenticateWithTelegram(ctx, wallet.AuthTelegramRequest{Initdata: initData})
+		return resp.Token, err
+	}
+}
+
+// Session holds the JWT of a user. It logs in on first use, renews the token with
+// GenerateJwtToken once it is about to expire and logs in again if renewing fails.
+type Session struct {
+	api           *wallet.API
+	login         Login
+	refreshBefore time.Duration
+	duration      wallet.GenerateJwtTokenParamsDuration
+
+	// renewing serializes logins and renewals, so that the network calls are made without
+	// holding mu
+	renewing chan struct{}
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Option allows setting custom parameters on a Session
+type Option func(*Session) error
+
+// New creates a Session logging in with client, which must not authorize its requests with the
+// Session itself
+func New(client wallet.ClientInterface, login Login, opts ...Option) (*Session, error) {
+	if login == nil {
+		return nil, fmt.Errorf("login is required")
+	}
+	s := Session{
+		api:           wallet.NewAPI(client),
+		login:         login,
+		refreshBefore: time.Hour,
+		duration:      wallet.N7,
+		renewing:      make(chan struct{}, 1),
+	}
+	for _, o := range opts {
+		if err := o(&s); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// WithRefreshBefore sets how long before its expiry the token is renewed, defaults to an hour
+func WithRefreshBefore(d time.Duration) Option {
+	return func(s *Session) error {
+		if d < 0 {
+			return fmt.Errorf("invalid refresh time: %v", d)
+		}
+		s.refreshBefore = d
+		return nil
+	}
+}
+
+// WithTokenDuration sets the validity of the tokens minted by GenerateJwtToken, defaults to 7
+// days
+func WithTokenDuration(days wallet.GenerateJwtTokenParamsDuration) Option {
+	return func(s *Session) error {
+		switch days {
+		case wallet.N7, wallet.N15, wallet.N30, wallet.N90:
+			s.duration = days
+			return nil
+		}
+		return fmt.Errorf("invalid token duration: %d days", days)
+	}
+}
+
+// WithToken starts the Session with an existing token instead of logging in
+func WithToken(token string) Option {
+	return func(s *Session) error {
+		return s.store(token)
+	}
+}
+
+// Login logs in, replacing the current token
+func (s *Session) Login(ctx context.Context) error {
+	if err := s.lockRenewing(ctx); err != nil {
+		return err
+	}
+	defer s.unlockRenewing()
+	_, err := s.relogin(ctx)
+	return err
+}
+
+// Token returns a valid token, logging in or renewing the current token as needed. Requests
+// with a valid token do not wait for a login or a renewal in progress.
+func (s *Session) Token(ctx context.Context) (string, error) {
+	if token, ok := s.valid(); ok {
+		return token, nil
+	}
+	if err := s.lockRenewing(ctx); err != nil {
+		return "", err
+	}
+	defer s.unlockRenewing()
+
+	// another request may have logged in or renewed the token meanwhile
+	if token, ok := s.valid(); ok {
+		return token, nil
+	}
+	s.mu.Lock()
+	token, expiry := s.token, s.expiry
+	s.mu.Unlock()
+	if token == "" {
+		return s.relogin(ctx)
+	}
+	renewed, err := s.refresh(ctx, token)
+	if err == nil {
+		return renewed, nil
+	}
+	if time.Now().Before(expiry) {
+		// the current token still works, renew it on a later request
+		return token, nil
+	}
+	return s.relogin(ctx)
+}
+
+// Expiry returns the expiry of the current token, or the zero time if it is unknown
+func (s *Session) Expiry() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expiry
+}
+
+// Authorize sets the Authorization header of a request to the current token. It is a
+// RequestEditorFn of both the wallet and the service clients. Requests rejected as unauthorized
+// are not sent again, use a Transport or Call for that.
+func (s *Session) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authorize request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// valid returns the current token if it does not need to be renewed yet
+func (s *Session) valid() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" {
+		return "", false
+	}
+	return s.token, s.expiry.IsZero() || time.Until(s.expiry) > s.refreshBefore
+}
+
+// lockRenewing waits for the logins and renewals in progress
+func (s *Session) lockRenewing(ctx context.Context) error {
+	select {
+	case s.renewing <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unlockRenewing lets the next login or renewal through
+func (s *Session) unlockRenewing() {
+	<-s.renewing
+}
+
+// Invalidate discards a token rejected by the API, so that the next request logs in again. A
+// token already replaced by a concurrent request is left alone.
+func (s *Session) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+		s.expiry = time.Time{}
+	}
+}
+
+// Call calls an API operation authorized by s, and calls it once more with a new token if the
+// API rejects the token as unauthorized
+//
+//	profile, err := session.Call(ctx, s, func(ctx context.Context) (wallet.ProfileResponse, error) {
+//		return api.GetUserProfile(ctx)
+//	})
+func Call[T any](ctx context.Context, s *Session, call func(ctx context.Context) (T, error)) (T, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := call(ctx)
+	if !unauthorized(err) {
+		return result, err
+	}
+	s.Invalidate(token)
+	return call(ctx)
+}
+
+// relogin logs in and stores the token, with renewing held
+func (s *Session) relogin(ctx context.Context) (string, error) {
+	token, err := s.login(ctx, s.api)
+	if err != nil {
+		return "", fmt.Errorf("failed to login: %w", err)
+	}
+	if err := s.store(token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// refresh renews the current token with GenerateJwtToken and stores it, with renewing held
+func (s *Session) refresh(ctx context.Context, current string) (string, error) {
+	resp, err := s.api.GenerateJwtToken(ctx, &wallet.GenerateJwtTokenParams{Duration: s.duration},
+		func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+current)
+			return nil
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to renew token: %w", err)
+	}
+	if err := s.store(resp.Token); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// store replaces the current token
+func (s *Session) store(token string) error {
+	if token == "" {
+		return fmt.Errorf("%w: empty token", ErrInvalidToken)
+	}
+	expiry, err := Expiry(token)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	s.expiry = expiry
+	return nil
+}
+
+// unauthorized reports whether err is a 401 response of the wallet or the service API
+func unauthorized(err error) bool {
+	var walletErr *wallet.APIError
+	if errors.As(err, &walletErr) {
+		return walletErr.IsUnauthorized()
+	}
+	var serviceErr *service.APIError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.IsUnauthorized()
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+var tokenID int64
+
+// testToken returns an unsigned JWT expiring at exp, unique to each call
+func testToken(exp time.Time) string {
+	claims, _ := json.Marshal(map[string]int64{"exp": exp.Unix(), "jti": atomic.AddInt64(&tokenID, 1)})
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+}
+
+// authServer is a stand-in wallet API logging in and renewing tokens
+type authServer struct {
+	loginFails bool
+	renewFails bool
+	block      chan struct{}
+
+	logins   int32
+	renewals int32
+}
+
+func (a *authServer) start(t *testing.T) wallet.ClientInterface {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var fails bool
+		switch r.URL.Path {
+		case "/auth/login":
+			atomic.AddInt32(&a.logins, 1)
+			if a.block != nil {
+				<-a.block
+			}
+			fails = a.loginFails
+		case "/auth/token":
+			atomic.AddInt32(&a.renewals, 1)
+			fails = a.renewFails || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if fails {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"msg":"unauthorized"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, testToken(time.Now().Add(24*time.Hour)))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := wallet.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestToken(t *testing.T) {
+	valid := testToken(time.Now().Add(24 * time.Hour))
+	expiring := testToken(time.Now().Add(30 * time.Minute))
+	expired := testToken(time.Now().Add(-time.Minute))
+	tests := []struct {
+		name         string
+		token        string
+		server       authServer
+		wantToken    string
+		wantErr      bool
+		wantLogins   int32
+		wantRenewals int32
+	}{
+		{name: "logs in", wantLogins: 1},
+		{name: "login fails", server: authServer{loginFails: true}, wantErr: true, wantLogins: 1},
+		{name: "valid token", token: valid, wantToken: valid},
+		{name: "renews expiring token", token: expiring, wantRenewals: 1},
+		{name: "keeps expiring token if renewal fails", token: expiring, server: authServer{renewFails: true}, wantToken: expiring, wantRenewals: 1},
+		{name: "logs in if expired token cannot be renewed", token: expired, server: authServer{renewFails: true}, wantLogins: 1, wantRenewals: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.token != "" {
+				opts = append(opts, WithToken(tt.token))
+			}
+			s, err := New(tt.server.start(t), EmailPassword("user@example.com", "password"), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := s.Token(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantToken != "" && token != tt.wantToken {
+				t.Fatal("expected the current token")
+			}
+			if tt.wantToken == "" && !tt.wantErr && (token == "" || token == tt.token) {
+				t.Fatal("expected a new token")
+			}
+			if logins := atomic.LoadInt32(&tt.server.logins); logins != tt.wantLogins {
+				t.Fatalf("expected %d logins, got %d", tt.wantLogins, logins)
+			}
+			if renewals := atomic.LoadInt32(&tt.server.renewals); renewals != tt.wantRenewals {
+				t.Fatalf("expected %d renewals, got %d", tt.wantRenewals, renewals)
+			}
+		})
+	}
+}
+
+func TestTokenDoesNotHoldLock(t *testing.T) {
+	server := authServer{block: make(chan struct{})}
+	s, err := New(server.start(t), EmailPassword("user@example.com", "password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := s.Token(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}()
+	for atomic.LoadInt32(&server.logins) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the login in progress does not block the session
+	if !s.Expiry().IsZero() {
+		t.Fatal("expected no expiry before logging in")
+	}
+	s.Invalidate("other")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second request to wait for the login, got %v", err)
+	}
+
+	close(server.block)
+	wg.Wait()
+	if _, err := s.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins := atomic.LoadInt32(&server.logins); logins != 1 {
+		t.Fatalf("expected a single login, got %d", logins)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	rejected := testToken(time.Now().Add(24 * time.Hour))
+	tests := []struct {
+		name       string
+		server     authServer
+		body       func() io.Reader
+		rejectAll  bool
+		wantStatus int
+		wantErr    bool
+		wantCalls  int
+		wantLogins int32
+	}{
+		{
+			name:       "rejected token is replaced",
+			body:       func() io.Reader { return strings.NewReader(`{"amount":"1"}`) },
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantLogins: 1,
+		},
+		{
+			name:       "request without body",
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantLogins: 1,
+		},
+		{
+			name:       "new token rejected as well",
+			rejectAll:  true,
+			wantStatus: http.StatusUnauthorized,
+			wantCalls:  2,
+			wantLogins: 1,
+		},
+		{
+			name:       "body cannot be rewound",
+			body:       func() io.Reader { return io.MultiReader(strings.NewReader(`{"amount":"1"}`)) },
+			wantStatus: http.StatusUnauthorized,
+			wantCalls:  1,
+		},
+		{
+			name:       "login fails",
+			server:     authServer{loginFails: true},
+			wantErr:    true,
+			wantCalls:  1,
+			wantLogins: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				body, _ := io.ReadAll(r.Body)
+				if tt.body != nil && string(body) != `{"amount":"1"}` {
+					t.Errorf("unexpected body %q", body)
+				}
+				if tt.rejectAll || r.Header.Get("Authorization") == "Bearer "+rejected {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer api.Close()
+
+			s, err := New(tt.server.start(t), EmailPassword("user@example.com", "password"), WithToken(rejected))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doer, err := NewTransport(nil, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body io.Reader
+			if tt.body != nil {
+				body = tt.body()
+			}
+			req, err := http.NewRequest(http.MethodPost, api.URL+"/v2/orders", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := doer.Do(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+				}
+			}
+			if got := atomic.LoadInt32(&calls); int(got) != tt.wantCalls {
+				t.Fatalf("expected %d calls, got %d", tt.wantCalls, got)
+			}
+			if logins := atomic.LoadInt32(&tt.server.logins); logins != tt.wantLogins {
+				t.Fatalf("expected %d logins, got %d", tt.wantLogins, logins)
+			}
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	if _, err := NewTransport(nil, nil); err == nil {
+		t.Fatal("expected an error without a session")
+	}
+}
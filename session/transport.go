@@ -0,0 +1,72 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// Transport authorizes requests with a Session. When the API rejects the token with a 401
+// response, it discards the token and sends the request once more with a new one. Requests
+// whose body cannot be rewound with GetBody are not sent again. It is an HttpRequestDoer of
+// both the wallet and the service clients, installed with WithHTTPClient.
+type Transport struct {
+	next    wallet.HttpRequestDoer
+	session *Session
+}
+
+// NewTransport creates a Transport sending requests with next, or http.DefaultClient if next is
+// nil. The client passed to New to log in must not use the Transport itself.
+func NewTransport(next wallet.HttpRequestDoer, s *Session) (*Transport, error) {
+	if s == nil {
+		return nil, fmt.Errorf("session is required")
+	}
+	if next == nil {
+		next = http.DefaultClient
+	}
+	return &Transport{next: next, session: s}, nil
+}
+
+// Do sends the request with the current token, and with a new token if it is rejected
+func (t *Transport) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	token, err := t.session.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize request: %w", err)
+	}
+	resp, err := t.next.Do(authorized(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !rewindable {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+
+	t.session.Invalidate(token)
+	renewed, err := t.session.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize request: %w", err)
+	}
+	retry := authorized(req, renewed)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		retry.Body = body
+	}
+	return t.next.Do(retry)
+}
+
+// authorized returns a copy of req with its Authorization header set to token
+func authorized(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
@@ -0,0 +1,26 @@
+package signer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// NewKeystoreSigner creates a Signer from an encrypted go-ethereum keystore JSON document
+func NewKeystoreSigner(keyJSON []byte, passphrase string) (*PrivateKeySigner, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return NewPrivateKeySigner(key.PrivateKey)
+}
+
+// NewKeystoreFileSigner creates a Signer from an encrypted go-ethereum keystore file
+func NewKeystoreFileSigner(path string, passphrase string) (*PrivateKeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+	return NewKeystoreSigner(keyJSON, passphrase)
+}
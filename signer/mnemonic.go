@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// hardenedOffset is the first hardened BIP-32 child index
+const hardenedOffset = 0x80000000
+
+// Mnemonic derives any number of accounts from a single BIP-39 mnemonic
+type Mnemonic struct {
+	seed []byte
+}
+
+// NewMnemonic validates a BIP-39 mnemonic and derives its seed using the optional passphrase
+func NewMnemonic(mnemonic string, passphrase string) (*Mnemonic, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	return &Mnemonic{seed: seed}, nil
+}
+
+// Account returns the signer of the index-th account on the default Ethereum path m/44'/60'/0'/0/index
+func (m *Mnemonic) Account(index uint32) (*PrivateKeySigner, error) {
+	if index >= hardenedOffset {
+		return nil, fmt.Errorf("invalid account index: %d", index)
+	}
+	path := make(accounts.DerivationPath, len(accounts.DefaultBaseDerivationPath))
+	copy(path, accounts.DefaultBaseDerivationPath)
+	path[len(path)-1] = index
+	return m.DerivePath(path)
+}
+
+// Derive returns the signer for a BIP-44 derivation path such as m/44'/60'/0'/0/0
+func (m *Mnemonic) Derive(path string) (*PrivateKeySigner, error) {
+	parsed, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return m.DerivePath(parsed)
+}
+
+// DerivePath returns the signer for a parsed derivation path
+func (m *Mnemonic) DerivePath(path accounts.DerivationPath) (*PrivateKeySigner, error) {
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), m.seed)
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		var err error
+		key, chainCode, err = deriveChild(key, chainCode, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+	}
+	privateKey, err := crypto.ToECDSA(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySigner(privateKey)
+}
+
+// deriveChild implements BIP-32 private parent key to private child key derivation
+func deriveChild(key []byte, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, key...)
+	} else {
+		privateKey, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&privateKey.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	tweak, childChainCode := hmacSHA512(chainCode, data)
+	if err := checkKey(tweak); err != nil {
+		return nil, nil, err
+	}
+	child := new(big.Int).SetBytes(tweak)
+	child.Add(child, new(big.Int).SetBytes(key))
+	child.Mod(child, crypto.S256().Params().N)
+	if child.Sign() == 0 {
+		return nil, nil, errors.New("derived key is zero")
+	}
+	return child.FillBytes(make([]byte, 32)), childChainCode, nil
+}
+
+// checkKey rejects key material that is not a valid secp256k1 private key
+func checkKey(key []byte) error {
+	k := new(big.Int).SetBytes(key)
+	if k.Sign() == 0 || k.Cmp(crypto.S256().Params().N) >= 0 {
+		return errors.New("derived key is out of range")
+	}
+	return nil
+}
+
+// hmacSHA512 returns the two 32-byte halves of HMAC-SHA512(key, data)
+func hmacSHA512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
@@ -0,0 +1,114 @@
+package signer
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonicDerive(t *testing.T) {
+	tests := []struct {
+		name       string
+		mnemonic   string
+		passphrase string
+		path       string
+		want       string
+		wantErr    bool
+	}{
+		{name: "default path", mnemonic: testMnemonic, path: "m/44'/60'/0'/0/0", want: "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"},
+		{name: "second account", mnemonic: testMnemonic, path: "m/44'/60'/0'/0/1", want: "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"},
+		{name: "invalid checksum", mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", path: "m/44'/60'/0'/0/0", wantErr: true},
+		{name: "invalid path", mnemonic: testMnemonic, path: "m/44'/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMnemonic(tt.mnemonic, tt.passphrase)
+			if err == nil {
+				var s *PrivateKeySigner
+				s, err = m.Derive(tt.path)
+				if err == nil && s.Address() != common.HexToAddress(tt.want) {
+					t.Fatalf("expected %s, got %s", tt.want, s.Address())
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMnemonicAccount(t *testing.T) {
+	m, err := NewMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := m.Account(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); s.Address() != want {
+		t.Fatalf("expected %s, got %s", want, s.Address())
+	}
+	if _, err := m.Account(hardenedOffset); err == nil {
+		t.Fatal("expected an error for a hardened index")
+	}
+}
+
+// TestDeriveChild checks test vector 1 of BIP-32, which alternates hardened and normal children
+func TestDeriveChild(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path      string
+		key       string
+		chainCode string
+	}{
+		{path: "m", key: "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", chainCode: "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"},
+		{path: "m/0'", key: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea", chainCode: "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141"},
+		{path: "m/0'/1", key: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", chainCode: "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19"},
+		{path: "m/0'/1/2'", key: "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca", chainCode: "04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f"},
+		{path: "m/0'/1/2'/2", key: "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4", chainCode: "cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd"},
+		{path: "m/0'/1/2'/2/1000000000", key: "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8", chainCode: "c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+			if tt.path != "m" {
+				path, err := accounts.ParseDerivationPath(tt.path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, index := range path {
+					key, chainCode, err = deriveChild(key, chainCode, index)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if got := hex.EncodeToString(key); got != tt.key {
+				t.Fatalf("expected key %s, got %s", tt.key, got)
+			}
+			if got := hex.EncodeToString(chainCode); got != tt.chainCode {
+				t.Fatalf("expected chain code %s, got %s", tt.chainCode, got)
+			}
+
+			// the signer of the derived path holds the same key
+			m := &Mnemonic{seed: seed}
+			path, _ := accounts.ParseDerivationPath(tt.path)
+			if tt.path == "m" {
+				path = accounts.DerivationPath{}
+			}
+			s, err := m.DerivePath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(crypto.FromECDSA(s.key)); got != tt.key {
+				t.Fatalf("expected signer key %s, got %s", tt.key, got)
+			}
+		})
+	}
+}
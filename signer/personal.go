@@ -0,0 +1,76 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrSignatureMismatch is returned when a signature was not produced by the expected address
+var ErrSignatureMismatch = errors.New("signature does not match address")
+
+// PersonalMessage returns the bytes to sign for a PersonalSignRequest message.
+// Hex encoded messages are decoded, any other message is signed as UTF-8 text.
+func PersonalMessage(message string) []byte {
+	if has0xPrefix(message) {
+		if decoded, err := hexutil.Decode(message); err == nil {
+			return decoded
+		}
+	}
+	return []byte(message)
+}
+
+// PersonalHash returns the EIP-191 hash of a personal_sign message
+func PersonalHash(message []byte) common.Hash {
+	return common.BytesToHash(accounts.TextHash(message))
+}
+
+// SignPersonal returns the 65-byte EIP-191 personal_sign signature of the message.
+// The recovery id is returned as 27 or 28, like personal_sign in wallets.
+func SignPersonal(message []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	return signDigest(PersonalHash(message), key)
+}
+
+// RecoverPersonal returns the address that produced a personal_sign signature of the message
+func RecoverPersonal(message []byte, signature []byte) (common.Address, error) {
+	return recoverDigest(PersonalHash(message), signature)
+}
+
+// VerifyPersonal checks that signature is a personal_sign signature of the message by address
+func VerifyPersonal(address common.Address, message []byte, signature []byte) error {
+	recovered, err := RecoverPersonal(message, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != address {
+		return fmt.Errorf("%w: expected %s, recovered %s", ErrSignatureMismatch, address.Hex(), recovered.Hex())
+	}
+	return nil
+}
+
+// recoverDigest recovers the signer of a digest, accepting recovery ids in both [0, 1] and [27, 28]
+func recoverDigest(digest common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// has0xPrefix reports whether s starts with 0x or 0X
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
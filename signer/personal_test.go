@@ -0,0 +1,111 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPersonalHash(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		// the vector of accounts.TextHash in go-ethereum
+		{message: "Hello Joe", want: "0xa080337ae51c4e064c189e113edd0ba391df9206e2f49db658bb32cf2911730b"},
+		// the vector of hashMessage in ethers
+		{message: "Hello World", want: "0xa1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := PersonalHash([]byte(tt.message)).Hex(); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPersonalMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "text", message: "hello", want: "hello"},
+		{name: "hex", message: "0x68656c6c6f", want: "hello"},
+		{name: "upper case prefix", message: "0X68656c6c6f", want: "hello"},
+		{name: "invalid hex", message: "0xzz", want: "0xzz"},
+		{name: "odd length hex", message: "0x123", want: "0x123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(PersonalMessage(tt.message)); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSignPersonal(t *testing.T) {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	message := []byte("Sign in to Zarban")
+	signature, err := SignPersonal(message, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := signature[crypto.RecoveryIDOffset]
+	if v != 27 && v != 28 {
+		t.Fatalf("expected a recovery id of 27 or 28, got %d", v)
+	}
+
+	// the same signature with a recovery id of 0 or 1, as returned by crypto.Sign
+	raw := append([]byte(nil), signature...)
+	raw[crypto.RecoveryIDOffset] -= 27
+	// the other recovery id recovers another key, if any
+	flipped := append([]byte(nil), signature...)
+	flipped[crypto.RecoveryIDOffset] ^= 1
+	invalid := append([]byte(nil), signature...)
+	invalid[crypto.RecoveryIDOffset] = 29
+
+	tests := []struct {
+		name      string
+		message   []byte
+		signature []byte
+		wantErr   error
+		wantAny   bool
+	}{
+		{name: "recovery id 27 or 28", message: message, signature: signature},
+		{name: "recovery id 0 or 1", message: message, signature: raw},
+		{name: "other message", message: []byte("Sign in to Zarban!"), signature: signature, wantErr: ErrSignatureMismatch},
+		{name: "other recovery id", message: message, signature: flipped, wantAny: true},
+		{name: "invalid recovery id", message: message, signature: invalid, wantAny: true},
+		{name: "short signature", message: message, signature: signature[:64], wantAny: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyPersonal(address, tt.message, tt.signature)
+			switch {
+			case tt.wantAny:
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// the signature is not modified by recovering it
+	if signature[crypto.RecoveryIDOffset] != v {
+		t.Fatal("expected the signature not to be modified")
+	}
+}
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// PrivateKeySigner signs with an in-memory private key
+type PrivateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner creates a Signer backed by the given private key
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) (*PrivateKeySigner, error) {
+	if key == nil {
+		return nil, errors.New("private key is required")
+	}
+	return &PrivateKeySigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+// Address returns the address of the private key
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs a legacy, access list or dynamic fee transaction for the given chain
+func (s *PrivateKeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, errors.New("chain id is required")
+	}
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// SignTypedData returns the 65-byte EIP-712 signature of the typed data
+func (s *PrivateKeySigner) SignTypedData(ctx context.Context, data service.TypedData) ([]byte, error) {
+	return SignTypedData(data, s.key)
+}
+
+// SignPersonal returns the 65-byte EIP-191 personal_sign signature of the message
+func (s *PrivateKeySigner) SignPersonal(ctx context.Context, message []byte) ([]byte, error) {
+	return SignPersonal(message, s.key)
+}
@@ -0,0 +1,248 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	// ErrRequestDenied is returned when the remote signer or its operator rejects a request
+	ErrRequestDenied = errors.New("request denied by remote signer")
+
+	// ErrRemoteTimeout is returned when the remote signer does not answer within the timeout
+	ErrRemoteTimeout = errors.New("remote signer timed out")
+
+	// ErrTxMismatch is returned when the remote signer signed a different transaction than requested
+	ErrTxMismatch = errors.New("remote signer returned a different transaction")
+)
+
+// RemoteSigner forwards signing requests to a Clef compatible JSON-RPC endpoint, so keys
+// never have to be loaded into the calling process
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	timeout time.Duration
+}
+
+// RemoteOption allows setting custom parameters on a RemoteSigner
+type RemoteOption func(*RemoteSigner) error
+
+// WithRemoteTimeout sets how long to wait for each signing request, including manual approval
+func WithRemoteTimeout(d time.Duration) RemoteOption {
+	return func(s *RemoteSigner) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid timeout: %v", d)
+		}
+		s.timeout = d
+		return nil
+	}
+}
+
+// DialRemoteSigner connects to a Clef endpoint (http, ws or ipc) and signs for address
+func DialRemoteSigner(ctx context.Context, endpoint string, address common.Address, opts ...RemoteOption) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	s, err := NewRemoteSigner(client, address, opts...)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewRemoteSigner creates a RemoteSigner using an existing RPC client
+func NewRemoteSigner(client *rpc.Client, address common.Address, opts ...RemoteOption) (*RemoteSigner, error) {
+	s := RemoteSigner{
+		client:  client,
+		address: address,
+		timeout: 2 * time.Minute,
+	}
+	for _, o := range opts {
+		if err := o(&s); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// Close closes the underlying RPC connection
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+// Address returns the account the remote signer signs for
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx asks the remote signer to sign a transaction with account_signTransaction
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, errors.New("chain id is required")
+	}
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(s.address),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  &data,
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+	args.ChainID = (*hexutil.Big)(chainID)
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var result struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+	if err := s.call(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	signedTx := result.Tx
+	if signedTx == nil {
+		signedTx = new(types.Transaction)
+		if err := signedTx.UnmarshalBinary(result.Raw); err != nil {
+			return nil, fmt.Errorf("failed to decode signed transaction: %w", err)
+		}
+	}
+	if err := checkSignedTx(tx, signedTx, chainID, s.address); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// SignTypedData asks the remote signer to sign typed data with account_signTypedData
+func (s *RemoteSigner) SignTypedData(ctx context.Context, data service.TypedData) ([]byte, error) {
+	typedData, err := toAPITypes(data)
+	if err != nil {
+		return nil, err
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	var signature hexutil.Bytes
+	if err := s.call(ctx, &signature, "account_signTypedData", common.NewMixedcaseAddress(s.address), typedData); err != nil {
+		return nil, err
+	}
+	if err := s.checkSignature(common.BytesToHash(digest), signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// SignPersonal asks the remote signer to sign a message with account_signData as text/plain
+func (s *RemoteSigner) SignPersonal(ctx context.Context, message []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	if err := s.call(ctx, &signature, "account_signData", "text/plain", common.NewMixedcaseAddress(s.address), hexutil.Encode(message)); err != nil {
+		return nil, err
+	}
+	if err := s.checkSignature(PersonalHash(message), signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// call performs a JSON-RPC call with the signer timeout and maps remote errors
+func (s *RemoteSigner) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.client.CallContext(ctx, result, method, args...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", method, ErrRemoteTimeout)
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && strings.Contains(strings.ToLower(rpcErr.Error()), "request denied") {
+		return fmt.Errorf("%s: %w", method, ErrRequestDenied)
+	}
+	return fmt.Errorf("%s: %w", method, err)
+}
+
+// checkSignature makes sure the remote signer signed the expected digest with the expected account
+func (s *RemoteSigner) checkSignature(digest common.Hash, signature []byte) error {
+	recovered, err := recoverDigest(digest, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != s.address {
+		return fmt.Errorf("%w: expected %s, recovered %s", ErrSignatureMismatch, s.address.Hex(), recovered.Hex())
+	}
+	return nil
+}
+
+// checkSignedTx makes sure the remote signer did not alter the transaction it was asked to sign,
+// including its type, chain and fees, and that it signed with the expected account
+func checkSignedTx(tx *types.Transaction, signedTx *types.Transaction, chainID *big.Int, address common.Address) error {
+	var field string
+	switch {
+	case signedTx.Type() != tx.Type():
+		field = "type"
+	case signedTx.ChainId().Cmp(chainID) != 0:
+		field = "chain id"
+	case signedTx.Nonce() != tx.Nonce():
+		field = "nonce"
+	case signedTx.Gas() != tx.Gas():
+		field = "gas"
+	case signedTx.GasPrice().Cmp(tx.GasPrice()) != 0:
+		field = "gas price"
+	case signedTx.GasTipCap().Cmp(tx.GasTipCap()) != 0:
+		field = "gas tip cap"
+	case signedTx.GasFeeCap().Cmp(tx.GasFeeCap()) != 0:
+		field = "gas fee cap"
+	case signedTx.Value().Cmp(tx.Value()) != 0:
+		field = "value"
+	case !bytes.Equal(signedTx.Data(), tx.Data()):
+		field = "data"
+	case (signedTx.To() == nil) != (tx.To() == nil), tx.To() != nil && *signedTx.To() != *tx.To():
+		field = "recipient"
+	case !reflect.DeepEqual(signedTx.AccessList(), tx.AccessList()):
+		field = "access list"
+	}
+	if field != "" {
+		return fmt.Errorf("%w: %s differs", ErrTxMismatch, field)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+	if err != nil {
+		return fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+	if sender != address {
+		return fmt.Errorf("%w: expected %s, recovered %s", ErrSignatureMismatch, address.Hex(), sender.Hex())
+	}
+	return nil
+}
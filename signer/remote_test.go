@@ -0,0 +1,230 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// clefAPI is a stand-in for the account namespace of Clef, signing with key. The tamper and deny
+// hooks simulate a compromised or rejecting signer.
+type clefAPI struct {
+	key    *ecdsa.PrivateKey
+	tamper func(args *apitypes.SendTxArgs)
+	deny   bool
+}
+
+type signTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (api *clefAPI) SignTransaction(ctx context.Context, args apitypes.SendTxArgs) (*signTxResult, error) {
+	if api.deny {
+		return nil, errors.New("Request denied")
+	}
+	if api.tamper != nil {
+		api.tamper(&args)
+	}
+	tx, err := types.SignTx(args.ToTransaction(), types.LatestSignerForChainID((*big.Int)(args.ChainID)), api.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTxResult{Raw: raw, Tx: tx}, nil
+}
+
+func (api *clefAPI) SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if api.deny {
+		return nil, errors.New("Request denied")
+	}
+	return SignPersonal(data, api.key)
+}
+
+// dialClef starts a stand-in Clef server and returns a RemoteSigner connected to it
+func dialClef(t *testing.T, api *clefAPI, address common.Address) *RemoteSigner {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	s, err := NewRemoteSigner(rpc.DialInProc(server), address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRemoteSignerSignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	chainID := big.NewInt(1)
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(30),
+		Gas: 50000, To: &to, Value: big.NewInt(1), Data: []byte{1, 2, 3},
+	})
+	legacyTx := types.NewTx(&types.LegacyTx{
+		Nonce: 7, GasPrice: big.NewInt(30), Gas: 50000, To: &to, Value: big.NewInt(1),
+	})
+
+	tests := []struct {
+		name    string
+		tx      *types.Transaction
+		api     *clefAPI
+		wantErr error
+	}{
+		{name: "dynamic fee", tx: dynamicTx, api: &clefAPI{key: key}},
+		{name: "legacy", tx: legacyTx, api: &clefAPI{key: key}},
+		{
+			name:    "denied",
+			tx:      dynamicTx,
+			api:     &clefAPI{key: key, deny: true},
+			wantErr: ErrRequestDenied,
+		},
+		{
+			name:    "other key",
+			tx:      dynamicTx,
+			api:     &clefAPI{key: otherKey},
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "other chain",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.ChainID = (*hexutil.Big)(big.NewInt(5))
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "inflated fee cap",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.MaxFeePerGas = (*hexutil.Big)(big.NewInt(3000))
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "inflated tip cap",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.MaxPriorityFeePerGas = (*hexutil.Big)(big.NewInt(29))
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "inflated gas price",
+			tx:   legacyTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.GasPrice = (*hexutil.Big)(big.NewInt(3000))
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other type",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.GasPrice = (*hexutil.Big)(big.NewInt(30))
+				args.MaxFeePerGas = nil
+				args.MaxPriorityFeePerGas = nil
+				args.AccessList = nil
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other recipient",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				attacker := common.NewMixedcaseAddress(common.HexToAddress("0x00000000000000000000000000000000000000bb"))
+				args.To = &attacker
+			}},
+			wantErr: ErrTxMismatch,
+		},
+		{
+			name: "other value",
+			tx:   dynamicTx,
+			api: &clefAPI{key: key, tamper: func(args *apitypes.SendTxArgs) {
+				args.Value = hexutil.Big(*big.NewInt(1e18))
+			}},
+			wantErr: ErrTxMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := dialClef(t, tt.api, address)
+			signedTx, err := s.SignTx(context.Background(), tt.tx, chainID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signedTx.Hash() == tt.tx.Hash() {
+				t.Fatal("expected a signed transaction")
+			}
+		})
+	}
+}
+
+func TestRemoteSignerSignPersonal(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	tests := []struct {
+		name    string
+		api     *clefAPI
+		wantErr error
+	}{
+		{name: "signed", api: &clefAPI{key: key}},
+		{name: "other key", api: &clefAPI{key: otherKey}, wantErr: ErrSignatureMismatch},
+		{name: "denied", api: &clefAPI{key: key, deny: true}, wantErr: ErrRequestDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := dialClef(t, tt.api, address)
+			signature, err := s.SignPersonal(context.Background(), []byte("hello"))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyPersonal(address, []byte("hello"), signature); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
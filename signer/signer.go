@@ -0,0 +1,26 @@
+// Package signer provides the signing primitives used to complete service.ChainActivity steps
+package signer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// Signer signs the transactions and messages produced by a service.ChainActivity
+type Signer interface {
+	// Address returns the account the signer signs for
+	Address() common.Address
+
+	// SignTx signs a transaction for the given chain
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+	// SignTypedData returns the 65-byte EIP-712 signature of the typed data
+	SignTypedData(ctx context.Context, data service.TypedData) ([]byte, error)
+
+	// SignPersonal returns the 65-byte EIP-191 personal_sign signature of the message
+	SignPersonal(ctx context.Context, message []byte) ([]byte, error)
+}
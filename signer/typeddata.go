@@ -0,0 +1,187 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// eip712Domain is the name of the type describing the EIP-712 domain separator
+const eip712Domain = "EIP712Domain"
+
+// maxSafeInteger is the largest integer a float64 decoded from JSON is known to hold exactly,
+// like Number.MAX_SAFE_INTEGER. 2^53 itself may be a rounded 2^53 + 1.
+const maxSafeInteger = 1<<53 - 1
+
+// TypedDataHash returns the EIP-712 digest of the typed data, ready to be signed
+func TypedDataHash(data service.TypedData) (common.Hash, error) {
+	typedData, err := toAPITypes(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return common.BytesToHash(digest), nil
+}
+
+// SignTypedData returns the 65-byte EIP-712 signature of the typed data.
+// The recovery id is returned as 27 or 28, as expected by on-chain verifiers.
+func SignTypedData(data service.TypedData, key *ecdsa.PrivateKey) ([]byte, error) {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return nil, err
+	}
+	return signDigest(digest, key)
+}
+
+// RecoverTypedData returns the address that produced an EIP-712 signature of the typed data
+func RecoverTypedData(data service.TypedData, signature []byte) (common.Address, error) {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverDigest(digest, signature)
+}
+
+// VerifyTypedData checks that signature is an EIP-712 signature of the typed data by address
+func VerifyTypedData(address common.Address, data service.TypedData, signature []byte) error {
+	recovered, err := RecoverTypedData(data, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != address {
+		return fmt.Errorf("%w: expected %s, recovered %s", ErrSignatureMismatch, address.Hex(), recovered.Hex())
+	}
+	return nil
+}
+
+// signDigest signs a 32-byte digest and moves the recovery id into the [27, 28] range
+func signDigest(digest common.Hash, key *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(digest.Bytes(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign digest: %w", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// toAPITypes converts service typed data into the go-ethereum representation
+func toAPITypes(data service.TypedData) (apitypes.TypedData, error) {
+	domain := apitypes.TypedDataDomain{
+		Name:              data.Domain.Name,
+		VerifyingContract: data.Domain.VerifyingContract,
+	}
+	if data.Domain.ChainId != "" {
+		var chainID math.HexOrDecimal256
+		if err := chainID.UnmarshalText([]byte(data.Domain.ChainId)); err != nil {
+			return apitypes.TypedData{}, fmt.Errorf("invalid domain chain id %q: %w", data.Domain.ChainId, err)
+		}
+		domain.ChainId = &chainID
+	}
+	if data.Domain.Version != nil {
+		domain.Version = *data.Domain.Version
+	}
+	if data.Domain.Salt != nil {
+		domain.Salt = *data.Domain.Salt
+	}
+	if data.PrimaryType == "" {
+		return apitypes.TypedData{}, fmt.Errorf("typed data has no primary type")
+	}
+
+	types := make(apitypes.Types, len(data.Types)+1)
+	for name, fields := range data.Types {
+		converted := make([]apitypes.Type, len(fields))
+		for i, field := range fields {
+			converted[i] = apitypes.Type{Name: field.Name, Type: field.Type}
+		}
+		types[name] = converted
+	}
+	if _, ok := types[eip712Domain]; !ok {
+		types[eip712Domain] = domainType(domain)
+	}
+	if _, ok := types[data.PrimaryType]; !ok {
+		return apitypes.TypedData{}, fmt.Errorf("primary type %q is not defined", data.PrimaryType)
+	}
+
+	message := map[string]interface{}{}
+	if data.Message != nil {
+		normalized, err := normalizeValue(data.Message)
+		if err != nil {
+			return apitypes.TypedData{}, err
+		}
+		message = normalized.(map[string]interface{})
+	}
+
+	return apitypes.TypedData{
+		Types:       types,
+		PrimaryType: data.PrimaryType,
+		Domain:      domain,
+		Message:     message,
+	}, nil
+}
+
+// domainType builds the EIP712Domain type from the fields present in the domain,
+// in the order defined by EIP-712
+func domainType(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var fields []apitypes.Type
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// normalizeValue copies a decoded JSON message, rejecting numbers that lost precision
+// while being decoded into float64
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			n, err := normalizeValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			normalized[key] = n
+		}
+		return normalized, nil
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			n, err := normalizeValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			normalized[i] = n
+		}
+		return normalized, nil
+	case float64:
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			return nil, fmt.Errorf("number %v cannot be represented exactly, encode it as a string", v)
+		}
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return v, nil
+	}
+}
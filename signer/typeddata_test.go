@@ -0,0 +1,165 @@
+package signer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+// eip712Mail is the example of the EIP-712 specification, as the service API encodes typed data
+const eip712Mail = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": "1",
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+// decodeTypedData decodes typed data like the service client does
+func decodeTypedData(t *testing.T, s string) service.TypedData {
+	t.Helper()
+	var data service.TypedData
+	if err := json.Unmarshal([]byte(s), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestTypedDataHashMail(t *testing.T) {
+	data := decodeTypedData(t, eip712Mail)
+	typedData, err := toAPITypes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	separator, err := typedData.HashStruct(eip712Domain, typedData.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; separator.String() != want {
+		t.Fatalf("expected domain separator %s, got %s", want, separator)
+	}
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; digest.Hex() != want {
+		t.Fatalf("expected digest %s, got %s", want, digest.Hex())
+	}
+
+	// the domain type is derived from the domain when the API leaves it out
+	delete(data.Types, eip712Domain)
+	if derived, err := TypedDataHash(data); err != nil || derived != digest {
+		t.Fatalf("expected the same digest with a derived domain type, got %s, %v", derived.Hex(), err)
+	}
+}
+
+func TestSignTypedDataMail(t *testing.T) {
+	data := decodeTypedData(t, eip712Mail)
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := SignTypedData(data, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// r, s and v of the signature in the specification
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if got := hexutil.Encode(signature); got != want {
+		t.Fatalf("expected signature %s, got %s", want, got)
+	}
+	cow := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	if err := VerifyTypedData(cow, data, signature); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypedDataIntegers(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "small number", value: `42`},
+		{name: "largest safe integer", value: `9007199254740991`},
+		{name: "smallest safe integer", value: `-9007199254740991`},
+		{name: "2^53", value: `9007199254740992`, wantErr: true},
+		{name: "rounded above 2^53", value: `9007199254740993`, wantErr: true},
+		{name: "below -2^53", value: `-9007199254740993`, wantErr: true},
+		{name: "wei amount", value: `1e18`, wantErr: true},
+		{name: "wei amount as a string", value: `"1000000000000000000"`},
+		{name: "above 2^53 as a string", value: `"9007199254740993"`},
+		{name: "nested in an array", value: `[1, 9007199254740993]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldType := "int256"
+			if tt.value[0] == '[' {
+				fieldType = "int256[]"
+			}
+			data := decodeTypedData(t, `{
+				"types": {"Transfer": [{"name": "amount", "type": "`+fieldType+`"}]},
+				"primaryType": "Transfer",
+				"domain": {"name": "Zarban", "chainId": "1", "verifyingContract": "`+protocolContract+`"},
+				"message": {"amount": `+tt.value+`}
+			}`)
+			_, err := TypedDataHash(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	value := map[string]interface{}{
+		"amount": json.Number("9007199254740993"),
+		"nested": map[string]interface{}{"values": []interface{}{float64(1), json.Number("2")}},
+	}
+	normalized, err := normalizeValue(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := normalized.(map[string]interface{})
+	if got["amount"] != "9007199254740993" {
+		t.Fatalf("expected a json.Number to be kept exactly, got %v", got["amount"])
+	}
+	values := got["nested"].(map[string]interface{})["values"].([]interface{})
+	if values[0] != float64(1) || values[1] != "2" {
+		t.Fatalf("unexpected nested values %v", values)
+	}
+
+	_, err = normalizeValue(map[string]interface{}{"nested": []interface{}{1e16}})
+	if err == nil || err.Error() != "nested: [0]: number 1e+16 cannot be represented exactly, encode it as a string" {
+		t.Fatalf("expected the path of the number in the error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zarbanio/zarban-go/service"
+)
+
+var (
+	// ErrHashMismatch is returned when a server-supplied hash does not match the typed data
+	ErrHashMismatch = errors.New("hash does not match typed data")
+
+	// ErrUnknownContract is returned when the verifying contract is not a protocol contract
+	ErrUnknownContract = errors.New("verifying contract is not a protocol contract")
+
+	// ErrMissingContract is returned when the domain has no verifying contract and its primary
+	// type is not allowed to omit it
+	ErrMissingContract = errors.New("typed data has no verifying contract")
+)
+
+// TamperError reports server-supplied signing data that failed local verification
+type TamperError struct {
+	// Kind is ErrHashMismatch, ErrUnknownContract or ErrMissingContract
+	Kind error
+
+	// Name of the sign request, if any
+	Name string
+
+	// Expected is the locally computed value, Actual the one supplied by the server
+	Expected string
+	Actual   string
+}
+
+// Error implements the error interface
+func (e *TamperError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s: %v: expected %s, got %s", e.Name, e.Kind, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("%v: expected %s, got %s", e.Kind, e.Expected, e.Actual)
+}
+
+// Unwrap returns the kind of tampering, so errors.Is works with ErrHashMismatch, ErrUnknownContract
+// and ErrMissingContract
+func (e *TamperError) Unwrap() error {
+	return e.Kind
+}
+
+// VerifyTypedDataHash recomputes the EIP-712 digest of the typed data and compares it with hash
+func VerifyTypedDataHash(data service.TypedData, hash string) error {
+	digest, err := TypedDataHash(data)
+	if err != nil {
+		return err
+	}
+	supplied, err := hexutil.Decode(hash)
+	if err != nil || len(supplied) != common.HashLength || common.BytesToHash(supplied) != digest {
+		return &TamperError{Kind: ErrHashMismatch, Expected: digest.Hex(), Actual: hash}
+	}
+	return nil
+}
+
+// Verifier checks server-supplied EIP-712 requests before they are signed
+type Verifier struct {
+	addresses *service.AddressBook
+
+	// withoutContract lists the primary types allowed to omit the verifying contract
+	withoutContract map[string]bool
+}
+
+// VerifierOption allows setting custom parameters on a Verifier
+type VerifierOption func(*Verifier) error
+
+// NewVerifier creates a Verifier that accepts the protocol contracts known to the address book
+func NewVerifier(addresses *service.AddressBook, opts ...VerifierOption) (*Verifier, error) {
+	if addresses == nil {
+		return nil, errors.New("address book is required")
+	}
+	v := Verifier{
+		addresses:       addresses,
+		withoutContract: make(map[string]bool),
+	}
+	for _, o := range opts {
+		if err := o(&v); err != nil {
+			return nil, err
+		}
+	}
+	return &v, nil
+}
+
+// WithoutVerifyingContract allows typed data of the given primary types to have a domain without
+// a verifying contract. Typed data of any other primary type must name a protocol contract.
+func WithoutVerifyingContract(primaryTypes ...string) VerifierOption {
+	return func(v *Verifier) error {
+		for _, primaryType := range primaryTypes {
+			if primaryType == "" {
+				return errors.New("primary type is required")
+			}
+			v.withoutContract[primaryType] = true
+		}
+		return nil
+	}
+}
+
+// Verify checks that hash is the digest of data and that its verifying contract is a protocol
+// contract. A missing verifying contract is rejected unless its primary type was allowed with
+// WithoutVerifyingContract.
+func (v *Verifier) Verify(ctx context.Context, hash string, data service.TypedData) error {
+	if err := VerifyTypedDataHash(data, hash); err != nil {
+		return err
+	}
+	contract := data.Domain.VerifyingContract
+	if contract == "" {
+		if v.withoutContract[data.PrimaryType] {
+			return nil
+		}
+		return &TamperError{Kind: ErrMissingContract, Expected: "a protocol contract", Actual: "none for " + data.PrimaryType}
+	}
+	_, ok, err := v.addresses.Label(ctx, contract)
+	if err != nil {
+		return fmt.Errorf("failed to get protocol addresses: %w", err)
+	}
+	if !ok {
+		return &TamperError{Kind: ErrUnknownContract, Expected: "a protocol contract", Actual: contract}
+	}
+	return nil
+}
+
+// VerifyEIP712SignRequest verifies an EIP712SignRequest step
+func (v *Verifier) VerifyEIP712SignRequest(ctx context.Context, request service.EIP712SignRequest) error {
+	err := v.Verify(ctx, request.Hash, request.TypedData)
+	var tamperErr *TamperError
+	if errors.As(err, &tamperErr) {
+		tamperErr.Name = request.Name
+	}
+	return err
+}
+
+// VerifyPermitSingle verifies a Permit2 permit returned by GetSingleTokenPermit
+func (v *Verifier) VerifyPermitSingle(ctx context.Context, permit service.PermitSingle) error {
+	return v.Verify(ctx, permit.Hash, permit.TypedData)
+}
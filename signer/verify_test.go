@@ -0,0 +1,123 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zarbanio/zarban-go/service"
+)
+
+const protocolContract = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+// newAddressBook returns an address book backed by a stand-in service API knowing addresses
+func newAddressBook(t *testing.T, addresses ...string) *service.AddressBook {
+	t.Helper()
+	var response service.AddressResponse
+	for _, address := range addresses {
+		response.Data = append(response.Data, service.Address{Address: address, Label: "Permit2"})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := service.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service.NewAddressBook(client, 0)
+}
+
+// mailTypedData returns typed data with the given primary type and verifying contract
+func mailTypedData(primaryType, contract string) service.TypedData {
+	return service.TypedData{
+		Domain: service.TypedDataDomain{ChainId: "1", Name: "Zarban", VerifyingContract: contract},
+		Types: service.Types{
+			primaryType: {{Name: "contents", Type: "string"}},
+		},
+		PrimaryType: primaryType,
+		Message:     map[string]interface{}{"contents": "hello"},
+	}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    service.TypedData
+		hash    string
+		opts    []VerifierOption
+		wantErr error
+	}{
+		{
+			name: "protocol contract",
+			data: mailTypedData("Mail", protocolContract),
+		},
+		{
+			name:    "unknown contract",
+			data:    mailTypedData("Mail", "0x00000000000000000000000000000000000000bb"),
+			wantErr: ErrUnknownContract,
+		},
+		{
+			name:    "hash mismatch",
+			data:    mailTypedData("Mail", protocolContract),
+			hash:    "0x" + "11" + "00000000000000000000000000000000000000000000000000000000000000",
+			wantErr: ErrHashMismatch,
+		},
+		{
+			name:    "missing contract",
+			data:    mailTypedData("Mail", ""),
+			wantErr: ErrMissingContract,
+		},
+		{
+			name: "missing contract allowed for primary type",
+			data: mailTypedData("Mail", ""),
+			opts: []VerifierOption{WithoutVerifyingContract("Mail")},
+		},
+		{
+			name:    "missing contract allowed for another primary type",
+			data:    mailTypedData("Mail", ""),
+			opts:    []VerifierOption{WithoutVerifyingContract("Login")},
+			wantErr: ErrMissingContract,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVerifier(newAddressBook(t, protocolContract), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash := tt.hash
+			if hash == "" {
+				digest, err := TypedDataHash(tt.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				hash = digest.Hex()
+			}
+			err = v.Verify(context.Background(), hash, tt.data)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var tamperErr *TamperError
+			if !errors.As(err, &tamperErr) {
+				t.Fatalf("expected a *TamperError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestWithoutVerifyingContractRejectsEmptyType(t *testing.T) {
+	if _, err := NewVerifier(newAddressBook(t), WithoutVerifyingContract("")); err == nil {
+		t.Fatal("expected an error for an empty primary type")
+	}
+}
@@ -0,0 +1,267 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// ErrCircuitOpen is returned for requests to a host whose circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned by a Breaker for requests to a host whose circuit is open
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v for %s until %s", ErrCircuitOpen, e.Host, e.Until.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// State is the state of the circuit of a host
+type State int
+
+const (
+	// StateClosed lets requests through
+	StateClosed State = iota
+	// StateOpen fails requests fast until the cooldown has elapsed
+	StateOpen
+	// StateHalfOpen probes the host before letting requests through again
+	StateHalfOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Probe checks whether the API at server is healthy again
+type Probe func(ctx context.Context, server string) error
+
+// HealthProbe returns a Probe calling CheckApiHealth of the wallet API at the root of the host
+// with doer. The service API has no health endpoint, so only use it for wallet API hosts.
+func HealthProbe(doer Doer) Probe {
+	return func(ctx context.Context, server string) error {
+		api, err := wallet.NewAPIWithServer(server, wallet.WithHTTPClient(doer))
+		if err != nil {
+			return err
+		}
+		_, err = api.CheckApiHealth(ctx)
+		return err
+	}
+}
+
+// Breaker fails requests fast while their host is unhealthy. The circuit of a host opens after
+// a number of consecutive failures, which are connection errors, 5xx responses and responses
+// slower than the latency threshold. Once the cooldown has elapsed a single request is let
+// through as a trial, or the host is probed first with WithProbe, and the circuit closes again
+// when it succeeds.
+type Breaker struct {
+	next         Doer
+	failures     int
+	slow         time.Duration
+	cooldown     time.Duration
+	probe        Probe
+	probeTimeout time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of a host
+type circuit struct {
+	state    State
+	failures int
+	until    time.Time
+}
+
+// BreakerOption allows setting custom parameters on a Breaker
+type BreakerOption func(*Breaker) error
+
+// NewBreaker creates a Breaker sending requests with next, or http.DefaultClient if next is nil
+func NewBreaker(next Doer, opts ...BreakerOption) (*Breaker, error) {
+	b := Breaker{
+		next:         defaultDoer(next),
+		failures:     5,
+		slow:         10 * time.Second,
+		cooldown:     30 * time.Second,
+		probeTimeout: 5 * time.Second,
+		circuits:     make(map[string]*circuit),
+	}
+	for _, o := range opts {
+		if err := o(&b); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// WithFailureThreshold sets the number of consecutive failures opening a circuit, defaults to 5
+func WithFailureThreshold(failures int) BreakerOption {
+	return func(b *Breaker) error {
+		if failures < 1 {
+			return fmt.Errorf("invalid failure threshold: %d", failures)
+		}
+		b.failures = failures
+		return nil
+	}
+}
+
+// WithLatencyThreshold sets the latency above which responses count as failures, defaults to
+// 10s. Zero disables it.
+func WithLatencyThreshold(d time.Duration) BreakerOption {
+	return func(b *Breaker) error {
+		b.slow = d
+		return nil
+	}
+}
+
+// WithCooldown sets how long a circuit stays open before the host is probed, defaults to 30s
+func WithCooldown(d time.Duration) BreakerOption {
+	return func(b *Breaker) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid cooldown: %v", d)
+		}
+		b.cooldown = d
+		return nil
+	}
+}
+
+// WithProbe sets how hosts are probed before requests are let through again, e.g. HealthProbe
+// for wallet API hosts. The probe is given the context of the request waiting for it, limited to
+// timeout. A nil probe, the default, lets a single request through as the probe instead.
+func WithProbe(probe Probe, timeout time.Duration) BreakerOption {
+	return func(b *Breaker) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid probe timeout: %v", timeout)
+		}
+		b.probe = probe
+		b.probeTimeout = timeout
+		return nil
+	}
+}
+
+// State returns the state of the circuit of host
+func (b *Breaker) State(host string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[host]; ok {
+		return c.state
+	}
+	return StateClosed
+}
+
+// Do sends the request unless the circuit of its host is open
+func (b *Breaker) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	trial, err := b.allow(req.Context(), req.URL.Scheme, host)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := b.next.Do(req)
+	if req.Context().Err() != nil {
+		// the caller gave up, which says nothing about the host
+		if trial {
+			// let another trial request through
+			b.reopen(host, time.Now())
+		}
+		return resp, err
+	}
+	failed := err != nil ||
+		(resp != nil && resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented) ||
+		(b.slow > 0 && time.Since(start) > b.slow)
+	b.record(host, failed)
+	return resp, err
+}
+
+// allow checks the circuit of host, and probes it once the cooldown has elapsed. It reports
+// whether the request is the trial request of a half-open circuit without a probe.
+func (b *Breaker) allow(ctx context.Context, scheme, host string) (bool, error) {
+	b.mu.Lock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{}
+		b.circuits[host] = c
+	}
+	switch {
+	case c.state == StateClosed:
+		b.mu.Unlock()
+		return false, nil
+	case c.state == StateHalfOpen || time.Now().Before(c.until):
+		until := c.until
+		b.mu.Unlock()
+		return false, &CircuitOpenError{Host: host, Until: until}
+	}
+	c.state = StateHalfOpen
+	b.mu.Unlock()
+
+	if b.probe == nil {
+		return true, nil
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, b.probeTimeout)
+	defer cancel()
+	if err := b.probe(probeCtx, scheme+"://"+host); err != nil {
+		if ctx.Err() != nil {
+			// the caller gave up on the probe, let the next request probe again
+			b.reopen(host, time.Now())
+			return false, ctx.Err()
+		}
+		until := b.reopen(host, time.Now().Add(b.cooldown))
+		return false, &CircuitOpenError{Host: host, Until: until}
+	}
+	b.record(host, false)
+	return false, nil
+}
+
+// record updates the circuit of host with the outcome of a request. A success sent before the
+// circuit opened does not close it again.
+func (b *Breaker) record(host string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[host]
+	if !failed {
+		if c.state == StateOpen {
+			return
+		}
+		c.state = StateClosed
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.state == StateHalfOpen || c.failures >= b.failures {
+		c.state = StateOpen
+		c.until = time.Now().Add(b.cooldown)
+	}
+}
+
+// reopen opens the circuit of host until the given time, and returns it
+func (b *Breaker) reopen(host string, until time.Time) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[host]
+	c.state = StateOpen
+	c.until = until
+	return until
+}
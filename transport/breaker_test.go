@@ -0,0 +1,307 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// doerFunc is a Doer calling a function
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBreaker(t *testing.T) {
+	errProbe := errors.New("probe failed")
+	type step struct {
+		sleep     time.Duration
+		wantErr   error
+		wantState State
+	}
+	tests := []struct {
+		name      string
+		opts      []BreakerOption
+		replies   []reply
+		steps     []step
+		wantCalls int
+	}{
+		{
+			name:    "failures open the circuit",
+			replies: []reply{{status: 503}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateOpen},
+				{wantErr: ErrCircuitOpen, wantState: StateOpen},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:    "connection errors are failures",
+			replies: []reply{{err: io.ErrUnexpectedEOF}},
+			steps: []step{
+				{wantErr: io.ErrUnexpectedEOF, wantState: StateClosed},
+				{wantErr: io.ErrUnexpectedEOF, wantState: StateOpen},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:    "success resets the failures",
+			replies: []reply{{status: 500}, {status: 200}, {status: 500}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateClosed},
+				{wantState: StateClosed},
+			},
+			wantCalls: 3,
+		},
+		{
+			name:    "501 is not a failure",
+			replies: []reply{{status: 501}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateClosed},
+				{wantState: StateClosed},
+			},
+			wantCalls: 3,
+		},
+		{
+			name:    "successful trial closes the circuit",
+			replies: []reply{{status: 503}, {status: 503}, {status: 200}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateOpen},
+				{sleep: 20 * time.Millisecond, wantState: StateClosed},
+			},
+			wantCalls: 3,
+		},
+		{
+			name:    "failed trial opens the circuit",
+			replies: []reply{{status: 503}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateOpen},
+				{sleep: 20 * time.Millisecond, wantState: StateOpen},
+				{wantErr: ErrCircuitOpen, wantState: StateOpen},
+			},
+			wantCalls: 3,
+		},
+		{
+			name:    "successful probe closes the circuit",
+			opts:    []BreakerOption{WithProbe(func(context.Context, string) error { return nil }, time.Second)},
+			replies: []reply{{status: 503}, {status: 503}, {status: 200}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateOpen},
+				{sleep: 20 * time.Millisecond, wantState: StateClosed},
+			},
+			wantCalls: 3,
+		},
+		{
+			name:    "failed probe keeps the circuit open",
+			opts:    []BreakerOption{WithProbe(func(context.Context, string) error { return errProbe }, time.Second)},
+			replies: []reply{{status: 503}},
+			steps: []step{
+				{wantState: StateClosed},
+				{wantState: StateOpen},
+				{sleep: 20 * time.Millisecond, wantErr: ErrCircuitOpen, wantState: StateOpen},
+			},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doer := &scriptedDoer{replies: tt.replies}
+			opts := append([]BreakerOption{
+				WithFailureThreshold(2),
+				WithCooldown(10 * time.Millisecond),
+			}, tt.opts...)
+			b, err := NewBreaker(doer, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range tt.steps {
+				time.Sleep(s.sleep)
+				resp, err := b.Do(newRequest(t, context.Background()))
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: expected %v, got %v", i, s.wantErr, err)
+				}
+				if resp != nil {
+					resp.Body.Close()
+				}
+				if state := b.State("api.zarban.io"); state != s.wantState {
+					t.Fatalf("step %d: expected %s circuit, got %s", i, s.wantState, state)
+				}
+			}
+			if doer.calls() != tt.wantCalls {
+				t.Fatalf("expected %d calls, got %d", tt.wantCalls, doer.calls())
+			}
+		})
+	}
+}
+
+func TestBreakerProbeUsesRequestContext(t *testing.T) {
+	var probes int32
+	probe := func(ctx context.Context, server string) error {
+		if atomic.AddInt32(&probes, 1) > 1 {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	doer := &scriptedDoer{replies: []reply{{status: 503}, {status: 200}}}
+	b, err := NewBreaker(doer, WithFailureThreshold(1), WithCooldown(10*time.Millisecond), WithProbe(probe, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := b.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := b.Do(newRequest(t, ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the probe to stop with the request, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the probe to stop with the request, took %v", elapsed)
+	}
+
+	// the next request probes again right away
+	resp, err = b.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if state := b.State("api.zarban.io"); state != StateClosed {
+		t.Fatalf("expected closed circuit, got %s", state)
+	}
+}
+
+func TestBreakerIgnoresCancelledRequests(t *testing.T) {
+	blocking := doerFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	b, err := NewBreaker(blocking, WithFailureThreshold(1), WithCooldown(10*time.Millisecond), WithProbe(nil, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Do(newRequest(t, ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to be cancelled, got %v", err)
+	}
+	if state := b.State("api.zarban.io"); state != StateClosed {
+		t.Fatalf("expected closed circuit, got %s", state)
+	}
+}
+
+func TestBreakerCancelledTrial(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusServiceUnavailable
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+	b, err := NewBreaker(doer, WithFailureThreshold(1), WithCooldown(10*time.Millisecond), WithProbe(nil, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := b.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Do(newRequest(t, ctx)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the trial to be cancelled, got %v", err)
+	}
+	if state := b.State("api.zarban.io"); state != StateOpen {
+		t.Fatalf("expected open circuit, got %s", state)
+	}
+
+	// another trial is let through right away
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	resp, err = b.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if state := b.State("api.zarban.io"); state != StateClosed {
+		t.Fatalf("expected closed circuit, got %s", state)
+	}
+}
+
+func TestBreakerLateSuccess(t *testing.T) {
+	unblock := make(chan struct{})
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusServiceUnavailable
+		if req.URL.Path == "/v2/slow" {
+			<-unblock
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+	b, err := NewBreaker(doer, WithFailureThreshold(1), WithCooldown(time.Minute), WithLatencyThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		req, err := http.NewRequest(http.MethodGet, "https://api.zarban.io/v2/slow", nil)
+		if err != nil {
+			done <- err
+			return
+		}
+		resp, err := b.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	// wait for the slow request to get past the closed circuit
+	for started := false; !started; time.Sleep(time.Millisecond) {
+		b.mu.Lock()
+		_, started = b.circuits["api.zarban.io"]
+		b.mu.Unlock()
+	}
+
+	resp, err := b.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if state := b.State("api.zarban.io"); state != StateOpen {
+		t.Fatalf("expected open circuit, got %s", state)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if state := b.State("api.zarban.io"); state != StateOpen {
+		t.Fatalf("expected the late success to leave the circuit open, got %s", state)
+	}
+}
@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// Failover sends requests to an ordered list of base URLs of the same API, such as a mirror or
+// a regional endpoint. Clients are created with the first base URL, and each request is sent to
+// the first base URL that does not fail.
+//
+// GET and HEAD requests fail over on connection errors and 5xx responses. Other requests only
+// fail over when they were not sent, because the circuit of the host is open or the connection
+// could not be established, so that they are never submitted twice. Stack the Failover above a
+// Breaker so that unhealthy base URLs are skipped without waiting for them.
+type Failover struct {
+	next    Doer
+	servers []*url.URL
+}
+
+// NewFailover creates a Failover sending requests to servers with next, or http.DefaultClient if
+// next is nil
+func NewFailover(next Doer, servers ...string) (*Failover, error) {
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers to fail over")
+	}
+	f := Failover{next: defaultDoer(next)}
+	for _, server := range servers {
+		u, err := url.Parse(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse server %q: %w", server, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid server %q", server)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		f.servers = append(f.servers, u)
+	}
+	return &f, nil
+}
+
+// Server returns the first base URL, to create clients with
+func (f *Failover) Server() string {
+	return f.servers[0].String()
+}
+
+// Do sends the request to the first base URL that does not fail
+func (f *Failover) Do(req *http.Request) (*http.Response, error) {
+	origin := f.origin(req.URL)
+	if origin < 0 {
+		return f.next.Do(req)
+	}
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	var resp *http.Response
+	var err error
+	for i, server := range f.servers {
+		last := i == len(f.servers)-1 || !rewindable
+		attemptReq := req
+		if i != origin || i > 0 {
+			if attemptReq, err = f.rewrite(req, f.servers[origin], server, i > 0); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = f.next.Do(attemptReq)
+		if last || req.Context().Err() != nil {
+			return resp, err
+		}
+		switch {
+		case err != nil:
+			if !notSent(err) && !(idempotent && isTransient(err)) {
+				return resp, err
+			}
+		case idempotent && resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+	}
+	return resp, err
+}
+
+// origin returns the index of the base URL u was created with, or -1
+func (f *Failover) origin(u *url.URL) int {
+	for i, server := range f.servers {
+		if u.Scheme == server.Scheme && u.Host == server.Host && strings.HasPrefix(u.Path, server.Path+"/") {
+			return i
+		}
+	}
+	return -1
+}
+
+// rewrite returns a copy of req moved from the base URL from to the base URL to, with a fresh
+// body if the request was sent before
+func (f *Failover) rewrite(req *http.Request, from, to *url.URL, sent bool) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	clone.URL.Scheme = to.Scheme
+	clone.URL.Host = to.Host
+	clone.URL.Path = to.Path + strings.TrimPrefix(req.URL.Path, from.Path)
+	if req.URL.RawPath != "" {
+		clone.URL.RawPath = to.EscapedPath() + strings.TrimPrefix(req.URL.RawPath, from.EscapedPath())
+	}
+	clone.Host = ""
+	if sent && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// notSent reports whether a request error happened before the request was sent
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	case errors.As(err, &dnsErr):
+		return true
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return true
+	}
+	return false
+}
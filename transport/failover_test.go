@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		replies    map[string]reply
+		wantStatus int
+		wantErr    error
+		wantURLs   []string
+	}{
+		{
+			name:       "first server",
+			method:     http.MethodGet,
+			url:        "https://api.zarban.io/v2/orders?page=2",
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders?page=2"},
+		},
+		{
+			name:       "get fails over on 5xx",
+			method:     http.MethodGet,
+			url:        "https://api.zarban.io/v2/orders?page=2",
+			replies:    map[string]reply{"api.zarban.io": {status: 503}},
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders?page=2", "https://mirror.zarban.io/api/v2/orders?page=2"},
+		},
+		{
+			name:       "get fails over on connection errors",
+			method:     http.MethodGet,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {err: io.ErrUnexpectedEOF}},
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders", "https://mirror.zarban.io/api/v2/orders"},
+		},
+		{
+			name:       "get does not fail over on 501",
+			method:     http.MethodGet,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {status: 501}},
+			wantStatus: 501,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders"},
+		},
+		{
+			name:       "last server",
+			method:     http.MethodGet,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {status: 503}, "mirror.zarban.io": {status: 502}},
+			wantStatus: 502,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders", "https://mirror.zarban.io/api/v2/orders"},
+		},
+		{
+			name:       "post does not fail over on 5xx",
+			method:     http.MethodPost,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {status: 503}},
+			wantStatus: 503,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders"},
+		},
+		{
+			name:     "post does not fail over once sent",
+			method:   http.MethodPost,
+			url:      "https://api.zarban.io/v2/orders",
+			replies:  map[string]reply{"api.zarban.io": {err: io.ErrUnexpectedEOF}},
+			wantErr:  io.ErrUnexpectedEOF,
+			wantURLs: []string{"https://api.zarban.io/v2/orders"},
+		},
+		{
+			name:       "post fails over when the circuit is open",
+			method:     http.MethodPost,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {err: &CircuitOpenError{Host: "api.zarban.io"}}},
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders", "https://mirror.zarban.io/api/v2/orders"},
+		},
+		{
+			name:       "post fails over when the connection is refused",
+			method:     http.MethodPost,
+			url:        "https://api.zarban.io/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {err: syscall.ECONNREFUSED}},
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders", "https://mirror.zarban.io/api/v2/orders"},
+		},
+		{
+			name:       "requests to later servers start with the first",
+			method:     http.MethodGet,
+			url:        "https://mirror.zarban.io/api/v2/orders",
+			replies:    map[string]reply{"api.zarban.io": {status: 503}},
+			wantStatus: 200,
+			wantURLs:   []string{"https://api.zarban.io/v2/orders", "https://mirror.zarban.io/api/v2/orders"},
+		},
+		{
+			name:       "other hosts are passed through",
+			method:     http.MethodGet,
+			url:        "https://rpc.zarban.io/v2/orders",
+			replies:    map[string]reply{"rpc.zarban.io": {status: 503}},
+			wantStatus: 503,
+			wantURLs:   []string{"https://rpc.zarban.io/v2/orders"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var urls, bodies []string
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				urls = append(urls, req.URL.String())
+				body := ""
+				if req.Body != nil {
+					data, _ := io.ReadAll(req.Body)
+					body = string(data)
+				}
+				bodies = append(bodies, body)
+				r, ok := tt.replies[req.URL.Host]
+				if !ok {
+					r = reply{status: 200}
+				}
+				if r.err != nil {
+					return nil, r.err
+				}
+				return &http.Response{StatusCode: r.status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+			})
+			f, err := NewFailover(doer, "https://api.zarban.io", "https://mirror.zarban.io/api/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := f.Do(req)
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if strings.Join(urls, " ") != strings.Join(tt.wantURLs, " ") {
+				t.Fatalf("expected requests to %v, got %v", tt.wantURLs, urls)
+			}
+			for i, body := range bodies {
+				if body != "payload" {
+					t.Fatalf("attempt %d sent body %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFailover(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []string
+		wantErr bool
+	}{
+		{name: "valid", servers: []string{"https://api.zarban.io", "https://mirror.zarban.io/api"}},
+		{name: "no servers", wantErr: true},
+		{name: "relative url", servers: []string{"/api"}, wantErr: true},
+		{name: "invalid url", servers: []string{"https://api.zarban.io/%zz"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFailover(nil, tt.servers...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
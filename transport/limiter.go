@@ -0,0 +1,286 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limit is a request budget. A zero Rate or MaxInFlight leaves that dimension unlimited.
+type Limit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+	// Burst is the number of requests that may be sent at once above Rate, at least 1
+	Burst int
+	// MaxInFlight is the number of requests that may be in flight at once. A request is in
+	// flight until its response body is closed.
+	MaxInFlight int
+}
+
+// validate checks the limit and fills in the default burst
+func (l *Limit) validate() error {
+	if l.Rate < 0 || l.Burst < 0 || l.MaxInFlight < 0 {
+		return fmt.Errorf("invalid limit: %+v", *l)
+	}
+	if l.Rate > 0 && l.Burst == 0 {
+		l.Burst = 1
+	}
+	return nil
+}
+
+// Limiter delays requests so that they stay within the budgets of their API host and of their
+// operation. Requests wait for their budget until their context is done instead of failing.
+type Limiter struct {
+	next        Doer
+	hostDefault Limit
+	hostLimits  map[string]Limit
+	opLimits    map[string]Limit
+	resolve     OperationResolver
+
+	mu    sync.Mutex
+	hosts map[string]*budget
+	ops   map[string]*budget
+}
+
+// LimiterOption allows setting custom parameters on a Limiter
+type LimiterOption func(*Limiter) error
+
+// NewLimiter creates a Limiter sending requests with next, or http.DefaultClient if next is nil
+func NewLimiter(next Doer, opts ...LimiterOption) (*Limiter, error) {
+	l := Limiter{
+		next:       defaultDoer(next),
+		hostLimits: make(map[string]Limit),
+		opLimits:   make(map[string]Limit),
+		resolve:    ResolveOperation,
+		hosts:      make(map[string]*budget),
+		ops:        make(map[string]*budget),
+	}
+	for _, o := range opts {
+		if err := o(&l); err != nil {
+			return nil, err
+		}
+	}
+	return &l, nil
+}
+
+// WithDefaultHostLimit sets the budget of each API host without a budget of its own
+func WithDefaultHostLimit(limit Limit) LimiterOption {
+	return func(l *Limiter) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+		l.hostDefault = limit
+		return nil
+	}
+}
+
+// WithHostLimit sets the budget of an API host, as in the host of the server URL, e.g.
+// "api.zarban.io"
+func WithHostLimit(host string, limit Limit) LimiterOption {
+	return func(l *Limiter) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+		l.hostLimits[host] = limit
+		return nil
+	}
+}
+
+// WithOperationLimit sets the budget of an operation by operationId, shared by all hosts.
+// Requests to operations without a budget are only limited by their host.
+func WithOperationLimit(id string, limit Limit) LimiterOption {
+	return func(l *Limiter) error {
+		if err := limit.validate(); err != nil {
+			return err
+		}
+		l.opLimits[id] = limit
+		return nil
+	}
+}
+
+// WithLimiterOperationResolver sets how the operationId of a request is found, defaults to
+// ResolveOperation
+func WithLimiterOperationResolver(resolve OperationResolver) LimiterOption {
+	return func(l *Limiter) error {
+		l.resolve = resolve
+		return nil
+	}
+}
+
+// Do waits for the budgets of the request and sends it
+func (l *Limiter) Do(req *http.Request) (*http.Response, error) {
+	budgets := []*budget{l.hostBudget(req.URL.Host)}
+	if len(l.opLimits) > 0 && l.resolve != nil {
+		if b := l.operationBudget(l.resolve(req)); b != nil {
+			budgets = append(budgets, b)
+		}
+	}
+
+	ctx := req.Context()
+	var acquired []*budget
+	release := func() {
+		for _, b := range acquired {
+			b.release()
+		}
+	}
+	// take the operation slot first, so that requests waiting for it do not hold a host slot
+	// that other operations could use
+	for i := len(budgets) - 1; i >= 0; i-- {
+		if err := budgets[i].acquire(ctx); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, budgets[i])
+	}
+	for i, b := range budgets {
+		if err := b.wait(ctx); err != nil {
+			// the earlier budgets were not used either, give their tokens back
+			for _, waited := range budgets[:i] {
+				waited.refund()
+			}
+			release()
+			return nil, err
+		}
+	}
+
+	resp, err := l.next.Do(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// hostBudget returns the budget of a host, creating it on first use
+func (l *Limiter) hostBudget(host string) *budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.hosts[host]
+	if !ok {
+		limit, ok := l.hostLimits[host]
+		if !ok {
+			limit = l.hostDefault
+		}
+		b = newBudget(limit)
+		l.hosts[host] = b
+	}
+	return b
+}
+
+// operationBudget returns the budget of an operation, or nil if it has none
+func (l *Limiter) operationBudget(id string) *budget {
+	limit, ok := l.opLimits[id]
+	if !ok {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.ops[id]
+	if !ok {
+		b = newBudget(limit)
+		l.ops[id] = b
+	}
+	return b
+}
+
+// budget is a token bucket together with a semaphore of in-flight requests
+type budget struct {
+	rate  float64
+	burst float64
+	slots chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBudget(limit Limit) *budget {
+	b := budget{rate: limit.Rate, burst: float64(limit.Burst), tokens: float64(limit.Burst), last: time.Now()}
+	if limit.MaxInFlight > 0 {
+		b.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+	return &b
+}
+
+// acquire waits for an in-flight slot
+func (b *budget) acquire(ctx context.Context) error {
+	if b.slots == nil {
+		return nil
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees an in-flight slot taken by acquire
+func (b *budget) release() {
+	if b.slots != nil {
+		<-b.slots
+	}
+}
+
+// wait takes a token from the bucket, waiting until one is available
+func (b *budget) wait(ctx context.Context) error {
+	if b.rate == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	t := time.Now()
+	if elapsed := t.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = t
+	}
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the token back so that later requests do not wait for it
+		b.refund()
+		return ctx.Err()
+	}
+}
+
+// refund gives back a token taken by wait for a request that was not sent
+func (b *budget) refund() {
+	if b.rate == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// releaseBody releases the budgets of a request when its response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
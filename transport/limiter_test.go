@@ -0,0 +1,204 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLimiterRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []LimiterOption
+		requests int
+		minTime  time.Duration
+		maxTime  time.Duration
+	}{
+		{
+			name:     "unlimited",
+			requests: 5,
+			maxTime:  50 * time.Millisecond,
+		},
+		{
+			name:     "within burst",
+			opts:     []LimiterOption{WithDefaultHostLimit(Limit{Rate: 10, Burst: 3})},
+			requests: 3,
+			maxTime:  50 * time.Millisecond,
+		},
+		{
+			name:     "above burst",
+			opts:     []LimiterOption{WithDefaultHostLimit(Limit{Rate: 20, Burst: 1})},
+			requests: 3,
+			minTime:  90 * time.Millisecond,
+			maxTime:  time.Second,
+		},
+		{
+			name: "host limit overrides the default",
+			opts: []LimiterOption{
+				WithDefaultHostLimit(Limit{Rate: 1, Burst: 1}),
+				WithHostLimit("api.zarban.io", Limit{Rate: 20, Burst: 1}),
+			},
+			requests: 3,
+			minTime:  90 * time.Millisecond,
+			maxTime:  time.Second,
+		},
+		{
+			name: "operation limit",
+			opts: []LimiterOption{
+				WithOperationLimit("GetOrders", Limit{Rate: 20, Burst: 1}),
+				WithLimiterOperationResolver(func(*http.Request) string { return "GetOrders" }),
+			},
+			requests: 3,
+			minTime:  90 * time.Millisecond,
+			maxTime:  time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLimiter(&scriptedDoer{replies: []reply{{status: 200}}}, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				resp, err := l.Do(newRequest(t, context.Background()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+			if elapsed := time.Since(start); elapsed < tt.minTime || elapsed > tt.maxTime {
+				t.Fatalf("expected %d requests to take between %v and %v, took %v", tt.requests, tt.minTime, tt.maxTime, elapsed)
+			}
+		})
+	}
+}
+
+func TestLimiterInvalidLimit(t *testing.T) {
+	if _, err := NewLimiter(nil, WithDefaultHostLimit(Limit{Rate: -1})); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	l, err := NewLimiter(&scriptedDoer{replies: []reply{{status: 200}}}, WithDefaultHostLimit(Limit{MaxInFlight: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := l.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Do(newRequest(t, ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second request to wait for the first, got %v", err)
+	}
+
+	first.Body.Close()
+	first.Body.Close()
+	second, err := l.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+}
+
+func TestLimiterOperationSlots(t *testing.T) {
+	l, err := NewLimiter(&scriptedDoer{replies: []reply{{status: 200}}},
+		WithDefaultHostLimit(Limit{MaxInFlight: 2}),
+		WithOperationLimit("GetUserTransactions", Limit{MaxInFlight: 1}),
+		WithLimiterOperationResolver(func(req *http.Request) string {
+			if req.URL.Path == "/v2/transactions" {
+				return "GetUserTransactions"
+			}
+			return "GetOrders"
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(ctx context.Context, path string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.zarban.io"+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l.Do(req)
+	}
+
+	first, err := send(context.Background(), "/v2/transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a second page waits for the operation slot
+	waiting, cancelWaiting := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		resp, err := send(waiting, "/v2/transactions")
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// another operation on the host is not blocked by the waiting page
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	other, err := send(ctx, "/v2/orders")
+	if err != nil {
+		t.Fatalf("expected the other operation to be sent, got %v", err)
+	}
+	other.Body.Close()
+
+	cancelWaiting()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the waiting page to be cancelled, got %v", err)
+	}
+	first.Body.Close()
+}
+
+func TestLimiterRefundsTokens(t *testing.T) {
+	l, err := NewLimiter(&scriptedDoer{replies: []reply{{status: 200}}},
+		WithDefaultHostLimit(Limit{Rate: 0.001, Burst: 2}),
+		WithOperationLimit("GetOrders", Limit{Rate: 0.001, Burst: 1}),
+		WithLimiterOperationResolver(func(*http.Request) string { return "GetOrders" }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := l.Do(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// the operation budget is spent, so the request is cancelled after taking a host token
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Do(newRequest(t, ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to wait for its operation, got %v", err)
+	}
+
+	for name, b := range map[string]*budget{"host": l.hostBudget("api.zarban.io"), "operation": l.operationBudget("GetOrders")} {
+		b.mu.Lock()
+		tokens := b.tokens
+		b.mu.Unlock()
+		want := b.burst - 1
+		if tokens < want || tokens > want+0.01 {
+			t.Errorf("expected %v %s tokens, got %v", want, name, tokens)
+		}
+	}
+}
+
+func newRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.zarban.io/v2/orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
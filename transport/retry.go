@@ -0,0 +1,243 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Retry retries requests failing with a transient error: a connection error, 429 Too Many
+// Requests or a 5xx response other than 501 Not Implemented. Only GET and HEAD requests are
+// retried by default, other operations must be opted in by operationId.
+type Retry struct {
+	next          Doer
+	maxAttempts   int
+	baseDelay     time.Duration
+	maxDelay      time.Duration
+	jitter        float64
+	maxRetryAfter time.Duration
+	operations    map[string]bool
+	resolve       OperationResolver
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// RetryOption allows setting custom parameters on a Retry
+type RetryOption func(*Retry) error
+
+// NewRetry creates a Retry sending requests with next, or http.DefaultClient if next is nil
+func NewRetry(next Doer, opts ...RetryOption) (*Retry, error) {
+	r := Retry{
+		next:          defaultDoer(next),
+		maxAttempts:   3,
+		baseDelay:     200 * time.Millisecond,
+		maxDelay:      10 * time.Second,
+		jitter:        0.5,
+		maxRetryAfter: time.Minute,
+		operations:    make(map[string]bool),
+		resolve:       ResolveOperation,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, o := range opts {
+		if err := o(&r); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// WithMaxAttempts sets the number of attempts including the first one, defaults to 3
+func WithMaxAttempts(attempts int) RetryOption {
+	return func(r *Retry) error {
+		if attempts < 1 {
+			return fmt.Errorf("invalid max attempts: %d", attempts)
+		}
+		r.maxAttempts = attempts
+		return nil
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled for each further retry up to
+// maxDelay, defaults to 200ms and 10s
+func WithBackoff(base, maxDelay time.Duration) RetryOption {
+	return func(r *Retry) error {
+		if base <= 0 || maxDelay < base {
+			return fmt.Errorf("invalid backoff: %v to %v", base, maxDelay)
+		}
+		r.baseDelay = base
+		r.maxDelay = maxDelay
+		return nil
+	}
+}
+
+// WithJitter sets the fraction of each delay that is randomized, between 0 and 1, defaults to
+// 0.5 so that delays are between half and all of the backoff
+func WithJitter(fraction float64) RetryOption {
+	return func(r *Retry) error {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("invalid jitter: %v", fraction)
+		}
+		r.jitter = fraction
+		return nil
+	}
+}
+
+// WithMaxRetryAfter sets the longest Retry-After delay honored, defaults to a minute. Responses
+// asking to wait longer are returned without retrying.
+func WithMaxRetryAfter(d time.Duration) RetryOption {
+	return func(r *Retry) error {
+		r.maxRetryAfter = d
+		return nil
+	}
+}
+
+// WithRetryOperations opts operations that are not GET requests in to retries, by operationId.
+// Only opt in operations the server handles idempotently: retrying RequestWithdrawal or
+// RedeemZar after a lost response could submit them twice.
+func WithRetryOperations(ids ...string) RetryOption {
+	return func(r *Retry) error {
+		for _, id := range ids {
+			r.operations[id] = true
+		}
+		return nil
+	}
+}
+
+// WithOperationResolver sets how the operationId of a request is found, defaults to
+// ResolveOperation
+func WithOperationResolver(resolve OperationResolver) RetryOption {
+	return func(r *Retry) error {
+		r.resolve = resolve
+		return nil
+	}
+}
+
+// Do sends the request, retrying it while it fails with a transient error
+func (r *Retry) Do(req *http.Request) (*http.Response, error) {
+	if !r.retryable(req) {
+		return r.next.Do(req)
+	}
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := r.rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := r.next.Do(attemptReq)
+		if attempt >= r.maxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := r.backoff(attempt)
+		switch {
+		case err != nil:
+			if !isTransient(err) {
+				return resp, err
+			}
+		case resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented):
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if retryAfter > r.maxRetryAfter {
+					return resp, nil
+				}
+				if retryAfter > delay {
+					delay = retryAfter
+				}
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether req may be sent more than once
+func (r *Retry) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	return len(r.operations) > 0 && r.resolve != nil && r.operations[r.resolve(req)]
+}
+
+// rewind returns the request to send for an attempt, with a fresh body after the first one
+func (r *Retry) rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// backoff returns the jittered delay before the retry following attempt
+func (r *Retry) backoff(attempt int) time.Duration {
+	delay := r.baseDelay
+	for i := 1; i < attempt && delay < r.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.maxDelay {
+		delay = r.maxDelay
+	}
+	r.mu.Lock()
+	f := r.rand.Float64()
+	r.mu.Unlock()
+	return delay - time.Duration(float64(delay)*r.jitter*f)
+}
+
+// isTransient reports whether a request error is worth retrying
+func isTransient(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header holding either seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reply is a response, or an error, returned by a scriptedDoer
+type reply struct {
+	status     int
+	retryAfter string
+	err        error
+}
+
+// scriptedDoer returns its replies in order, repeating the last one, and records the bodies of
+// the requests it was sent
+type scriptedDoer struct {
+	mu      sync.Mutex
+	replies []reply
+	bodies  []string
+}
+
+func (d *scriptedDoer) Do(req *http.Request) (*http.Response, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	d.bodies = append(d.bodies, body)
+	r := d.replies[0]
+	if len(d.replies) > 1 {
+		d.replies = d.replies[1:]
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	resp := &http.Response{StatusCode: r.status, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("")), Request: req}
+	if r.retryAfter != "" {
+		resp.Header.Set("Retry-After", r.retryAfter)
+	}
+	return resp, nil
+}
+
+func (d *scriptedDoer) calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.bodies)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		opts       []RetryOption
+		replies    []reply
+		wantStatus int
+		wantErr    error
+		wantCalls  int
+	}{
+		{
+			name:       "get retried after 503",
+			method:     http.MethodGet,
+			replies:    []reply{{status: 503}, {status: 200}},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		{
+			name:       "get retried after a connection reset",
+			method:     http.MethodGet,
+			replies:    []reply{{err: io.ErrUnexpectedEOF}, {status: 200}},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		{
+			name:      "other errors are returned",
+			method:    http.MethodGet,
+			replies:   []reply{{err: errors.New("certificate signed by unknown authority")}},
+			wantErr:   errors.New("certificate signed by unknown authority"),
+			wantCalls: 1,
+		},
+		{
+			name:       "501 is not retried",
+			method:     http.MethodGet,
+			replies:    []reply{{status: 501}},
+			wantStatus: 501,
+			wantCalls:  1,
+		},
+		{
+			name:       "attempts are limited",
+			method:     http.MethodGet,
+			replies:    []reply{{status: 503}},
+			wantStatus: 503,
+			wantCalls:  3,
+		},
+		{
+			name:       "long retry-after is returned",
+			method:     http.MethodGet,
+			replies:    []reply{{status: 429, retryAfter: "120"}},
+			wantStatus: 429,
+			wantCalls:  1,
+		},
+		{
+			name:       "post is not retried",
+			method:     http.MethodPost,
+			replies:    []reply{{status: 503}, {status: 200}},
+			wantStatus: 503,
+			wantCalls:  1,
+		},
+		{
+			name:   "opted in post is retried",
+			method: http.MethodPost,
+			opts: []RetryOption{
+				WithRetryOperations("SyncOrder"),
+				WithOperationResolver(func(*http.Request) string { return "SyncOrder" }),
+			},
+			replies:    []reply{{status: 502}, {status: 200}},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doer := &scriptedDoer{replies: tt.replies}
+			opts := append([]RetryOption{WithBackoff(time.Millisecond, time.Millisecond)}, tt.opts...)
+			r, err := NewRetry(doer, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(tt.method, "https://api.zarban.io/v2/orders", strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := r.Do(req)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if doer.calls() != tt.wantCalls {
+				t.Fatalf("expected %d calls, got %d", tt.wantCalls, doer.calls())
+			}
+			for i, body := range doer.bodies {
+				if body != "payload" {
+					t.Fatalf("attempt %d sent body %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "5", want: 5 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "Mon, 01 Jan 2024 00:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{value: "Sun, 31 Dec 2023 23:59:00 GMT", want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, expected %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Package transport provides HttpRequestDoer decorators for the wallet and service clients,
+// installed with WithHTTPClient:
+//
+//	doer, err := transport.NewRetry(http.DefaultClient)
+//	client, err := service.NewClient(server, service.WithHTTPClient(doer))
+package transport
+
+import (
+	"net/http"
+
+	"github.com/zarbanio/zarban-go/service"
+	"github.com/zarbanio/zarban-go/wallet"
+)
+
+// Doer sends HTTP requests. It is satisfied by *http.Client and by the HttpRequestDoer
+// interfaces of the wallet and service packages.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// OperationResolver returns the operationId of a request, or an empty string if unknown
+type OperationResolver func(req *http.Request) string
+
+// ResolveOperation resolves the operationId of requests to the service and wallet APIs. The
+// service operations are tried first, so requests to the wallet API whose path also matches a
+// service operation should be resolved with wallet.OperationID instead.
+func ResolveOperation(req *http.Request) string {
+	if id := service.OperationID(req); id != "" {
+		return id
+	}
+	return wallet.OperationID(req)
+}
+
+// defaultDoer returns next, or http.DefaultClient if next is nil
+func defaultDoer(next Doer) Doer {
+	if next == nil {
+		return http.DefaultClient
+	}
+	return next
+}
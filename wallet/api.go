@@ -0,0 +1,480 @@
+// Code generated by zarban-apigen from api_specs/wallet.openapi.yaml. DO NOT EDIT.
+
+package wallet
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Operation is an operation of the API, as declared in the OpenAPI spec
+type Operation struct {
+	ID     string
+	Method string
+	Path   string
+}
+
+// operations lists the operations of the API in the order of the spec
+var operations = []Operation{
+	{ID: "checkApiHealth", Method: "GET", Path: "/healthz"},
+	{ID: "listPrices", Method: "GET", Path: "/prices"},
+	{ID: "getUserProfile", Method: "GET", Path: "/profile"},
+	{ID: "verifyPhoneNumber", Method: "POST", Path: "/users/phone"},
+	{ID: "confirmPhoneNumber", Method: "POST", Path: "/users/phone/confirm"},
+	{ID: "verifyUserEmailAddress", Method: "POST", Path: "/users/email"},
+	{ID: "submitEmailConfirmationOtp", Method: "POST", Path: "/users/email/confirm"},
+	{ID: "submitKyc", Method: "POST", Path: "/users/kyc"},
+	{ID: "confirmKyc", Method: "POST", Path: "/users/kyc/confirm"},
+	{ID: "createChildUser", Method: "POST", Path: "/users/children"},
+	{ID: "createPayment", Method: "POST", Path: "/payments"},
+	{ID: "getReferrals", Method: "GET", Path: "/referrals"},
+	{ID: "validateReferral", Method: "POST", Path: "/referrals/{referralId}/validate"},
+	{ID: "redeemReferral", Method: "POST", Path: "/referrals/{referralId}/redeem"},
+	{ID: "getReferralById", Method: "GET", Path: "/referrals/{referralId}"},
+	{ID: "getSupportedCoins", Method: "GET", Path: "/coins"},
+	{ID: "getCoinDetails", Method: "GET", Path: "/coins/{symbol}"},
+	{ID: "swapCoins", Method: "POST", Path: "/swap"},
+	{ID: "requestWithdrawal", Method: "POST", Path: "/withdraws/request"},
+	{ID: "previewWithdrawal", Method: "POST", Path: "/withdraws/preview"},
+	{ID: "getUserWithdrawRequests", Method: "GET", Path: "/withdraws"},
+	{ID: "getWithdrawalStatus", Method: "GET", Path: "/withdraws/{id}"},
+	{ID: "getBalanceBySymbol", Method: "GET", Path: "/balance/{symbol}"},
+	{ID: "getWalletBalance", Method: "GET", Path: "/balance"},
+	{ID: "depositMoney", Method: "GET", Path: "/deposit"},
+	{ID: "authenticateWithTelegram", Method: "POST", Path: "/auth/telegram"},
+	{ID: "signupWithEmailAndPassword", Method: "POST", Path: "/auth/signup"},
+	{ID: "getOtp", Method: "GET", Path: "/auth/otp"},
+	{ID: "loginWithEmailAndPassword", Method: "POST", Path: "/auth/login"},
+	{ID: "generateJwtToken", Method: "GET", Path: "/auth/token"},
+	{ID: "verifyUserEmail", Method: "GET", Path: "/verify-email"},
+	{ID: "getTasks", Method: "GET", Path: "/tasks"},
+	{ID: "getFriendsPoints", Method: "GET", Path: "/points/frineds"},
+	{ID: "getUserLoans", Method: "GET", Path: "/loans"},
+	{ID: "getLoanDetails", Method: "GET", Path: "/loans/{id}"},
+	{ID: "estimateLoanCollateral", Method: "GET", Path: "/loans/estimate"},
+	{ID: "createLoanVault", Method: "POST", Path: "/loans/create"},
+	{ID: "repayLoan", Method: "POST", Path: "/loans/repay"},
+	{ID: "getAllLoanPlans", Method: "GET", Path: "/loans/plans"},
+	{ID: "getUserTransactions", Method: "GET", Path: "/transactions"},
+	{ID: "redeemZar", Method: "POST", Path: "/redemptions"},
+	{ID: "getAllRedemptions", Method: "GET", Path: "/admin/redemptions"},
+	{ID: "getRedemptionDetails", Method: "GET", Path: "/admin/redemptions/{id}"},
+	{ID: "updateRedemptionStatus", Method: "POST", Path: "/admin/redemptions/{id}"},
+}
+
+// Operations returns the operations of the API
+func Operations() []Operation {
+	return append([]Operation(nil), operations...)
+}
+
+// OperationID returns the operationId of a request to the API, or an empty string if the
+// request does not match any operation. Paths are matched against the end of the request
+// path, so servers with a path prefix are supported, and literal segments take precedence over
+// path parameters.
+func OperationID(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	id, best := "", -1
+	for _, op := range operations {
+		if op.Method != req.Method {
+			continue
+		}
+		if literals, ok := matchPath(op.Path, segments); ok && literals > best {
+			id, best = op.ID, literals
+		}
+	}
+	return id
+}
+
+// matchPath matches a path template against the trailing request path segments, and returns
+// the number of literal segments matched
+func matchPath(template string, segments []string) (int, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) > len(segments) {
+		return 0, false
+	}
+	segments = segments[len(segments)-len(parts):]
+	literals := 0
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// API calls the operations of the API and returns their decoded responses. Failed requests
+// and error responses are returned as *APIError.
+type API struct {
+	client ClientInterface
+}
+
+// NewAPI creates an API calling the operations with client
+func NewAPI(client ClientInterface) *API {
+	return &API{client: client}
+}
+
+// NewAPIWithServer creates an API with a new Client for server
+func NewAPIWithServer(server string, opts ...ClientOption) (*API, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPI(client), nil
+}
+
+// Client returns the client used by the API
+func (a *API) Client() ClientInterface {
+	return a.client
+}
+
+// CheckApiHealth calls GET /healthz: Health check
+func (a *API) CheckApiHealth(ctx context.Context, reqEditors ...RequestEditorFn) (HealthStatus, error) {
+	var response HealthStatus
+	httpResponse, err := a.client.CheckApiHealth(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ListPrices calls GET /prices: List prices based on query parameters
+func (a *API) ListPrices(ctx context.Context, params *ListPricesParams, reqEditors ...RequestEditorFn) (PriceListResponse, error) {
+	var response PriceListResponse
+	httpResponse, err := a.client.ListPrices(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserProfile calls GET /profile: Get profile
+func (a *API) GetUserProfile(ctx context.Context, reqEditors ...RequestEditorFn) (ProfileResponse, error) {
+	var response ProfileResponse
+	httpResponse, err := a.client.GetUserProfile(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// VerifyPhoneNumber calls POST /users/phone: Verify phone number
+func (a *API) VerifyPhoneNumber(ctx context.Context, body VerifyPhoneNumberJSONRequestBody, reqEditors ...RequestEditorFn) error {
+	httpResponse, err := a.client.VerifyPhoneNumber(ctx, body, reqEditors...)
+	return checkResponse(ctx, httpResponse, err)
+}
+
+// ConfirmPhoneNumber calls POST /users/phone/confirm: Confirm phone number
+func (a *API) ConfirmPhoneNumber(ctx context.Context, body ConfirmPhoneNumberJSONRequestBody, reqEditors ...RequestEditorFn) error {
+	httpResponse, err := a.client.ConfirmPhoneNumber(ctx, body, reqEditors...)
+	return checkResponse(ctx, httpResponse, err)
+}
+
+// VerifyUserEmailAddress calls POST /users/email: Verify email
+func (a *API) VerifyUserEmailAddress(ctx context.Context, body VerifyUserEmailAddressJSONRequestBody, reqEditors ...RequestEditorFn) (SimpleResponse, error) {
+	var response SimpleResponse
+	httpResponse, err := a.client.VerifyUserEmailAddress(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SubmitEmailConfirmationOtp calls POST /users/email/confirm: Submit email confirmation OTP
+func (a *API) SubmitEmailConfirmationOtp(ctx context.Context, body SubmitEmailConfirmationOtpJSONRequestBody, reqEditors ...RequestEditorFn) (SimpleResponse, error) {
+	var response SimpleResponse
+	httpResponse, err := a.client.SubmitEmailConfirmationOtp(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SubmitKyc calls POST /users/kyc: Submit KYC
+func (a *API) SubmitKyc(ctx context.Context, body SubmitKycJSONRequestBody, reqEditors ...RequestEditorFn) (KycResponse, error) {
+	var response KycResponse
+	httpResponse, err := a.client.SubmitKyc(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ConfirmKyc calls POST /users/kyc/confirm: Confirm KYC
+func (a *API) ConfirmKyc(ctx context.Context, body ConfirmKycJSONRequestBody, reqEditors ...RequestEditorFn) (SimpleResponse, error) {
+	var response SimpleResponse
+	httpResponse, err := a.client.ConfirmKyc(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateChildUser calls POST /users/children: create a child user
+func (a *API) CreateChildUser(ctx context.Context, body CreateChildUserJSONRequestBody, reqEditors ...RequestEditorFn) (User, error) {
+	var response User
+	httpResponse, err := a.client.CreateChildUser(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreatePayment calls POST /payments: Create a payment
+func (a *API) CreatePayment(ctx context.Context, body CreatePaymentJSONRequestBody, reqEditors ...RequestEditorFn) (Payment, error) {
+	var response Payment
+	httpResponse, err := a.client.CreatePayment(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetReferrals calls GET /referrals: Get referrals
+func (a *API) GetReferrals(ctx context.Context, params *GetReferralsParams, reqEditors ...RequestEditorFn) (ReferralResponse, error) {
+	var response ReferralResponse
+	httpResponse, err := a.client.GetReferrals(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// ValidateReferral calls POST /referrals/{referralId}/validate: Validate a referral
+func (a *API) ValidateReferral(ctx context.Context, referralId ReferralIdParameter, reqEditors ...RequestEditorFn) error {
+	httpResponse, err := a.client.ValidateReferral(ctx, referralId, reqEditors...)
+	return checkResponse(ctx, httpResponse, err)
+}
+
+// RedeemReferral calls POST /referrals/{referralId}/redeem: Redeem a referral
+func (a *API) RedeemReferral(ctx context.Context, referralId ReferralIdParameter, reqEditors ...RequestEditorFn) error {
+	httpResponse, err := a.client.RedeemReferral(ctx, referralId, reqEditors...)
+	return checkResponse(ctx, httpResponse, err)
+}
+
+// GetReferralById calls GET /referrals/{referralId}: Get referral by ID
+func (a *API) GetReferralById(ctx context.Context, referralId ReferralIdParameter, reqEditors ...RequestEditorFn) (Referral, error) {
+	var response Referral
+	httpResponse, err := a.client.GetReferralById(ctx, referralId, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetSupportedCoins calls GET /coins: Get coins
+func (a *API) GetSupportedCoins(ctx context.Context, reqEditors ...RequestEditorFn) (CoinResponse, error) {
+	var response CoinResponse
+	httpResponse, err := a.client.GetSupportedCoins(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetCoinDetails calls GET /coins/{symbol}: Get coin
+func (a *API) GetCoinDetails(ctx context.Context, symbol Symbol, reqEditors ...RequestEditorFn) (Coin, error) {
+	var response Coin
+	httpResponse, err := a.client.GetCoinDetails(ctx, symbol, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SwapCoins calls POST /swap: Swap coins
+func (a *API) SwapCoins(ctx context.Context, body SwapCoinsJSONRequestBody, reqEditors ...RequestEditorFn) (SwapResponse, error) {
+	var response SwapResponse
+	httpResponse, err := a.client.SwapCoins(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// RequestWithdrawal calls POST /withdraws/request: Withdraw request
+func (a *API) RequestWithdrawal(ctx context.Context, body RequestWithdrawalJSONRequestBody, reqEditors ...RequestEditorFn) (WithdrawResponseBody, error) {
+	var response WithdrawResponseBody
+	httpResponse, err := a.client.RequestWithdrawal(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// PreviewWithdrawal calls POST /withdraws/preview: Withdraw request
+func (a *API) PreviewWithdrawal(ctx context.Context, body PreviewWithdrawalJSONRequestBody, reqEditors ...RequestEditorFn) (WithdrawRequestPreview, error) {
+	var response WithdrawRequestPreview
+	httpResponse, err := a.client.PreviewWithdrawal(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserWithdrawRequests calls GET /withdraws: Get user withdraw requests
+func (a *API) GetUserWithdrawRequests(ctx context.Context, reqEditors ...RequestEditorFn) (WithdrawRequestResponse, error) {
+	var response WithdrawRequestResponse
+	httpResponse, err := a.client.GetUserWithdrawRequests(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetWithdrawalStatus calls GET /withdraws/{id}: Get withdrawal status
+func (a *API) GetWithdrawalStatus(ctx context.Context, id WithdrawalIdRequest, reqEditors ...RequestEditorFn) (WithdrawRequest, error) {
+	var response WithdrawRequest
+	httpResponse, err := a.client.GetWithdrawalStatus(ctx, id, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetBalanceBySymbol calls GET /balance/{symbol}: Get balance
+func (a *API) GetBalanceBySymbol(ctx context.Context, symbol Symbol, reqEditors ...RequestEditorFn) (Balance, error) {
+	var response Balance
+	httpResponse, err := a.client.GetBalanceBySymbol(ctx, symbol, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetWalletBalance calls GET /balance: Get wallet balance
+func (a *API) GetWalletBalance(ctx context.Context, reqEditors ...RequestEditorFn) (WalletBalance, error) {
+	var response WalletBalance
+	httpResponse, err := a.client.GetWalletBalance(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// DepositMoney calls GET /deposit: Deposit money
+func (a *API) DepositMoney(ctx context.Context, params *DepositMoneyParams, reqEditors ...RequestEditorFn) (DepositResponse, error) {
+	var response DepositResponse
+	httpResponse, err := a.client.DepositMoney(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// AuthenticateWithTelegram calls POST /auth/telegram: Authenticate with Telegram
+func (a *API) AuthenticateWithTelegram(ctx context.Context, body AuthenticateWithTelegramJSONRequestBody, reqEditors ...RequestEditorFn) (JwtResponse, error) {
+	var response JwtResponse
+	httpResponse, err := a.client.AuthenticateWithTelegram(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// SignupWithEmailAndPassword calls POST /auth/signup: signup with email and password
+func (a *API) SignupWithEmailAndPassword(ctx context.Context, body SignupWithEmailAndPasswordJSONRequestBody, reqEditors ...RequestEditorFn) (SimpleResponse, error) {
+	var response SimpleResponse
+	httpResponse, err := a.client.SignupWithEmailAndPassword(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetOtp calls GET /auth/otp: Get OTP
+func (a *API) GetOtp(ctx context.Context, params *GetOtpParams, reqEditors ...RequestEditorFn) (SimpleResponse, error) {
+	var response SimpleResponse
+	httpResponse, err := a.client.GetOtp(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// LoginWithEmailAndPassword calls POST /auth/login: Login with email and password
+func (a *API) LoginWithEmailAndPassword(ctx context.Context, body LoginWithEmailAndPasswordJSONRequestBody, reqEditors ...RequestEditorFn) (JwtResponse, error) {
+	var response JwtResponse
+	httpResponse, err := a.client.LoginWithEmailAndPassword(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GenerateJwtToken calls GET /auth/token: Generate a JWT token
+func (a *API) GenerateJwtToken(ctx context.Context, params *GenerateJwtTokenParams, reqEditors ...RequestEditorFn) (JwtResponse, error) {
+	var response JwtResponse
+	httpResponse, err := a.client.GenerateJwtToken(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// VerifyUserEmail calls GET /verify-email: Verify email
+func (a *API) VerifyUserEmail(ctx context.Context, params *VerifyUserEmailParams, reqEditors ...RequestEditorFn) (JwtResponse, error) {
+	var response JwtResponse
+	httpResponse, err := a.client.VerifyUserEmail(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetTasks calls GET /tasks: Get tasks
+func (a *API) GetTasks(ctx context.Context, reqEditors ...RequestEditorFn) (TaskResponse, error) {
+	var response TaskResponse
+	httpResponse, err := a.client.GetTasks(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetFriendsPoints calls GET /points/frineds: Get friends points.
+func (a *API) GetFriendsPoints(ctx context.Context, reqEditors ...RequestEditorFn) (FriendPointsResponse, error) {
+	var response FriendPointsResponse
+	httpResponse, err := a.client.GetFriendsPoints(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserLoans calls GET /loans: Get user loans
+func (a *API) GetUserLoans(ctx context.Context, params *GetUserLoansParams, reqEditors ...RequestEditorFn) (LoansResponseList, error) {
+	var response LoansResponseList
+	httpResponse, err := a.client.GetUserLoans(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetLoanDetails calls GET /loans/{id}: Get loan
+func (a *API) GetLoanDetails(ctx context.Context, id string, reqEditors ...RequestEditorFn) (LoansResponse, error) {
+	var response LoansResponse
+	httpResponse, err := a.client.GetLoanDetails(ctx, id, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// EstimateLoanCollateral calls GET /loans/estimate: Get collateral and loan amount estimation
+func (a *API) EstimateLoanCollateral(ctx context.Context, params *EstimateLoanCollateralParams, reqEditors ...RequestEditorFn) (Currency, error) {
+	var response Currency
+	httpResponse, err := a.client.EstimateLoanCollateral(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// CreateLoanVault calls POST /loans/create: Create vault
+func (a *API) CreateLoanVault(ctx context.Context, body CreateLoanVaultJSONRequestBody, reqEditors ...RequestEditorFn) (LoansResponse, error) {
+	var response LoansResponse
+	httpResponse, err := a.client.CreateLoanVault(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// RepayLoan calls POST /loans/repay: Repay Loan
+func (a *API) RepayLoan(ctx context.Context, body RepayLoanJSONRequestBody, reqEditors ...RequestEditorFn) (LoansResponse, error) {
+	var response LoansResponse
+	httpResponse, err := a.client.RepayLoan(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetAllLoanPlans calls GET /loans/plans: Get all plan loans
+func (a *API) GetAllLoanPlans(ctx context.Context, reqEditors ...RequestEditorFn) (LoanPlanResponse, error) {
+	var response LoanPlanResponse
+	httpResponse, err := a.client.GetAllLoanPlans(ctx, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetUserTransactions calls GET /transactions: Get user transactions
+func (a *API) GetUserTransactions(ctx context.Context, params *GetUserTransactionsParams, reqEditors ...RequestEditorFn) (TransactionResponse, error) {
+	var response TransactionResponse
+	httpResponse, err := a.client.GetUserTransactions(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// RedeemZar calls POST /redemptions: Redeem zar
+func (a *API) RedeemZar(ctx context.Context, body RedeemZarJSONRequestBody, reqEditors ...RequestEditorFn) (Redemption, error) {
+	var response Redemption
+	httpResponse, err := a.client.RedeemZar(ctx, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetAllRedemptions calls GET /admin/redemptions: Get all redemptions
+func (a *API) GetAllRedemptions(ctx context.Context, params *GetAllRedemptionsParams, reqEditors ...RequestEditorFn) (RedemptionResponse, error) {
+	var response RedemptionResponse
+	httpResponse, err := a.client.GetAllRedemptions(ctx, params, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// GetRedemptionDetails calls GET /admin/redemptions/{id}: Get redemption
+func (a *API) GetRedemptionDetails(ctx context.Context, id int64, reqEditors ...RequestEditorFn) (Redemption, error) {
+	var response Redemption
+	httpResponse, err := a.client.GetRedemptionDetails(ctx, id, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
+
+// UpdateRedemptionStatus calls POST /admin/redemptions/{id}: Update redemption
+func (a *API) UpdateRedemptionStatus(ctx context.Context, id string, body UpdateRedemptionStatusJSONRequestBody, reqEditors ...RequestEditorFn) (Redemption, error) {
+	var response Redemption
+	httpResponse, err := a.client.UpdateRedemptionStatus(ctx, id, body, reqEditors...)
+	err = decodeResponse(ctx, httpResponse, err, &response)
+	return response, err
+}
@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{method: "GET", url: "https://testwapi.zarban.io/healthz", want: "checkApiHealth"},
+		{method: "GET", url: "https://testwapi.zarban.io/loans/42", want: "getLoanDetails"},
+		{method: "GET", url: "https://testwapi.zarban.io/loans/42?lang=fa", want: "getLoanDetails"},
+		{method: "GET", url: "https://testwapi.zarban.io/loans/estimate", want: "estimateLoanCollateral"},
+		{method: "GET", url: "https://testwapi.zarban.io/loans/plans/", want: "getAllLoanPlans"},
+		{method: "POST", url: "https://testwapi.zarban.io/referrals/abc/validate", want: "validateReferral"},
+		{method: "GET", url: "https://testwapi.zarban.io/referrals/abc", want: "getReferralById"},
+		{method: "GET", url: "https://testwapi.zarban.io/api/v1/coins/USDT", want: "getCoinDetails"},
+		{method: "GET", url: "https://testwapi.zarban.io/api/v1/coins", want: "getSupportedCoins"},
+		{method: "POST", url: "https://testwapi.zarban.io/api/v1/auth/login", want: "loginWithEmailAndPassword"},
+		{method: "GET", url: "https://testwapi.zarban.io/referrals//redeem"},
+		{method: "GET", url: "https://testwapi.zarban.io/referrals/abc/validate"},
+		{method: "GET", url: "https://testwapi.zarban.io/unknown"},
+		{method: "GET", url: "https://testwapi.zarban.io/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			if got := OperationID(httptest.NewRequest(tt.method, tt.url, nil)); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	ops := Operations()
+	ops[0].ID = "changed"
+	if Operations()[0].ID == "changed" {
+		t.Fatal("expected Operations to return a copy")
+	}
+}
@@ -3,6 +3,7 @@ package wallet
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,6 +19,9 @@ type APIError struct {
 	Path         string
 	Method       string
 	ErrorContext map[string]interface{}
+
+	// Err is the error of a request that failed without a response
+	Err error
 }
 
 // Error implements the error interface
@@ -26,6 +30,11 @@ func (e *APIError) Error() string {
 		e.RequestID, e.StatusCode, e.Path, e.Message, e.Details)
 }
 
+// Unwrap returns the error of a request that failed without a response
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
 // WithContext adds additional context to the APIError
 func (e *APIError) WithContext(key string, value interface{}) *APIError {
 	if e.ErrorContext == nil {
@@ -134,6 +143,44 @@ func HandleAPIResponse[T any](ctx context.Context, resp *http.Response, successR
 	return apiError
 }
 
+// decodeResponse decodes the response of a request into successResponse, returning the error
+// of a request that failed without a response as an *APIError
+func decodeResponse[T any](ctx context.Context, resp *http.Response, err error, successResponse *T) error {
+	if err != nil {
+		return requestError(resp, err)
+	}
+	return HandleAPIResponse(ctx, resp, successResponse)
+}
+
+// checkResponse checks the status of a response without a body to decode
+func checkResponse(ctx context.Context, resp *http.Response, err error) error {
+	if err != nil {
+		return requestError(resp, err)
+	}
+	if resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.Body.Close()
+	}
+	var discard json.RawMessage
+	return HandleAPIResponse(ctx, resp, &discard)
+}
+
+// requestError wraps the error of a request that failed without a response
+func requestError(resp *http.Response, err error) error {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &APIError{
+		Message: "request failed",
+		Details: err.Error(),
+		Err:     err,
+	}
+}
+
 // PrettyPrintError formats the APIError with improved readability
 func PrettyPrintError(err *APIError) string {
 	var sb strings.Builder
@@ -0,0 +1,148 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// body is a response body recording whether it was closed
+type body struct {
+	io.Reader
+	closed bool
+}
+
+func (b *body) Close() error {
+	b.closed = true
+	return nil
+}
+
+// response returns a response to GET /profile with a request id
+func response(status int, text string) (*http.Response, *body) {
+	b := &body{Reader: strings.NewReader(text)}
+	header := http.Header{}
+	header.Set("X-Request-ID", "req-1")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       b,
+		Request:    httptest.NewRequest(http.MethodGet, "https://testwapi.zarban.io/profile", nil),
+	}, b
+}
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		want        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "success", status: http.StatusOK, body: `{"status":"ok"}`, want: "ok"},
+		{name: "invalid success body", status: http.StatusOK, body: `{"status":`, wantStatus: http.StatusOK, wantMessage: "failed to parse success response"},
+		{name: "user error", status: http.StatusBadRequest, body: `{"messages":{"en":{"userMessage":"Insufficient balance"}}}`, wantStatus: http.StatusBadRequest, wantMessage: "User error"},
+		{name: "generic error", status: http.StatusNotFound, body: `{"msg":"not found","reasons":["no profile"]}`, wantStatus: http.StatusNotFound, wantMessage: "not found"},
+		{name: "unhandled error", status: http.StatusBadGateway, body: "bad gateway", wantStatus: http.StatusBadGateway, wantMessage: "Unhandled error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, b := response(tt.status, tt.body)
+			var got struct {
+				Status string `json:"status"`
+			}
+			err := decodeResponse(context.Background(), resp, nil, &got)
+			if !b.closed {
+				t.Fatal("expected the body to be closed")
+			}
+			if tt.wantMessage == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Status != tt.want {
+					t.Fatalf("expected %s, got %s", tt.want, got.Status)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus || apiErr.Message != tt.wantMessage {
+				t.Fatalf("expected %d %q, got %d %q", tt.wantStatus, tt.wantMessage, apiErr.StatusCode, apiErr.Message)
+			}
+			if apiErr.RequestID != "req-1" || apiErr.Method != http.MethodGet || apiErr.Path != "/profile" {
+				t.Fatalf("expected the request of the response, got %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	resp, b := response(http.StatusNoContent, "ignored")
+	if err := checkResponse(context.Background(), resp, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !b.closed {
+		t.Fatal("expected the body to be closed")
+	}
+
+	resp, b = response(http.StatusUnauthorized, `{"msg":"unauthorized"}`)
+	var apiErr *APIError
+	if err := checkResponse(context.Background(), resp, nil); !errors.As(err, &apiErr) || !apiErr.IsUnauthorized() || apiErr.Message != "unauthorized" {
+		t.Fatalf("expected an unauthorized *APIError, got %v", err)
+	}
+	if !b.closed {
+		t.Fatal("expected the body to be closed")
+	}
+
+	if err := checkResponse(context.Background(), nil, context.DeadlineExceeded); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	failure := errors.New("connection refused")
+	tests := []struct {
+		name string
+		resp bool
+		err  error
+		want *APIError
+	}{
+		{name: "failed request", err: failure, want: &APIError{Message: "request failed", Details: failure.Error(), Err: failure}},
+		{name: "failed request with a response", resp: true, err: failure, want: &APIError{Message: "request failed", Details: failure.Error(), Err: failure}},
+		{name: "api error", err: &APIError{StatusCode: http.StatusTooManyRequests, Message: "rate limited"}, want: &APIError{StatusCode: http.StatusTooManyRequests, Message: "rate limited"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			var b *body
+			if tt.resp {
+				resp, b = response(http.StatusOK, "{}")
+			}
+			err := requestError(resp, tt.err)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.want.StatusCode || apiErr.Message != tt.want.Message || apiErr.Details != tt.want.Details || apiErr.Err != tt.want.Err {
+				t.Fatalf("expected %+v, got %+v", tt.want, apiErr)
+			}
+			if tt.want.Err != nil && !errors.Is(err, tt.want.Err) {
+				t.Fatalf("expected the error to wrap %v", tt.want.Err)
+			}
+			if b != nil && !b.closed {
+				t.Fatal("expected the body to be closed")
+			}
+		})
+	}
+
+	apiErr := &APIError{Message: "rate limited"}
+	if err := requestError(nil, apiErr); err != apiErr {
+		t.Fatalf("expected the *APIError to be returned as is, got %v", err)
+	}
+}